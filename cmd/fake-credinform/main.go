@@ -0,0 +1,103 @@
+// Команда fake-credinform запускает поддельный Credinform API для локальной
+// разработки без учётных данных и доступа к сети.
+//
+//	fake-credinform -fixtures internal/credinform/fakecredinform/testdata -fault 503@Search/SearchCompany*2
+//
+// Сбои можно добавлять и во время работы: POST /_fake/faults с описанием сбоя
+// в теле, DELETE /_fake/faults — удалить все, POST /_fake/expire-keys — отозвать ключи.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"scoring_worker/internal/credinform/fakecredinform"
+	"scoring_worker/internal/logger"
+
+	"go.uber.org/zap"
+)
+
+// faultFlags собирает повторяющийся флаг -fault
+type faultFlags []fakecredinform.Fault
+
+func (f *faultFlags) String() string {
+	return fmt.Sprint(len(*f), " faults")
+}
+
+func (f *faultFlags) Set(spec string) error {
+	fault, err := fakecredinform.ParseFault(spec)
+	if err != nil {
+		return err
+	}
+	*f = append(*f, fault)
+	return nil
+}
+
+func main() {
+	addr := flag.String("addr", ":8081", "listen address")
+	fixtures := flag.String("fixtures", "", "fixtures directory (search/<inn>.json, companies/<companyId>/<Method>.json)")
+	username := flag.String("username", "", "accepted username; any credentials are accepted if empty")
+	password := flag.String("password", "", "accepted password")
+	keyLifetime := flag.Duration("key-lifetime", 0, "access key lifetime, 0 means keys never expire")
+	var faults faultFlags
+	flag.Var(&faults, "fault", "fault to inject: <status|delay:<duration>|malformed>[@<method>][*<times>], may be repeated")
+	flag.Parse()
+
+	log, err := logger.New("info", false)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to setup logger: %v\n", err)
+		os.Exit(1)
+	}
+	defer log.Sync()
+
+	if *fixtures == "" {
+		log.Fatal("fixtures directory is required")
+	}
+	if info, err := os.Stat(*fixtures); err != nil || !info.IsDir() {
+		log.Fatal("fixtures directory is not accessible", zap.String("fixtures", *fixtures), zap.Error(err))
+	}
+
+	opts := []fakecredinform.Option{fakecredinform.WithKeyLifetime(*keyLifetime)}
+	if *username != "" {
+		opts = append(opts, fakecredinform.WithCredentials(*username, *password))
+	}
+	server := fakecredinform.New(os.DirFS(*fixtures), opts...)
+	for _, fault := range faults {
+		server.Inject(fault)
+	}
+
+	log.Info("Fake Credinform API listening",
+		zap.String("addr", *addr),
+		zap.String("fixtures", *fixtures),
+		zap.Int("faults", len(faults)))
+	if err := http.ListenAndServe(*addr, logRequests(server, log)); err != nil {
+		log.Fatal("fake credinform server failed", zap.Error(err))
+	}
+}
+
+// logRequests пишет в лог каждый запрос к серверу
+func logRequests(next http.Handler, log *zap.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		log.Info("Request served",
+			zap.String("method", strings.TrimPrefix(r.URL.Path, "/api/")),
+			zap.Int("status", rec.status),
+			zap.Duration("duration", time.Since(start)))
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"scoring_worker/internal/migrations"
+	"scoring_worker/internal/repository"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const usage = `usage:
+  scoring_worker [--record file | --replay file]  запуск воркера; --record записывает обмен с Credinform
+                                                 в кассету, --replay отвечает из кассеты без обращения к API
+  scoring_worker migrate up|down|status          управление схемой базы данных
+  scoring_worker cache stats                     статистика кэша данных
+  scoring_worker cache gc [--older-than 24h]     удаление неиспользуемых записей кэша`
+
+// runCommand выполняет служебную подкоманду, переданную в аргументах запуска
+func runCommand(args []string) error {
+	switch args[0] {
+	case "migrate":
+		return runMigrate(args[1:])
+	case "cache":
+		return runCache(args[1:])
+	case "help", "-h", "--help":
+		fmt.Println(usage)
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q\n%s", args[0], usage)
+	}
+}
+
+func runMigrate(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("migrate expects exactly one of up, down, status\n%s", usage)
+	}
+
+	return withDatabase(func(db *pgxpool.Pool, log *zap.Logger) error {
+		return migrate(db, log, args[0])
+	})
+}
+
+func migrate(db *pgxpool.Pool, log *zap.Logger, command string) error {
+	migrator, err := migrations.NewMigrator(db, log)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("applied %d migration(s)\n", applied)
+	case "down":
+		rolledBack, err := migrator.Down(ctx)
+		if err != nil {
+			return err
+		}
+		if !rolledBack {
+			fmt.Println("no migrations to roll back")
+			return nil
+		}
+		fmt.Println("rolled back 1 migration")
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		return w.Flush()
+	default:
+		return fmt.Errorf("unknown migrate command %q\n%s", command, usage)
+	}
+	return nil
+}
+
+func runCache(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("cache expects one of stats, gc\n%s", usage)
+	}
+
+	switch args[0] {
+	case "stats":
+		return withDatabase(func(db *pgxpool.Pool, log *zap.Logger) error {
+			stats, err := repository.NewDataCacheRepository(db, log).Stats(context.Background())
+			if err != nil {
+				return err
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			fmt.Fprintf(w, "total entries\t%d\n", stats.TotalEntries)
+			fmt.Fprintf(w, "storage size\t%d bytes\n", stats.StorageSize)
+			fmt.Fprintf(w, "hit rate\t%.2f%%\n", stats.CacheHitRate*100)
+			fmt.Fprintf(w, "deduplication rate\t%.2f%%\n", stats.DeduplicationRate*100)
+			return w.Flush()
+		})
+	case "gc":
+		flags := flag.NewFlagSet("cache gc", flag.ContinueOnError)
+		olderThan := flags.Duration("older-than", 24*time.Hour, "удалять записи, не использовавшиеся дольше указанного времени")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		return withDatabase(func(db *pgxpool.Pool, log *zap.Logger) error {
+			deleted, err := repository.NewDataCacheRepository(db, log).GC(context.Background(), *olderThan)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("deleted %d cache entries\n", deleted)
+			return nil
+		})
+	default:
+		return fmt.Errorf("unknown cache command %q\n%s", args[0], usage)
+	}
+}
+
+// withDatabase загружает конфигурацию и подключается к базе данных для служебной подкоманды
+func withDatabase(fn func(db *pgxpool.Pool, log *zap.Logger) error) error {
+	cfg, err := setupConfig()
+	if err != nil {
+		return fmt.Errorf("failed to setup config: %w", err)
+	}
+	log, err := setupLogger(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to setup logger: %w", err)
+	}
+	defer log.Sync()
+
+	db, err := setupDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return fn(db, log)
+}
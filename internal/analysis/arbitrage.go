@@ -0,0 +1,37 @@
+package analysis
+
+import "scoring_worker/internal/credinform/types"
+
+// ArbitrageCasesReport — список арбитражных дел со сводкой, сохраняемый как arbitrage_cases
+type ArbitrageCasesReport = Report[types.ArbitrageCases, ArbitrageCasesSummary]
+
+// ArbitrageCasesSummary — открытые дела, в которых компания выступает ответчиком.
+// Если список получен не полностью, сводка учитывает только полученные дела.
+type ArbitrageCasesSummary struct {
+	OpenAsDefendant         int     `json:"open_as_defendant"`
+	OpenAsDefendantClaimSum float64 `json:"open_as_defendant_claim_sum"`
+	// LastEventDate — дата последнего события по открытым делам ответчика
+	LastEventDate *string `json:"last_event_date,omitempty"`
+}
+
+// SummarizeArbitrageCases дополняет список дел сводкой
+func SummarizeArbitrageCases(cases *types.ArbitrageCases) *ArbitrageCasesReport {
+	summary := &ArbitrageCasesSummary{}
+	if cases == nil {
+		return &ArbitrageCasesReport{Summary: summary}
+	}
+
+	for _, c := range cases.CaseList {
+		if c == nil || !c.IsOpen() || c.Side == nil || *c.Side != types.ArbitrageSideDefendant {
+			continue
+		}
+		summary.OpenAsDefendant++
+		if c.ClaimAmount != nil {
+			summary.OpenAsDefendantClaimSum += *c.ClaimAmount
+		}
+		if c.LastEvent != nil && isLater(c.LastEvent.Date, summary.LastEventDate) {
+			summary.LastEventDate = c.LastEvent.Date
+		}
+	}
+	return &ArbitrageCasesReport{Data: cases, Summary: summary}
+}
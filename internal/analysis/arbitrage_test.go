@@ -0,0 +1,107 @@
+package analysis
+
+import (
+	"encoding/json"
+	"testing"
+
+	"scoring_worker/internal/credinform/types"
+)
+
+func s(v string) *string { return &v }
+
+func TestSummarizeArbitrageCases(t *testing.T) {
+	cases := &types.ArbitrageCases{CaseList: []*types.ArbitrageCase{
+		{
+			CaseNumber:  s("А40-1/2024"),
+			Status:      s(types.ArbitrageCaseStatusOpen),
+			Side:        s(types.ArbitrageSideDefendant),
+			ClaimAmount: f(1000),
+			LastEvent:   &types.ArbitrageCaseEvent{Date: s("2024-05-01")},
+		},
+		{
+			// Дело без статуса считается открытым
+			CaseNumber:  s("А40-2/2024"),
+			Side:        s(types.ArbitrageSideDefendant),
+			ClaimAmount: f(500),
+			LastEvent:   &types.ArbitrageCaseEvent{Date: s("2024-09-15")},
+		},
+		{
+			CaseNumber:  s("А40-3/2023"),
+			Status:      s(types.ArbitrageCaseStatusClosed),
+			Side:        s(types.ArbitrageSideDefendant),
+			ClaimAmount: f(7000),
+			LastEvent:   &types.ArbitrageCaseEvent{Date: s("2025-01-10")},
+		},
+		{
+			CaseNumber:  s("А40-4/2024"),
+			Status:      s(types.ArbitrageCaseStatusOpen),
+			Side:        s(types.ArbitrageSideClaimant),
+			ClaimAmount: f(300),
+		},
+		nil,
+	}}
+
+	report := SummarizeArbitrageCases(cases)
+	if report.Summary.OpenAsDefendant != 2 {
+		t.Errorf("expected 2 open cases as defendant, got %d", report.Summary.OpenAsDefendant)
+	}
+	if report.Summary.OpenAsDefendantClaimSum != 1500 {
+		t.Errorf("expected claim sum 1500, got %v", report.Summary.OpenAsDefendantClaimSum)
+	}
+	if report.Summary.LastEventDate == nil || *report.Summary.LastEventDate != "2024-09-15" {
+		t.Errorf("expected last event 2024-09-15, got %v", report.Summary.LastEventDate)
+	}
+
+	// Список и сводка сохраняются одним документом
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var stored map[string]json.RawMessage
+	if err := json.Unmarshal(data, &stored); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, field := range []string{"arbitrageCaseList", "summary"} {
+		if _, ok := stored[field]; !ok {
+			t.Errorf("expected %s in stored document, got %s", field, data)
+		}
+	}
+}
+
+func TestSummarizeArbitrageCasesLastEventDate(t *testing.T) {
+	defendant := func(date *string) *types.ArbitrageCase {
+		return &types.ArbitrageCase{Side: s(types.ArbitrageSideDefendant), LastEvent: &types.ArbitrageCaseEvent{Date: date}}
+	}
+
+	tests := []struct {
+		name     string
+		cases    []*types.ArbitrageCase
+		expected *string
+	}{
+		{
+			// Даты сравниваются без учёта времени
+			name:     "with_time",
+			cases:    []*types.ArbitrageCase{defendant(s("2024-09-15T10:30:00")), defendant(s("2024-05-01"))},
+			expected: s("2024-09-15T10:30:00"),
+		},
+		{
+			// Некорректная дата не учитывается
+			name:     "malformed_date",
+			cases:    []*types.ArbitrageCase{defendant(s("2024-05-01")), defendant(s("15.09.2024"))},
+			expected: s("2024-05-01"),
+		},
+		{
+			name:  "no_dates",
+			cases: []*types.ArbitrageCase{defendant(nil), {Side: s(types.ArbitrageSideDefendant)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := SummarizeArbitrageCases(&types.ArbitrageCases{CaseList: tt.cases}).Summary
+			if !equalPtr(summary.LastEventDate, tt.expected) {
+				t.Errorf("expected last event %v, got %v", deref(tt.expected), deref(summary.LastEventDate))
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package analysis
+
+import (
+	"sort"
+
+	"scoring_worker/internal/credinform/types"
+)
+
+// BankruptcyReport — сообщения о банкротстве со сводкой, сохраняемые как bankruptcy_messages
+type BankruptcyReport = Report[types.BankruptcyMessages, BankruptcySummary]
+
+// BankruptcySummary — состояние процедуры банкротства по последним сообщениям
+type BankruptcySummary struct {
+	// ActiveProcedure — процедура введена и после этого не прекращена
+	ActiveProcedure bool    `json:"active_procedure"`
+	Stage           *string `json:"stage,omitempty"`
+	// ProcedureStartDate — дата публикации о введении первой стадии текущей процедуры
+	ProcedureStartDate *string `json:"procedure_start_date,omitempty"`
+	LastIntentionDate  *string `json:"last_intention_date,omitempty"`
+	LiquidationDate    *string `json:"liquidation_decision_date,omitempty"`
+	ReorganizationDate *string `json:"reorganization_decision_date,omitempty"`
+}
+
+// SummarizeBankruptcyMessages дополняет сообщения сводкой. Сообщения рассматриваются
+// в порядке публикации; сообщения без корректной даты публикации не учитываются.
+func SummarizeBankruptcyMessages(messages *types.BankruptcyMessages) *BankruptcyReport {
+	summary := &BankruptcySummary{}
+	if messages == nil {
+		return &BankruptcyReport{Summary: summary}
+	}
+
+	type datedMessage struct {
+		date    string
+		message *types.BankruptcyMessage
+	}
+	var dated []datedMessage
+	for _, m := range messages.MessageList {
+		if m == nil || m.Kind == nil {
+			continue
+		}
+		if date, ok := types.DateOf(m.PublicationDate); ok {
+			dated = append(dated, datedMessage{date: date, message: m})
+		}
+	}
+	sort.SliceStable(dated, func(i, j int) bool { return dated[i].date < dated[j].date })
+
+	for _, d := range dated {
+		m := d.message
+		switch *m.Kind {
+		case types.BankruptcyMessageIntention:
+			summary.LastIntentionDate = m.PublicationDate
+		case types.BankruptcyMessageProcedureIntroduced:
+			if !summary.ActiveProcedure {
+				summary.ProcedureStartDate = m.PublicationDate
+			}
+			summary.ActiveProcedure = true
+			summary.Stage = m.Stage
+		case types.BankruptcyMessageProcedureTerminated:
+			summary.ActiveProcedure = false
+			summary.Stage = nil
+			summary.ProcedureStartDate = nil
+		case types.BankruptcyMessageLiquidationDecision:
+			summary.LiquidationDate = m.PublicationDate
+		case types.BankruptcyMessageReorganization:
+			summary.ReorganizationDate = m.PublicationDate
+		}
+	}
+	return &BankruptcyReport{Data: messages, Summary: summary}
+}
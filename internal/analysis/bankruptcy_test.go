@@ -0,0 +1,138 @@
+package analysis
+
+import (
+	"testing"
+
+	"scoring_worker/internal/credinform/types"
+)
+
+func TestSummarizeBankruptcyMessages(t *testing.T) {
+	msg := func(kind, date string, stage *string) *types.BankruptcyMessage {
+		return &types.BankruptcyMessage{Kind: s(kind), PublicationDate: s(date), Stage: stage}
+	}
+
+	tests := []struct {
+		name           string
+		messages       []*types.BankruptcyMessage
+		expectedActive bool
+		expectedStage  *string
+		expectedStart  *string
+	}{
+		{
+			// Намерение обратиться в суд ещё не означает введённой процедуры
+			name:     "intention_only",
+			messages: []*types.BankruptcyMessage{msg(types.BankruptcyMessageIntention, "2024-02-01", nil)},
+		},
+		{
+			name: "observation_introduced",
+			messages: []*types.BankruptcyMessage{
+				msg(types.BankruptcyMessageIntention, "2024-02-01", nil),
+				msg(types.BankruptcyMessageProcedureIntroduced, "2024-04-10", s(types.BankruptcyStageObservation)),
+			},
+			expectedActive: true,
+			expectedStage:  s(types.BankruptcyStageObservation),
+			expectedStart:  s("2024-04-10"),
+		},
+		{
+			// Смена стадии не меняет дату начала процедуры
+			name: "stage_changed",
+			messages: []*types.BankruptcyMessage{
+				msg(types.BankruptcyMessageProcedureIntroduced, "2024-04-10", s(types.BankruptcyStageObservation)),
+				msg(types.BankruptcyMessageProcedureIntroduced, "2024-10-01", s(types.BankruptcyStageCompetitiveProceedings)),
+			},
+			expectedActive: true,
+			expectedStage:  s(types.BankruptcyStageCompetitiveProceedings),
+			expectedStart:  s("2024-04-10"),
+		},
+		{
+			name: "terminated",
+			messages: []*types.BankruptcyMessage{
+				msg(types.BankruptcyMessageProcedureIntroduced, "2024-04-10", s(types.BankruptcyStageObservation)),
+				msg(types.BankruptcyMessageProcedureTerminated, "2024-08-20", nil),
+			},
+		},
+		{
+			// Сообщения упорядочиваются по дате публикации
+			name: "unsorted",
+			messages: []*types.BankruptcyMessage{
+				msg(types.BankruptcyMessageProcedureIntroduced, "2025-01-15", s(types.BankruptcyStageFinancialRecovery)),
+				msg(types.BankruptcyMessageProcedureTerminated, "2024-08-20", nil),
+				msg(types.BankruptcyMessageProcedureIntroduced, "2024-04-10", s(types.BankruptcyStageObservation)),
+			},
+			expectedActive: true,
+			expectedStage:  s(types.BankruptcyStageFinancialRecovery),
+			expectedStart:  s("2025-01-15"),
+		},
+		{
+			// Сообщения без даты публикации не учитываются
+			name: "undated",
+			messages: []*types.BankruptcyMessage{
+				{Kind: s(types.BankruptcyMessageProcedureIntroduced), Stage: s(types.BankruptcyStageObservation)},
+				nil,
+			},
+		},
+		{
+			// Сообщение с некорректной датой не прекращает процедуру
+			name: "malformed_date",
+			messages: []*types.BankruptcyMessage{
+				msg(types.BankruptcyMessageProcedureIntroduced, "2024-04-10T00:00:00", s(types.BankruptcyStageObservation)),
+				msg(types.BankruptcyMessageProcedureTerminated, "20.08.2024", nil),
+			},
+			expectedActive: true,
+			expectedStage:  s(types.BankruptcyStageObservation),
+			expectedStart:  s("2024-04-10T00:00:00"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := SummarizeBankruptcyMessages(&types.BankruptcyMessages{MessageList: tt.messages})
+			summary := report.Summary
+			if summary.ActiveProcedure != tt.expectedActive {
+				t.Errorf("expected active %t, got %t", tt.expectedActive, summary.ActiveProcedure)
+			}
+			if !equalPtr(summary.Stage, tt.expectedStage) {
+				t.Errorf("expected stage %v, got %v", deref(tt.expectedStage), deref(summary.Stage))
+			}
+			if !equalPtr(summary.ProcedureStartDate, tt.expectedStart) {
+				t.Errorf("expected start date %v, got %v", deref(tt.expectedStart), deref(summary.ProcedureStartDate))
+			}
+			if len(report.Data.MessageList) != len(tt.messages) {
+				t.Errorf("expected all %d messages kept, got %d", len(tt.messages), len(report.Data.MessageList))
+			}
+		})
+	}
+}
+
+func TestSummarizeBankruptcyMessagesDates(t *testing.T) {
+	report := SummarizeBankruptcyMessages(&types.BankruptcyMessages{MessageList: []*types.BankruptcyMessage{
+		{Kind: s(types.BankruptcyMessageIntention), PublicationDate: s("2023-05-01")},
+		{Kind: s(types.BankruptcyMessageIntention), PublicationDate: s("2024-03-01")},
+		{Kind: s(types.BankruptcyMessageReorganization), PublicationDate: s("2024-06-01")},
+		{Kind: s(types.BankruptcyMessageLiquidationDecision), PublicationDate: s("2024-07-01")},
+	}})
+
+	if deref(report.Summary.LastIntentionDate) != "2024-03-01" {
+		t.Errorf("expected last intention 2024-03-01, got %v", deref(report.Summary.LastIntentionDate))
+	}
+	if deref(report.Summary.ReorganizationDate) != "2024-06-01" {
+		t.Errorf("expected reorganization 2024-06-01, got %v", deref(report.Summary.ReorganizationDate))
+	}
+	if deref(report.Summary.LiquidationDate) != "2024-07-01" {
+		t.Errorf("expected liquidation 2024-07-01, got %v", deref(report.Summary.LiquidationDate))
+	}
+	if report.Summary.ActiveProcedure {
+		t.Error("expected no active procedure without introduction message")
+	}
+}
+
+func equalPtr(a, b *string) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
+
+func deref(v *string) string {
+	if v == nil {
+		return "<nil>"
+	}
+	return *v
+}
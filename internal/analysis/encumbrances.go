@@ -0,0 +1,115 @@
+package analysis
+
+import (
+	"sort"
+	"time"
+
+	"scoring_worker/internal/credinform/types"
+)
+
+// EncumbrancesReport — залоги и лизинг со сводкой, сохраняемые как pledges_and_leasing
+type EncumbrancesReport = Report[types.PledgesAndLeasing, EncumbrancesSummary]
+
+// EncumbrancesSummary — действующие обременения имущества компании на дату проверки:
+// залоги, где компания — залогодатель, и лизинг, где она — лизингополучатель
+type EncumbrancesSummary struct {
+	Date                   string `json:"date"`
+	ActivePledges          int    `json:"active_pledges"`
+	ActiveLeasingContracts int    `json:"active_leasing_contracts"`
+	// LastPledgeDate — дата регистрации последнего действующего залога
+	LastPledgeDate *string `json:"last_pledge_date,omitempty"`
+	// Holders — залогодержатели и лизингодатели по действующим обременениям,
+	// по убыванию их числа
+	Holders []*EncumbranceHolder `json:"holders"`
+}
+
+type EncumbranceHolder struct {
+	Name             *string `json:"name,omitempty"`
+	TaxNumber        *string `json:"tax_number,omitempty"`
+	Pledges          int     `json:"pledges"`
+	LeasingContracts int     `json:"leasing_contracts"`
+}
+
+// SummarizePledgesAndLeasing дополняет залоги и лизинг сводкой на момент now. Записи
+// без роли учитываются: список запрашивается только для залогодателя и лизингополучателя.
+func SummarizePledgesAndLeasing(data *types.PledgesAndLeasing, now time.Time) *EncumbrancesReport {
+	date := now.Format("2006-01-02")
+	summary := &EncumbrancesSummary{Date: date, Holders: []*EncumbranceHolder{}}
+	if data == nil {
+		return &EncumbrancesReport{Summary: summary}
+	}
+
+	holders := make(map[string]*EncumbranceHolder)
+	holder := func(p *participantKey) *EncumbranceHolder {
+		h := holders[p.key]
+		if h == nil {
+			h = &EncumbranceHolder{Name: p.participant.Name, TaxNumber: p.participant.TaxNumber}
+			holders[p.key] = h
+			summary.Holders = append(summary.Holders, h)
+		}
+		return h
+	}
+
+	for _, p := range data.PledgeList {
+		if p == nil || !hasRole(p.Role, types.EncumbranceRolePledgor) || !p.IsActive(date) {
+			continue
+		}
+		summary.ActivePledges++
+		if isLater(p.RegistrationDate, summary.LastPledgeDate) {
+			summary.LastPledgeDate = p.RegistrationDate
+		}
+		for _, key := range participantKeys(p.PledgeeList) {
+			holder(key).Pledges++
+		}
+	}
+	for _, l := range data.LeasingList {
+		if l == nil || !hasRole(l.Role, types.EncumbranceRoleLessee) || !l.IsActive(date) {
+			continue
+		}
+		summary.ActiveLeasingContracts++
+		for _, key := range participantKeys(l.LessorList) {
+			holder(key).LeasingContracts++
+		}
+	}
+
+	sort.SliceStable(summary.Holders, func(i, j int) bool {
+		return summary.Holders[i].Pledges+summary.Holders[i].LeasingContracts >
+			summary.Holders[j].Pledges+summary.Holders[j].LeasingContracts
+	})
+	return &EncumbrancesReport{Data: data, Summary: summary}
+}
+
+func hasRole(role *string, expected string) bool {
+	return role == nil || *role == expected
+}
+
+// participantKey — участник обременения с ключом группировки
+type participantKey struct {
+	key         string
+	participant *types.EncumbranceParticipant
+}
+
+// participantKeys группирует участников по ИНН, а без него — по наименованию;
+// участник, указанный в записи дважды, учитывается один раз
+func participantKeys(participants []*types.EncumbranceParticipant) []*participantKey {
+	var keys []*participantKey
+	seen := make(map[string]bool)
+	for _, p := range participants {
+		var key string
+		switch {
+		case p == nil:
+			continue
+		case p.TaxNumber != nil && *p.TaxNumber != "":
+			key = "inn:" + *p.TaxNumber
+		case p.Name != nil && *p.Name != "":
+			key = "name:" + *p.Name
+		default:
+			continue
+		}
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, &participantKey{key: key, participant: p})
+		}
+	}
+	return keys
+}
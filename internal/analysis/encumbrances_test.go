@@ -0,0 +1,110 @@
+package analysis
+
+import (
+	"testing"
+	"time"
+
+	"scoring_worker/internal/credinform/types"
+)
+
+func TestSummarizePledgesAndLeasing(t *testing.T) {
+	now := time.Date(2025, time.March, 10, 9, 0, 0, 0, time.UTC)
+	bank := &types.EncumbranceParticipant{Name: s("ПАО Банк"), TaxNumber: s("7707083893")}
+	lessor := &types.EncumbranceParticipant{Name: s("ООО Лизинг")}
+
+	data := &types.PledgesAndLeasing{
+		PledgeList: []*types.PledgeNotification{
+			{NotificationNumber: s("1"), RegistrationDate: s("2023-04-01"), Role: s(types.EncumbranceRolePledgor), PledgeeList: []*types.EncumbranceParticipant{bank}},
+			// Тот же залогодержатель под другим наименованием группируется по ИНН
+			{NotificationNumber: s("2"), RegistrationDate: s("2024-08-15"), PledgeeList: []*types.EncumbranceParticipant{{Name: s("Банк"), TaxNumber: s("7707083893")}, bank}},
+			// Исключён из реестра
+			{NotificationNumber: s("3"), RegistrationDate: s("2025-01-01"), ExclusionDate: s("2025-02-01"), PledgeeList: []*types.EncumbranceParticipant{bank}},
+			// Компания — залогодержатель, это не обременение её имущества
+			{NotificationNumber: s("4"), RegistrationDate: s("2025-02-01"), Role: s(types.EncumbranceRolePledgee)},
+			nil,
+		},
+		LeasingList: []*types.LeasingContract{
+			{ContractNumber: s("Л-1"), StartDate: s("2024-01-01"), EndDate: s("2027-01-01"), Role: s(types.EncumbranceRoleLessee), LessorList: []*types.EncumbranceParticipant{lessor}},
+			// Срок окончания — день проверки
+			{ContractNumber: s("Л-2"), EndDate: s("2025-03-10T00:00:00"), LessorList: []*types.EncumbranceParticipant{lessor, bank}},
+			{ContractNumber: s("Л-3"), EndDate: s("2025-03-09"), LessorList: []*types.EncumbranceParticipant{lessor}},
+			{ContractNumber: s("Л-4"), EndDate: s("2027-01-01"), TerminationDate: s("2024-12-01"), LessorList: []*types.EncumbranceParticipant{lessor}},
+			{ContractNumber: s("Л-5"), Role: s(types.EncumbranceRoleLessor)},
+		},
+	}
+
+	summary := SummarizePledgesAndLeasing(data, now).Summary
+	if summary.ActivePledges != 2 {
+		t.Errorf("expected 2 active pledges, got %d", summary.ActivePledges)
+	}
+	if summary.ActiveLeasingContracts != 2 {
+		t.Errorf("expected 2 active leasing contracts, got %d", summary.ActiveLeasingContracts)
+	}
+	if summary.LastPledgeDate == nil || *summary.LastPledgeDate != "2024-08-15" {
+		t.Errorf("expected last pledge date 2024-08-15, got %v", summary.LastPledgeDate)
+	}
+
+	if len(summary.Holders) != 2 {
+		t.Fatalf("expected 2 holders, got %d", len(summary.Holders))
+	}
+	if h := summary.Holders[0]; *h.TaxNumber != "7707083893" || h.Pledges != 2 || h.LeasingContracts != 1 {
+		t.Errorf("expected bank with 2 pledges and 1 leasing contract first, got %+v", h)
+	}
+	if h := summary.Holders[1]; *h.Name != "ООО Лизинг" || h.Pledges != 0 || h.LeasingContracts != 2 {
+		t.Errorf("expected lessor with 2 leasing contracts second, got %+v", h)
+	}
+}
+
+func TestSummarizePledgesAndLeasingDates(t *testing.T) {
+	now := time.Date(2025, time.March, 10, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		pledges         []*types.PledgeNotification
+		leasing         []*types.LeasingContract
+		expectedPledges int
+		expectedLeasing int
+		expectedLast    *string
+	}{
+		{
+			// Залог исключён в день проверки
+			name:    "excluded_on_date",
+			pledges: []*types.PledgeNotification{{ExclusionDate: s("2025-03-10T00:00:00")}},
+		},
+		{
+			// Некорректные даты исключения и окончания не учитываются
+			name:            "malformed_end_dates",
+			pledges:         []*types.PledgeNotification{{RegistrationDate: s("2024-01-01"), ExclusionDate: s("01.02.2025")}},
+			leasing:         []*types.LeasingContract{{EndDate: s("2025-02-30")}, {EndDate: s("2027-01-01"), TerminationDate: s("н/д")}},
+			expectedPledges: 1,
+			expectedLeasing: 2,
+			expectedLast:    s("2024-01-01"),
+		},
+		{
+			// Некорректная дата регистрации не становится последней
+			name: "malformed_registration_date",
+			pledges: []*types.PledgeNotification{
+				{RegistrationDate: s("2023-04-01")},
+				{RegistrationDate: s("15.08.2024")},
+			},
+			expectedPledges: 2,
+			expectedLast:    s("2023-04-01"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := &types.PledgesAndLeasing{PledgeList: tt.pledges, LeasingList: tt.leasing}
+			summary := SummarizePledgesAndLeasing(data, now).Summary
+			if summary.ActivePledges != tt.expectedPledges {
+				t.Errorf("expected %d active pledges, got %d", tt.expectedPledges, summary.ActivePledges)
+			}
+			if summary.ActiveLeasingContracts != tt.expectedLeasing {
+				t.Errorf("expected %d active leasing contracts, got %d", tt.expectedLeasing, summary.ActiveLeasingContracts)
+			}
+			if !equalPtr(summary.LastPledgeDate, tt.expectedLast) {
+				t.Errorf("expected last pledge date %v, got %v", deref(tt.expectedLast), deref(summary.LastPledgeDate))
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package analysis
+
+import (
+	"time"
+
+	"scoring_worker/internal/credinform/types"
+)
+
+// EnforcementReport — исполнительные производства со сводкой, сохраняемые как enforcement_proceedings
+type EnforcementReport = Report[types.EnforcementProceedings, EnforcementSummary]
+
+// EnforcementSummary — задолженность по исполнительным производствам на момент проверки
+type EnforcementSummary struct {
+	OpenCount int `json:"open_count"`
+	// OutstandingAmount — остаток долга по открытым производствам; если остаток
+	// не указан, учитывается вся сумма взыскания
+	OutstandingAmount float64 `json:"outstanding_amount"`
+	// OpenedLast12Months — производства, возбуждённые за 12 месяцев до проверки
+	OpenedLast12Months int `json:"opened_last_12_months"`
+}
+
+// SummarizeEnforcementProceedings дополняет производства сводкой на момент now
+func SummarizeEnforcementProceedings(proceedings *types.EnforcementProceedings, now time.Time) *EnforcementReport {
+	summary := &EnforcementSummary{}
+	if proceedings == nil {
+		return &EnforcementReport{Summary: summary}
+	}
+
+	since := now.AddDate(-1, 0, 0).Format("2006-01-02")
+	for _, p := range proceedings.ProceedingList {
+		if p == nil {
+			continue
+		}
+		if start, ok := types.DateOf(p.StartDate); ok && start >= since {
+			summary.OpenedLast12Months++
+		}
+		if !p.IsOpen() {
+			continue
+		}
+		summary.OpenCount++
+		switch {
+		case p.RemainingDebt != nil:
+			summary.OutstandingAmount += *p.RemainingDebt
+		case p.Amount != nil:
+			summary.OutstandingAmount += *p.Amount
+		}
+	}
+	return &EnforcementReport{Data: proceedings, Summary: summary}
+}
@@ -0,0 +1,86 @@
+package analysis
+
+import (
+	"testing"
+	"time"
+
+	"scoring_worker/internal/credinform/types"
+)
+
+func TestSummarizeEnforcementProceedings(t *testing.T) {
+	now := time.Date(2025, time.March, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                string
+		proceedings         []*types.EnforcementProceeding
+		expectedOpen        int
+		expectedOutstanding float64
+		expectedRecent      int
+	}{
+		{
+			name: "open_and_closed",
+			proceedings: []*types.EnforcementProceeding{
+				{Status: s(types.EnforcementProceedingStatusOpen), StartDate: s("2024-06-01"), Amount: f(1000), RemainingDebt: f(400)},
+				{Status: s(types.EnforcementProceedingStatusClosed), StartDate: s("2024-07-01"), EndDate: s("2024-12-01"), Amount: f(5000), RemainingDebt: f(0)},
+				{Status: s(types.EnforcementProceedingStatusOpen), StartDate: s("2022-01-15"), Amount: f(300), RemainingDebt: f(300)},
+			},
+			expectedOpen:        2,
+			expectedOutstanding: 700,
+			expectedRecent:      2,
+		},
+		{
+			// Без остатка долга учитывается вся сумма взыскания
+			name: "no_remaining_debt",
+			proceedings: []*types.EnforcementProceeding{
+				{Status: s(types.EnforcementProceedingStatusOpen), Amount: f(1200)},
+				{Status: s(types.EnforcementProceedingStatusOpen)},
+			},
+			expectedOpen:        2,
+			expectedOutstanding: 1200,
+		},
+		{
+			// Без статуса производство с датой окончания считается оконченным
+			name: "no_status",
+			proceedings: []*types.EnforcementProceeding{
+				{StartDate: s("2025-01-20T00:00:00"), RemainingDebt: f(50)},
+				{StartDate: s("2024-02-01"), EndDate: s("2024-09-01"), RemainingDebt: f(80)},
+				nil,
+			},
+			expectedOpen:        1,
+			expectedOutstanding: 50,
+			expectedRecent:      1,
+		},
+		{
+			// Граница окна — ровно 12 месяцев до проверки
+			name: "window_boundary",
+			proceedings: []*types.EnforcementProceeding{
+				{Status: s(types.EnforcementProceedingStatusClosed), StartDate: s("2024-03-10")},
+				{Status: s(types.EnforcementProceedingStatusClosed), StartDate: s("2024-03-09")},
+			},
+			expectedRecent: 1,
+		},
+		{
+			// Некорректная дата возбуждения не попадает в окно
+			name: "malformed_start_date",
+			proceedings: []*types.EnforcementProceeding{
+				{Status: s(types.EnforcementProceedingStatusClosed), StartDate: s("15.01.2025")},
+				{Status: s(types.EnforcementProceedingStatusClosed), StartDate: s("2025-13-01")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := SummarizeEnforcementProceedings(&types.EnforcementProceedings{ProceedingList: tt.proceedings}, now).Summary
+			if summary.OpenCount != tt.expectedOpen {
+				t.Errorf("expected %d open proceedings, got %d", tt.expectedOpen, summary.OpenCount)
+			}
+			if summary.OutstandingAmount != tt.expectedOutstanding {
+				t.Errorf("expected outstanding %v, got %v", tt.expectedOutstanding, summary.OutstandingAmount)
+			}
+			if summary.OpenedLast12Months != tt.expectedRecent {
+				t.Errorf("expected %d proceedings opened in last 12 months, got %d", tt.expectedRecent, summary.OpenedLast12Months)
+			}
+		})
+	}
+}
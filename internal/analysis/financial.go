@@ -0,0 +1,144 @@
+// Package analysis вычисляет производные показатели по данным Credinform,
+// чтобы потребителям не приходилось повторять расчёты у себя.
+package analysis
+
+import "scoring_worker/internal/credinform/types"
+
+// Признаки, которыми помечается отчётный год
+const (
+	FlagNegativeNetAssets = "NEGATIVE_NET_ASSETS"
+	FlagSharpRevenueDrop  = "SHARP_REVENUE_DROP"
+)
+
+// sharpRevenueDrop — падение выручки к предыдущему году, начиная с которого оно считается резким
+const sharpRevenueDrop = -0.3
+
+// FinancialAnalysis — финансовые коэффициенты по отчётным годам. Flags содержит
+// признаки последнего отчётного года.
+type FinancialAnalysis struct {
+	Years []*FinancialYearAnalysis `json:"years"`
+	Flags []string                 `json:"flags"`
+}
+
+// FinancialYearAnalysis — коэффициенты за год. Коэффициент отсутствует, если для
+// него не хватает строк отчётности или знаменатель равен нулю.
+type FinancialYearAnalysis struct {
+	Year int `json:"year"`
+	// CurrentRatio — коэффициент текущей ликвидности: оборотные активы / краткосрочные обязательства
+	CurrentRatio *float64 `json:"current_ratio,omitempty"`
+	// AutonomyRatio — коэффициент автономии: капитал и резервы / валюта баланса
+	AutonomyRatio *float64 `json:"autonomy_ratio,omitempty"`
+	// DebtToEquity — (долгосрочные + краткосрочные обязательства) / капитал и резервы
+	DebtToEquity *float64 `json:"debt_to_equity,omitempty"`
+	// ReturnOnSales — прибыль от продаж / выручка
+	ReturnOnSales *float64 `json:"return_on_sales,omitempty"`
+	// ReturnOnAssets — чистая прибыль / средняя за год валюта баланса
+	ReturnOnAssets *float64 `json:"return_on_assets,omitempty"`
+	// RevenueGrowth — изменение выручки к предыдущему году в долях
+	RevenueGrowth *float64 `json:"revenue_growth,omitempty"`
+	// NetAssets — чистые активы, оцениваемые по капиталу и резервам
+	NetAssets      *float64 `json:"net_assets,omitempty"`
+	CharterCapital *float64 `json:"charter_capital,omitempty"`
+	// NetAssetsToCharterCapital — чистые активы / уставный капитал
+	NetAssetsToCharterCapital *float64 `json:"net_assets_to_charter_capital,omitempty"`
+	Flags                     []string `json:"flags,omitempty"`
+}
+
+// AnalyzeFinancials вычисляет коэффициенты по каждому году отчётности.
+// Годы в statements должны быть упорядочены по возрастанию.
+func AnalyzeFinancials(statements *types.FinancialStatements) *FinancialAnalysis {
+	result := &FinancialAnalysis{Years: []*FinancialYearAnalysis{}, Flags: []string{}}
+	if statements == nil {
+		return result
+	}
+
+	var prev *types.FinancialYear
+	for _, year := range statements.Years {
+		if year == nil {
+			continue
+		}
+		result.Years = append(result.Years, analyzeYear(year, prev))
+		prev = year
+	}
+
+	if n := len(result.Years); n > 0 && result.Years[n-1].Flags != nil {
+		result.Flags = result.Years[n-1].Flags
+	}
+	return result
+}
+
+func analyzeYear(year, prev *types.FinancialYear) *FinancialYearAnalysis {
+	a := &FinancialYearAnalysis{Year: year.Year}
+
+	if b := year.BalanceSheet; b != nil {
+		a.CurrentRatio = ratio(b.CurrentAssets, b.ShortTermLiabilities)
+		a.AutonomyRatio = ratio(b.Equity, b.TotalAssets)
+		a.DebtToEquity = ratio(sum(b.LongTermLiabilities, b.ShortTermLiabilities), b.Equity)
+		a.NetAssets = b.Equity
+		a.CharterCapital = b.CharterCapital
+		a.NetAssetsToCharterCapital = ratio(b.Equity, b.CharterCapital)
+
+		if b.Equity != nil && *b.Equity < 0 {
+			a.Flags = append(a.Flags, FlagNegativeNetAssets)
+		}
+	}
+
+	if p := year.ProfitAndLoss; p != nil {
+		a.ReturnOnSales = ratio(p.SalesProfit, p.Revenue)
+		if year.BalanceSheet != nil {
+			a.ReturnOnAssets = ratio(p.NetProfit, averageAssets(year, prev))
+		}
+		if prev != nil && prev.ProfitAndLoss != nil {
+			a.RevenueGrowth = growth(p.Revenue, prev.ProfitAndLoss.Revenue)
+		}
+		if a.RevenueGrowth != nil && *a.RevenueGrowth <= sharpRevenueDrop {
+			a.Flags = append(a.Flags, FlagSharpRevenueDrop)
+		}
+	}
+
+	return a
+}
+
+// averageAssets возвращает среднюю валюту баланса за год или, если предыдущего
+// года нет, валюту баланса на конец года
+func averageAssets(year, prev *types.FinancialYear) *float64 {
+	current := year.BalanceSheet.TotalAssets
+	if current == nil || prev == nil || prev.BalanceSheet == nil || prev.BalanceSheet.TotalAssets == nil {
+		return current
+	}
+	avg := (*current + *prev.BalanceSheet.TotalAssets) / 2
+	return &avg
+}
+
+func ratio(numerator, denominator *float64) *float64 {
+	if numerator == nil || denominator == nil || *denominator == 0 {
+		return nil
+	}
+	r := *numerator / *denominator
+	return &r
+}
+
+// growth возвращает относительное изменение; для неположительной базы не определено
+func growth(current, previous *float64) *float64 {
+	if current == nil || previous == nil || *previous <= 0 {
+		return nil
+	}
+	g := (*current - *previous) / *previous
+	return &g
+}
+
+// sum складывает известные слагаемые; если неизвестны все, результат не определён
+func sum(values ...*float64) *float64 {
+	var total float64
+	known := false
+	for _, v := range values {
+		if v != nil {
+			total += *v
+			known = true
+		}
+	}
+	if !known {
+		return nil
+	}
+	return &total
+}
@@ -0,0 +1,94 @@
+package analysis
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"scoring_worker/internal/credinform/types"
+)
+
+func f(v float64) *float64 { return &v }
+
+func TestAnalyzeFinancials(t *testing.T) {
+	statements := &types.FinancialStatements{Years: []*types.FinancialYear{
+		{
+			Year:          2022,
+			BalanceSheet:  &types.BalanceSheet{TotalAssets: f(1000), Equity: f(400)},
+			ProfitAndLoss: &types.ProfitAndLoss{Revenue: f(2000)},
+		},
+		{
+			Year: 2023,
+			BalanceSheet: &types.BalanceSheet{
+				CurrentAssets:        f(600),
+				ShortTermLiabilities: f(300),
+				LongTermLiabilities:  f(100),
+				TotalAssets:          f(1200),
+				Equity:               f(800),
+				CharterCapital:       f(10),
+			},
+			ProfitAndLoss: &types.ProfitAndLoss{Revenue: f(2500), SalesProfit: f(250), NetProfit: f(110)},
+		},
+		{
+			Year:          2024,
+			BalanceSheet:  &types.BalanceSheet{TotalAssets: f(900), Equity: f(-50), CharterCapital: f(10), ShortTermLiabilities: f(0)},
+			ProfitAndLoss: &types.ProfitAndLoss{Revenue: f(1500)},
+		},
+	}}
+
+	result := AnalyzeFinancials(statements)
+	if len(result.Years) != 3 {
+		t.Fatalf("expected 3 analyzed years, got %d", len(result.Years))
+	}
+
+	tests := []struct {
+		name     string
+		got      *float64
+		expected *float64
+	}{
+		{"2023 current ratio", result.Years[1].CurrentRatio, f(2)},
+		{"2023 autonomy ratio", result.Years[1].AutonomyRatio, f(800.0 / 1200)},
+		{"2023 debt to equity", result.Years[1].DebtToEquity, f(0.5)},
+		{"2023 return on sales", result.Years[1].ReturnOnSales, f(0.1)},
+		{"2023 return on average assets", result.Years[1].ReturnOnAssets, f(0.1)},
+		{"2023 revenue growth", result.Years[1].RevenueGrowth, f(0.25)},
+		{"2023 net assets to charter capital", result.Years[1].NetAssetsToCharterCapital, f(80)},
+		{"2022 revenue growth without previous year", result.Years[0].RevenueGrowth, nil},
+		{"2022 current ratio without lines", result.Years[0].CurrentRatio, nil},
+		{"2024 current ratio with zero liabilities", result.Years[2].CurrentRatio, nil},
+		{"2024 revenue growth", result.Years[2].RevenueGrowth, f(-0.4)},
+		{"2024 net assets", result.Years[2].NetAssets, f(-50)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.expected == nil {
+				if tt.got != nil {
+					t.Errorf("expected no value, got %v", *tt.got)
+				}
+				return
+			}
+			if tt.got == nil || math.Abs(*tt.got-*tt.expected) > 1e-9 {
+				t.Errorf("expected %v, got %v", *tt.expected, tt.got)
+			}
+		})
+	}
+
+	if len(result.Years[1].Flags) != 0 {
+		t.Errorf("expected no flags for 2023, got %v", result.Years[1].Flags)
+	}
+	// Отрицательные чистые активы и падение выручки на 40% отмечаются в последнем году
+	expectedFlags := FlagNegativeNetAssets + "," + FlagSharpRevenueDrop
+	if strings.Join(result.Years[2].Flags, ",") != expectedFlags {
+		t.Errorf("expected 2024 flags %s, got %v", expectedFlags, result.Years[2].Flags)
+	}
+	if strings.Join(result.Flags, ",") != expectedFlags {
+		t.Errorf("expected summary flags %s, got %v", expectedFlags, result.Flags)
+	}
+}
+
+func TestAnalyzeFinancialsEmpty(t *testing.T) {
+	result := AnalyzeFinancials(nil)
+	if result.Years == nil || result.Flags == nil || len(result.Years) != 0 {
+		t.Errorf("expected empty non-nil analysis, got %+v", result)
+	}
+}
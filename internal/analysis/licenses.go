@@ -0,0 +1,114 @@
+package analysis
+
+import (
+	"strings"
+	"time"
+
+	"scoring_worker/internal/credinform/types"
+)
+
+// LicensesReport — лицензии и членство в СРО со сводкой, сохраняемые как licenses
+type LicensesReport = Report[types.Licenses, LicensesSummary]
+
+// LicensesSummary — действующие и недействующие разрешения на дату проверки
+type LicensesSummary struct {
+	// Date — дата проверки, на которую определено действие
+	Date                   string `json:"date"`
+	ActiveLicenses         int    `json:"active_licenses"`
+	InactiveLicenses       int    `json:"inactive_licenses"`
+	ActiveSroMemberships   int    `json:"active_sro_memberships"`
+	InactiveSroMemberships int    `json:"inactive_sro_memberships"`
+	// UnlicensedActivities — актуальные виды деятельности из лицензируемых групп ОКВЭД,
+	// не покрытые действующей лицензией или членством в СРО; null, если сверка недоступна
+	UnlicensedActivities []*UnlicensedActivity `json:"unlicensed_activities"`
+	// ActivitiesUnavailable — виды деятельности получить не удалось, сверка не выполнена
+	ActivitiesUnavailable bool `json:"activities_unavailable,omitempty"`
+}
+
+type UnlicensedActivity struct {
+	Code   string  `json:"code"`
+	Name   *string `json:"name,omitempty"`
+	IsMain bool    `json:"is_main"`
+	// Group — группа ОКВЭД из списка лицензируемых, к которой относится код
+	Group string `json:"group"`
+}
+
+// SummarizeLicenses дополняет лицензии сводкой на момент now. licensedGroups — коды
+// ОКВЭД ("86", "47.73"), деятельность по которым и их подкодам требует лицензии или СРО.
+// Деятельность покрыта, если действующее разрешение указывает код той же группы.
+// Без activities сверка помечается недоступной.
+func SummarizeLicenses(licenses *types.Licenses, activities *types.Activities, licensedGroups []string, now time.Time) *LicensesReport {
+	date := now.Format("2006-01-02")
+	summary := &LicensesSummary{Date: date}
+	// Без лицензий сверка по-прежнему выполняется: вся лицензируемая деятельность не покрыта
+	list := licenses
+	if list == nil {
+		list = &types.Licenses{}
+	}
+
+	covered := make(map[string]bool)
+	cover := func(codes []string) {
+		for _, code := range codes {
+			if group, ok := licensedGroup(code, licensedGroups); ok {
+				covered[group] = true
+			}
+		}
+	}
+	for _, l := range list.LicenseList {
+		if l == nil {
+			continue
+		}
+		if l.IsActive(date) {
+			summary.ActiveLicenses++
+			cover(l.ActivityCodes)
+		} else {
+			summary.InactiveLicenses++
+		}
+	}
+	for _, m := range list.SroMembershipList {
+		if m == nil {
+			continue
+		}
+		if m.IsActive(date) {
+			summary.ActiveSroMemberships++
+			cover(m.ActivityCodes)
+		} else {
+			summary.InactiveSroMemberships++
+		}
+	}
+
+	if activities == nil {
+		summary.ActivitiesUnavailable = true
+		return &LicensesReport{Data: licenses, Summary: summary}
+	}
+	summary.UnlicensedActivities = []*UnlicensedActivity{}
+	for _, a := range activities.KindOfActivityList {
+		if a == nil || a.Industry == nil || a.Industry.Code == nil || (a.IsActual != nil && !*a.IsActual) {
+			continue
+		}
+		group, ok := licensedGroup(*a.Industry.Code, licensedGroups)
+		if !ok || covered[group] {
+			continue
+		}
+		summary.UnlicensedActivities = append(summary.UnlicensedActivities, &UnlicensedActivity{
+			Code:   *a.Industry.Code,
+			Name:   a.Industry.Name,
+			IsMain: a.IsMain != nil && *a.IsMain,
+			Group:  group,
+		})
+	}
+	return &LicensesReport{Data: licenses, Summary: summary}
+}
+
+// licensedGroup возвращает самую длинную группу, к которой относится код ОКВЭД.
+// Группа совпадает с кодом по целым сегментам: "47.73" включает "47.73.1",
+// но не "47.731", а "4" не включает "41.20".
+func licensedGroup(code string, groups []string) (string, bool) {
+	var match string
+	for _, group := range groups {
+		if (code == group || strings.HasPrefix(code, group+".")) && len(group) > len(match) {
+			match = group
+		}
+	}
+	return match, match != ""
+}
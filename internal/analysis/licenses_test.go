@@ -0,0 +1,114 @@
+package analysis
+
+import (
+	"testing"
+	"time"
+
+	"scoring_worker/internal/credinform/types"
+)
+
+func TestSummarizeLicenses(t *testing.T) {
+	now := time.Date(2025, time.March, 10, 15, 0, 0, 0, time.UTC)
+	b := func(v bool) *bool { return &v }
+	activity := func(code, name string, isMain, isActual bool) *types.KindOfActivity {
+		return &types.KindOfActivity{Industry: &types.Industry{Code: s(code), Name: s(name)}, IsMain: b(isMain), IsActual: b(isActual)}
+	}
+
+	licenses := &types.Licenses{
+		LicenseList: []*types.License{
+			// Бессрочная лицензия на медицинскую деятельность
+			{Number: s("Л041-1"), StartDate: s("2019-01-01"), Status: s(types.LicenseStatusActive), ActivityCodes: []string{"86.10"}},
+			// Лицензия истекает в день проверки и ещё действует
+			{Number: s("Л041-2"), StartDate: s("2020-03-10"), EndDate: s("2025-03-10T00:00:00"), ActivityCodes: []string{"80.10"}},
+			{Number: s("Л041-3"), StartDate: s("2018-01-01"), EndDate: s("2024-12-31"), ActivityCodes: []string{"47.73"}},
+			{Number: s("Л041-4"), StartDate: s("2021-01-01"), Status: s(types.LicenseStatusRevoked), ActivityCodes: []string{"85.41"}},
+			nil,
+		},
+		SroMembershipList: []*types.SroMembership{
+			{MembershipNumber: s("СРО-С-1"), StartDate: s("2022-05-01"), Status: s(types.LicenseStatusActive), ActivityCodes: []string{"41.20"}},
+			{MembershipNumber: s("СРО-П-2"), StartDate: s("2026-01-01"), ActivityCodes: []string{"71.12"}},
+		},
+	}
+	activities := &types.Activities{KindOfActivityList: []*types.KindOfActivity{
+		activity("86.21", "Общая врачебная практика", true, true),
+		activity("80.10", "Деятельность частных охранных служб", false, true),
+		activity("47.73", "Торговля лекарствами в аптеках", false, true),
+		activity("85.41", "Дополнительное образование", false, true),
+		activity("41.20", "Строительство зданий", false, true),
+		activity("71.12", "Инженерные изыскания", false, true),
+		// Неактуальная и нелицензируемая деятельность не проверяется
+		activity("65.12", "Страхование", false, false),
+		activity("62.01", "Разработка ПО", false, true),
+		{Industry: &types.Industry{Name: s("Без кода")}},
+		nil,
+	}}
+
+	summary := SummarizeLicenses(licenses, activities, []string{"86", "80.10", "47.73", "85", "41.20", "71.12", "65"}, now).Summary
+	if summary.Date != "2025-03-10" {
+		t.Errorf("expected date 2025-03-10, got %s", summary.Date)
+	}
+	if summary.ActiveLicenses != 2 || summary.InactiveLicenses != 2 {
+		t.Errorf("expected 2 active and 2 inactive licenses, got %d and %d", summary.ActiveLicenses, summary.InactiveLicenses)
+	}
+	if summary.ActiveSroMemberships != 1 || summary.InactiveSroMemberships != 1 {
+		t.Errorf("expected 1 active and 1 inactive SRO membership, got %d and %d", summary.ActiveSroMemberships, summary.InactiveSroMemberships)
+	}
+
+	expected := []UnlicensedActivity{
+		{Code: "47.73", Group: "47.73"},
+		{Code: "85.41", Group: "85"},
+		{Code: "71.12", Group: "71.12"},
+	}
+	if len(summary.UnlicensedActivities) != len(expected) {
+		t.Fatalf("expected %d unlicensed activities, got %d", len(expected), len(summary.UnlicensedActivities))
+	}
+	for i, e := range expected {
+		got := summary.UnlicensedActivities[i]
+		if got.Code != e.Code || got.Group != e.Group || got.Name == nil {
+			t.Errorf("expected unlicensed activity %s in group %s, got %+v", e.Code, e.Group, got)
+		}
+	}
+}
+
+func TestLicensedGroup(t *testing.T) {
+	groups := []string{"4", "47", "47.73", "86", "86.1"}
+
+	tests := []struct {
+		code          string
+		expected      string
+		expectedFound bool
+	}{
+		{code: "47.73", expected: "47.73", expectedFound: true},
+		{code: "47.73.1", expected: "47.73", expectedFound: true},
+		{code: "47.11", expected: "47", expectedFound: true},
+		{code: "86", expected: "86", expectedFound: true},
+		// Группа совпадает только с целыми сегментами кода
+		{code: "47.74", expected: "47", expectedFound: true},
+		{code: "86.10", expected: "86", expectedFound: true},
+		{code: "41.20"},
+		{code: "62.01"},
+		{code: "4", expected: "4", expectedFound: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			group, found := licensedGroup(tt.code, groups)
+			if group != tt.expected || found != tt.expectedFound {
+				t.Errorf("expected %q (%t), got %q (%t)", tt.expected, tt.expectedFound, group, found)
+			}
+		})
+	}
+}
+
+func TestSummarizeLicensesActivitiesUnavailable(t *testing.T) {
+	licenses := &types.Licenses{LicenseList: []*types.License{{Status: s(types.LicenseStatusActive), ActivityCodes: []string{"86.10"}}}}
+	summary := SummarizeLicenses(licenses, nil, []string{"86"}, time.Now()).Summary
+
+	// Лицензии учитываются, а сверка с деятельностью помечается недоступной
+	if summary.ActiveLicenses != 1 {
+		t.Errorf("expected 1 active license, got %d", summary.ActiveLicenses)
+	}
+	if !summary.ActivitiesUnavailable || summary.UnlicensedActivities != nil {
+		t.Errorf("expected unlicensed check to be unavailable, got %+v", summary)
+	}
+}
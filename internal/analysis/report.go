@@ -0,0 +1,45 @@
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"scoring_worker/internal/credinform/types"
+)
+
+// Report — данные Credinform со сводкой. Сохраняется одним объектом: поля данных
+// и ключ summary; без данных — только сводка.
+type Report[T, S any] struct {
+	Data    *T
+	Summary *S
+}
+
+func (r Report[T, S]) MarshalJSON() ([]byte, error) {
+	fields := make(map[string]json.RawMessage)
+	if r.Data != nil {
+		raw, err := json.Marshal(r.Data)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			return nil, fmt.Errorf("failed to inline report data: %w", err)
+		}
+	}
+	summary, err := json.Marshal(r.Summary)
+	if err != nil {
+		return nil, err
+	}
+	fields["summary"] = summary
+	return json.Marshal(fields)
+}
+
+// isLater сообщает, позже ли дата candidate даты current. Некорректная дата
+// candidate не учитывается, некорректная current заменяется.
+func isLater(candidate, current *string) bool {
+	c, ok := types.DateOf(candidate)
+	if !ok {
+		return false
+	}
+	d, ok := types.DateOf(current)
+	return !ok || c > d
+}
@@ -0,0 +1,64 @@
+package analysis
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"scoring_worker/internal/credinform/types"
+)
+
+func TestReportMarshalJSON(t *testing.T) {
+	now := time.Date(2025, time.March, 10, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		report   any
+		expected string
+	}{
+		{
+			// Поля данных и сводка сохраняются одним документом
+			name: "data_and_summary",
+			report: SummarizeEnforcementProceedings(&types.EnforcementProceedings{ProceedingList: []*types.EnforcementProceeding{
+				{Status: s(types.EnforcementProceedingStatusOpen), Amount: f(100)},
+			}}, now),
+			expected: `{"enforcementProceedingList":[{"status":"Open","amount":100}],"summary":{"open_count":1,"outstanding_amount":100,"opened_last_12_months":0}}`,
+		},
+		{
+			// Без данных сохраняется только сводка
+			name:     "nil_data",
+			report:   SummarizeArbitrageCases(nil),
+			expected: `{"summary":{"open_as_defendant":0,"open_as_defendant_claim_sum":0}}`,
+		},
+		{
+			name:     "nil_data_empty_groups",
+			report:   SummarizeStateContracts(nil),
+			expected: `{"summary":{"as_supplier":{"count":0,"sum":0,"by_year":[],"by_counterparty":[]},"as_customer":{"count":0,"sum":0,"by_year":[],"by_counterparty":[]}}}`,
+		},
+		{
+			name:     "nil_data_empty_holders",
+			report:   SummarizePledgesAndLeasing(nil, now),
+			expected: `{"summary":{"date":"2025-03-10","active_pledges":0,"active_leasing_contracts":0,"holders":[]}}`,
+		},
+		{
+			// Без лицензий вся актуальная лицензируемая деятельность не покрыта
+			name: "nil_licenses",
+			report: SummarizeLicenses(nil, &types.Activities{KindOfActivityList: []*types.KindOfActivity{
+				{Industry: &types.Industry{Code: s("86.10")}},
+			}}, []string{"86"}, now),
+			expected: `{"summary":{"date":"2025-03-10","active_licenses":0,"inactive_licenses":0,"active_sro_memberships":0,"inactive_sro_memberships":0,"unlicensed_activities":[{"code":"86.10","is_main":false,"group":"86"}]}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.report)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, data)
+			}
+		})
+	}
+}
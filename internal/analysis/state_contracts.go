@@ -0,0 +1,157 @@
+package analysis
+
+import (
+	"sort"
+	"strconv"
+
+	"scoring_worker/internal/credinform/types"
+)
+
+// StateContractsReport — государственные контракты со сводкой, сохраняемые как state_contracts
+type StateContractsReport = Report[types.StateContracts, StateContractsSummary]
+
+// StateContractsSummary — участие в госзакупках отдельно в роли поставщика и заказчика.
+// Если список получен не полностью, сводка учитывает только полученные контракты.
+type StateContractsSummary struct {
+	AsSupplier *ContractParticipation `json:"as_supplier"`
+	AsCustomer *ContractParticipation `json:"as_customer"`
+}
+
+// ContractParticipation — контракты в одной роли. ByCounterparty группирует их по
+// заказчикам для поставщика и по поставщикам для заказчика.
+type ContractParticipation struct {
+	Count          int                     `json:"count"`
+	Sum            float64                 `json:"sum"`
+	ByYear         []*ContractYearTotal    `json:"by_year"`
+	ByCounterparty []*ContractCounterparty `json:"by_counterparty"`
+}
+
+type ContractYearTotal struct {
+	Year  int     `json:"year"`
+	Count int     `json:"count"`
+	Sum   float64 `json:"sum"`
+}
+
+type ContractCounterparty struct {
+	Name      *string `json:"name,omitempty"`
+	TaxNumber *string `json:"tax_number,omitempty"`
+	Count     int     `json:"count"`
+	Sum       float64 `json:"sum"`
+}
+
+// SummarizeStateContracts дополняет контракты сводкой. Контракты без даты заключения
+// не попадают в разбивку по годам, без роли — не учитываются.
+func SummarizeStateContracts(contracts *types.StateContracts) *StateContractsReport {
+	supplier, customer := newParticipation(), newParticipation()
+	summary := &StateContractsSummary{AsSupplier: supplier.result, AsCustomer: customer.result}
+	if contracts == nil {
+		return &StateContractsReport{Summary: summary}
+	}
+
+	for _, c := range contracts.ContractList {
+		if c == nil || c.Role == nil {
+			continue
+		}
+		switch *c.Role {
+		case types.StateContractRoleSupplier:
+			supplier.add(c, c.Customer)
+		case types.StateContractRoleCustomer:
+			customer.add(c, c.Supplier)
+		}
+	}
+	supplier.finish()
+	customer.finish()
+	return &StateContractsReport{Data: contracts, Summary: summary}
+}
+
+// participationBuilder накапливает ContractParticipation для одной роли
+type participationBuilder struct {
+	result         *ContractParticipation
+	years          map[int]*ContractYearTotal
+	counterparties map[string]*ContractCounterparty
+}
+
+func newParticipation() *participationBuilder {
+	return &participationBuilder{
+		result:         &ContractParticipation{ByYear: []*ContractYearTotal{}, ByCounterparty: []*ContractCounterparty{}},
+		years:          make(map[int]*ContractYearTotal),
+		counterparties: make(map[string]*ContractCounterparty),
+	}
+}
+
+func (b *participationBuilder) add(c *types.StateContract, counterparty *types.StateContractParticipant) {
+	var amount float64
+	if c.Amount != nil {
+		amount = *c.Amount
+	}
+	b.result.Count++
+	b.result.Sum += amount
+
+	if year, ok := contractYear(c.SignDate); ok {
+		total := b.years[year]
+		if total == nil {
+			total = &ContractYearTotal{Year: year}
+			b.years[year] = total
+		}
+		total.Count++
+		total.Sum += amount
+	}
+
+	if key, ok := counterpartyKey(counterparty); ok {
+		total := b.counterparties[key]
+		if total == nil {
+			total = &ContractCounterparty{Name: counterparty.Name, TaxNumber: counterparty.TaxNumber}
+			b.counterparties[key] = total
+		}
+		total.Count++
+		total.Sum += amount
+	}
+}
+
+// finish упорядочивает годы по возрастанию, контрагентов — по убыванию суммы
+func (b *participationBuilder) finish() {
+	for _, total := range b.years {
+		b.result.ByYear = append(b.result.ByYear, total)
+	}
+	sort.Slice(b.result.ByYear, func(i, j int) bool { return b.result.ByYear[i].Year < b.result.ByYear[j].Year })
+
+	for _, total := range b.counterparties {
+		b.result.ByCounterparty = append(b.result.ByCounterparty, total)
+	}
+	sort.Slice(b.result.ByCounterparty, func(i, j int) bool {
+		a, c := b.result.ByCounterparty[i], b.result.ByCounterparty[j]
+		if a.Sum != c.Sum {
+			return a.Sum > c.Sum
+		}
+		return counterpartyName(a) < counterpartyName(c)
+	})
+}
+
+func contractYear(date *string) (int, bool) {
+	if date == nil || len(*date) < 4 {
+		return 0, false
+	}
+	year, err := strconv.Atoi((*date)[:4])
+	return year, err == nil
+}
+
+// counterpartyKey группирует контрагентов по ИНН, а без него — по наименованию
+func counterpartyKey(p *types.StateContractParticipant) (string, bool) {
+	switch {
+	case p == nil:
+		return "", false
+	case p.TaxNumber != nil && *p.TaxNumber != "":
+		return "inn:" + *p.TaxNumber, true
+	case p.Name != nil && *p.Name != "":
+		return "name:" + *p.Name, true
+	default:
+		return "", false
+	}
+}
+
+func counterpartyName(c *ContractCounterparty) string {
+	if c.Name != nil {
+		return *c.Name
+	}
+	return ""
+}
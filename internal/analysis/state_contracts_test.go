@@ -0,0 +1,60 @@
+package analysis
+
+import (
+	"testing"
+
+	"scoring_worker/internal/credinform/types"
+)
+
+func TestSummarizeStateContracts(t *testing.T) {
+	supplied := func(date string, amount float64, customer *types.StateContractParticipant) *types.StateContract {
+		return &types.StateContract{Role: s(types.StateContractRoleSupplier), SignDate: s(date), Amount: f(amount), Customer: customer}
+	}
+	ministry := &types.StateContractParticipant{Name: s("Минздрав"), TaxNumber: s("7707778246")}
+	hospital := &types.StateContractParticipant{Name: s("ГБУЗ ГКБ №1")}
+
+	report := SummarizeStateContracts(&types.StateContracts{ContractList: []*types.StateContract{
+		supplied("2023-03-01", 100, ministry),
+		supplied("2024-05-10T00:00:00", 250, hospital),
+		supplied("2024-11-20", 300, ministry),
+		// Тот же заказчик с другим наименованием группируется по ИНН
+		supplied("2024-12-01", 50, &types.StateContractParticipant{Name: s("Министерство здравоохранения РФ"), TaxNumber: s("7707778246")}),
+		// Без даты контракт учитывается только в итогах и по контрагентам
+		{Role: s(types.StateContractRoleSupplier), Amount: f(10), Customer: hospital},
+		{Role: s(types.StateContractRoleCustomer), SignDate: s("2024-02-02"), Amount: f(70), Supplier: &types.StateContractParticipant{TaxNumber: s("7700000001")}},
+		{SignDate: s("2024-02-02"), Amount: f(999)},
+		nil,
+	}})
+
+	supplier := report.Summary.AsSupplier
+	if supplier.Count != 5 || supplier.Sum != 710 {
+		t.Errorf("expected 5 contracts for 710 as supplier, got %d for %v", supplier.Count, supplier.Sum)
+	}
+
+	expectedYears := []ContractYearTotal{{Year: 2023, Count: 1, Sum: 100}, {Year: 2024, Count: 3, Sum: 600}}
+	if len(supplier.ByYear) != len(expectedYears) {
+		t.Fatalf("expected %d years, got %d", len(expectedYears), len(supplier.ByYear))
+	}
+	for i, expected := range expectedYears {
+		if *supplier.ByYear[i] != expected {
+			t.Errorf("expected year total %+v, got %+v", expected, *supplier.ByYear[i])
+		}
+	}
+
+	// Заказчики упорядочены по убыванию суммы
+	if len(supplier.ByCounterparty) != 2 {
+		t.Fatalf("expected 2 customers, got %d", len(supplier.ByCounterparty))
+	}
+	top := supplier.ByCounterparty[0]
+	if *top.TaxNumber != "7707778246" || top.Count != 3 || top.Sum != 450 {
+		t.Errorf("expected ministry with 3 contracts for 450 first, got %+v", top)
+	}
+	if second := supplier.ByCounterparty[1]; *second.Name != "ГБУЗ ГКБ №1" || second.Count != 2 || second.Sum != 260 {
+		t.Errorf("expected hospital with 2 contracts for 260 second, got %+v", second)
+	}
+
+	customer := report.Summary.AsCustomer
+	if customer.Count != 1 || customer.Sum != 70 || len(customer.ByCounterparty) != 1 || *customer.ByCounterparty[0].TaxNumber != "7700000001" {
+		t.Errorf("expected one contract as customer grouped by supplier, got %+v", customer)
+	}
+}
@@ -0,0 +1,30 @@
+// Package canonicaljson приводит JSON к канонической форме. Её используют хэш кэша
+// данных и сопоставление запросов в кассетах, поэтому одинаковые по смыслу
+// документы совпадают в обоих местах.
+package canonicaljson
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Format приводит JSON к канонической форме: ключи объектов отсортированы,
+// лишние пробелы удалены, числа сохраняются без потери точности.
+// Строки, не являющиеся корректным JSON, возвращаются без изменений.
+func Format(data string) string {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(data)))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return data
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return data
+	}
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
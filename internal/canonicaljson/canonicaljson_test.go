@@ -0,0 +1,45 @@
+package canonicaljson
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{
+			name:     "sorted_keys",
+			data:     `{"b": 1, "a": {"d": [3, 2], "c": null}}`,
+			expected: `{"a":{"c":null,"d":[3,2]},"b":1}`,
+		},
+		{
+			name:     "large_numbers_preserved",
+			data:     `{"revenue": 12345678901234567890, "ratio": 0.10}`,
+			expected: `{"ratio":0.10,"revenue":12345678901234567890}`,
+		},
+		{
+			name:     "html_not_escaped",
+			data:     `{"name": "ООО \"Ромашка\" & Co <test>"}`,
+			expected: `{"name":"ООО \"Ромашка\" & Co <test>"}`,
+		},
+		{
+			name:     "invalid_json_unchanged",
+			data:     `{"test": `,
+			expected: `{"test": `,
+		},
+		{
+			name:     "multiple_values_unchanged",
+			data:     `{} {}`,
+			expected: `{} {}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Format(tt.data); got != tt.expected {
+				t.Errorf("expected '%s', but got '%s'", tt.expected, got)
+			}
+		})
+	}
+}
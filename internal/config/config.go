@@ -2,8 +2,11 @@ package config
 
 import (
 	"fmt"
+	"reflect"
+	"strconv"
 	"strings"
 
+	"github.com/go-viper/mapstructure/v2"
 	"github.com/spf13/viper"
 )
 
@@ -12,9 +15,72 @@ type Config struct {
 	NATS              NATSConfig       `mapstructure:"nats"`
 	Log               LogConfig        `mapstructure:"log"`
 	Credinform        CredinformConfig `mapstructure:"credinform"`
+	Worker            WorkerConfig     `mapstructure:"worker"`
+	CacheGC           CacheGCConfig    `mapstructure:"cache_gc"`
+	Data              DataConfig       `mapstructure:"data"`
 	WorkerConcurrency int              `mapstructure:"worker_concurrency" env-default:"5"`
 }
 
+// WorkerConfig содержит параметры жизненного цикла воркера. Интервалы задаются в секундах.
+type WorkerConfig struct {
+	// ID идентифицирует экземпляр воркера как владельца аренды проверок.
+	// Если не задан, формируется из имени хоста и PID.
+	ID                  string `mapstructure:"id"`
+	ShutdownGracePeriod int    `mapstructure:"shutdown_grace_period"`
+	LeaseTTL            int    `mapstructure:"lease_ttl"`
+	LeaseRenewInterval  int    `mapstructure:"lease_renew_interval"`
+	RecoveryInterval    int    `mapstructure:"recovery_interval"`
+	// MaxAttempts ограничивает число повторов проверки после временных ошибок
+	// Credinform; 0 — без ограничения. RetryDelay удваивается с каждой попыткой
+	// до RetryMaxDelay.
+	MaxAttempts   int `mapstructure:"max_attempts"`
+	RetryDelay    int `mapstructure:"retry_delay"`
+	RetryMaxDelay int `mapstructure:"retry_max_delay"`
+}
+
+// CacheGCConfig настраивает периодическую очистку неиспользуемых записей
+// verification_data_cache. Интервалы задаются в секундах.
+type CacheGCConfig struct {
+	Enabled   bool `mapstructure:"enabled"`
+	Interval  int  `mapstructure:"interval"`
+	OlderThan int  `mapstructure:"older_than"`
+}
+
+// DataConfig задаёт параметры запросов данных по умолчанию. Для отдельной проверки
+// их можно переопределить в сообщении verification.create.
+type DataConfig struct {
+	// ArbitrageLookback — глубина истории арбитражных дел: относительная ("36m", "2y", "90d"),
+	// отсчитываемая от момента обработки, или фиксированная дата ("2025-01-01")
+	ArbitrageLookback string `mapstructure:"arbitrage_lookback"`
+	// ArbitrageSides, AffiliationTypes и LicensedActivities задаются списком; в
+	// переменной окружения — через запятую
+	ArbitrageSides   []string `mapstructure:"arbitrage_sides"`
+	AffiliationTypes []string `mapstructure:"affiliation_types"`
+	// LicensedActivities — группы ОКВЭД, деятельность по которым требует лицензии или членства в СРО
+	LicensedActivities []string `mapstructure:"licensed_activities"`
+	// FinancialStatementsYears — за сколько последних отчётных лет запрашивается отчётность
+	FinancialStatementsYears int `mapstructure:"financial_statements_years"`
+}
+
+// DefaultDataConfig возвращает параметры запросов данных по умолчанию. Лицензируемые
+// группы ОКВЭД: фармацевтика, строительство и проектирование (СРО), банки,
+// страхование, охрана, образование и медицина.
+func DefaultDataConfig() DataConfig {
+	return DataConfig{
+		ArbitrageLookback: "2025-01-01",
+		ArbitrageSides:    []string{"Claimant", "Defendant", "ThirdPartiesAndOthers"},
+		AffiliationTypes: []string{
+			"ByManagementOrShareholdersNaturalPersons",
+			"ByLiquidatorOrBankruptcyAdministrator",
+			"UnderAdministrationOfTheCompany",
+			"ByManagingLegalPersons",
+			"ByShareholdersLegalPersons",
+		},
+		LicensedActivities:       []string{"21.20", "41.20", "42", "43", "47.73", "64.19", "65", "71.11", "71.12", "80.10", "85", "86"},
+		FinancialStatementsYears: 5,
+	}
+}
+
 type DatabaseConfig struct {
 	Host     string `mapstructure:"host"`
 	Port     int    `mapstructure:"port"`
@@ -22,10 +88,26 @@ type DatabaseConfig struct {
 	Password string `mapstructure:"password"`
 	DBName   string `mapstructure:"dbname"`
 	SSLMode  string `mapstructure:"sslmode"`
+	// AutoMigrate применяет встроенные миграции при запуске воркера
+	AutoMigrate bool `mapstructure:"auto_migrate"`
 }
 
 type NATSConfig struct {
-	URL string `mapstructure:"url"`
+	URL        string          `mapstructure:"url"`
+	QueueGroup string          `mapstructure:"queue_group"`
+	JetStream  JetStreamConfig `mapstructure:"jetstream"`
+}
+
+// JetStreamConfig настраивает durable consumer для verification.create.
+// Интервалы задаются в секундах.
+type JetStreamConfig struct {
+	Enabled     bool   `mapstructure:"enabled"`
+	Stream      string `mapstructure:"stream"`
+	Durable     string `mapstructure:"durable"`
+	AckWait     int    `mapstructure:"ack_wait"`
+	MaxDeliver  int    `mapstructure:"max_deliver"`
+	NakDelay    int    `mapstructure:"nak_delay"`
+	NakMaxDelay int    `mapstructure:"nak_max_delay"`
 }
 
 type LogConfig struct {
@@ -39,7 +121,79 @@ type CredinformConfig struct {
 	Password      string `mapstructure:"password"`
 	Timeout       int    `mapstructure:"timeout"`
 	RetryAttempts int    `mapstructure:"retry_attempts"`
-	RetryDelay    int    `mapstructure:"retry_delay"`
+	// RetryDelay — базовая задержка экспоненциального повтора в секундах,
+	// RetryMaxDelay ограничивает её сверху
+	RetryDelay    int `mapstructure:"retry_delay"`
+	RetryMaxDelay int `mapstructure:"retry_max_delay"`
+	// AccessKeyLifetime — срок действия ключа доступа в секундах; 0 отключает
+	// плановое обновление, ключ обновляется только после ответа 401
+	AccessKeyLifetime int `mapstructure:"access_key_lifetime"`
+	// AccessKeyRefreshBefore — за сколько секунд до истечения ключ обновляется заранее
+	AccessKeyRefreshBefore int                  `mapstructure:"access_key_refresh_before"`
+	RateLimit              RateLimitConfig      `mapstructure:"rate_limit"`
+	CircuitBreaker         CircuitBreakerConfig `mapstructure:"circuit_breaker"`
+	Pagination             PaginationConfig     `mapstructure:"pagination"`
+}
+
+// PaginationConfig задаёт постраничное получение списков Credinform. Списки длиннее
+// MaxPages страниц сохраняются не полностью, с признаком truncated.
+type PaginationConfig struct {
+	PageSize int `mapstructure:"page_size"`
+	MaxPages int `mapstructure:"max_pages"`
+	// MethodMaxPages заменяет MaxPages для перечисленных методов. Задаётся словарём
+	// метод → число страниц или, например в переменной окружения, строкой вида
+	// "CompanyInformation/StateContracts=30"
+	MethodMaxPages map[string]int `mapstructure:"methods"`
+}
+
+// CircuitBreakerConfig задаёт размыкание запросов к Credinform при массовых сбоях.
+// Breaker размыкается, когда доля временных ошибок в окне достигает ErrorRate,
+// и через OpenTimeout секунд пропускает один пробный запрос.
+type CircuitBreakerConfig struct {
+	Enabled     bool    `mapstructure:"enabled"`
+	ErrorRate   float64 `mapstructure:"error_rate"`
+	MinRequests int     `mapstructure:"min_requests"`
+	// Window — длительность окна подсчёта ошибок в секундах
+	Window      int `mapstructure:"window"`
+	OpenTimeout int `mapstructure:"open_timeout"`
+}
+
+// validate проверяет параметры включённого breaker: при нулевом окне или
+// min_requests он не накопил бы статистику и не разомкнулся бы никогда
+func (c CircuitBreakerConfig) validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.ErrorRate <= 0 || c.ErrorRate > 1 {
+		return fmt.Errorf("error_rate must be in (0, 1], got %v", c.ErrorRate)
+	}
+	if c.MinRequests <= 0 {
+		return fmt.Errorf("min_requests must be positive, got %d", c.MinRequests)
+	}
+	if c.Window <= 0 {
+		return fmt.Errorf("window must be positive, got %d", c.Window)
+	}
+	return nil
+}
+
+// RateLimitConfig ограничивает частоту запросов к Credinform. Общий лимит
+// действует на все методы, Methods задаёт отдельные бюджеты для методов API.
+// RequestsPerSecond <= 0 снимает ограничение.
+type RateLimitConfig struct {
+	Enabled           bool    `mapstructure:"enabled"`
+	RequestsPerSecond float64 `mapstructure:"requests_per_second"`
+	Burst             int     `mapstructure:"burst"`
+	// Methods задаётся словарём метод → лимит или, например в переменной окружения,
+	// строкой вида "Search/SearchCompany=2:5,CompanyInformation/BasicInformation=5",
+	// где после имени метода указываются запросы в секунду и, через двоеточие, burst
+	Methods map[string]MethodRateLimit `mapstructure:"methods"`
+	// Distributed хранит бакеты в PostgreSQL, чтобы лимит соблюдался всеми репликами вместе
+	Distributed bool `mapstructure:"distributed"`
+}
+
+type MethodRateLimit struct {
+	RequestsPerSecond float64 `mapstructure:"requests_per_second"`
+	Burst             int     `mapstructure:"burst"`
 }
 
 func Load() (*Config, error) {
@@ -53,7 +207,16 @@ func Load() (*Config, error) {
 	viper.SetDefault("database.password", "postgres")
 	viper.SetDefault("database.dbname", "scoring")
 	viper.SetDefault("database.sslmode", "disable")
+	viper.SetDefault("database.auto_migrate", false)
 	viper.SetDefault("nats.url", "nats://localhost:4222")
+	viper.SetDefault("nats.queue_group", "scoring_worker")
+	viper.SetDefault("nats.jetstream.enabled", false)
+	viper.SetDefault("nats.jetstream.stream", "VERIFICATIONS")
+	viper.SetDefault("nats.jetstream.durable", "scoring_worker")
+	viper.SetDefault("nats.jetstream.ack_wait", 30)
+	viper.SetDefault("nats.jetstream.max_deliver", 5)
+	viper.SetDefault("nats.jetstream.nak_delay", 1)
+	viper.SetDefault("nats.jetstream.nak_max_delay", 60)
 	viper.SetDefault("log.level", "info")
 	viper.SetDefault("log.json", false)
 	viper.SetDefault("credinform.base_url", "https://restapi.credinform.ru")
@@ -62,16 +225,149 @@ func Load() (*Config, error) {
 	viper.SetDefault("credinform.timeout", 30)
 	viper.SetDefault("credinform.retry_attempts", 3)
 	viper.SetDefault("credinform.retry_delay", 1)
+	viper.SetDefault("credinform.retry_max_delay", 30)
+	viper.SetDefault("credinform.access_key_lifetime", 3600)
+	viper.SetDefault("credinform.access_key_refresh_before", 60)
+	viper.SetDefault("credinform.rate_limit.enabled", false)
+	viper.SetDefault("credinform.rate_limit.requests_per_second", 10)
+	viper.SetDefault("credinform.rate_limit.burst", 10)
+	viper.SetDefault("credinform.rate_limit.distributed", false)
+	viper.SetDefault("credinform.rate_limit.methods", "")
+	viper.SetDefault("credinform.circuit_breaker.enabled", false)
+	viper.SetDefault("credinform.circuit_breaker.error_rate", 0.5)
+	viper.SetDefault("credinform.circuit_breaker.min_requests", 10)
+	viper.SetDefault("credinform.circuit_breaker.window", 60)
+	viper.SetDefault("credinform.circuit_breaker.open_timeout", 30)
+	viper.SetDefault("credinform.pagination.page_size", 100)
+	viper.SetDefault("credinform.pagination.max_pages", 10)
+	viper.SetDefault("credinform.pagination.methods", "")
 	viper.SetDefault("worker_concurrency", 5)
+	viper.SetDefault("worker.id", "")
+	viper.SetDefault("worker.shutdown_grace_period", 30)
+	viper.SetDefault("worker.lease_ttl", 60)
+	viper.SetDefault("worker.lease_renew_interval", 20)
+	viper.SetDefault("worker.recovery_interval", 30)
+	viper.SetDefault("worker.max_attempts", 5)
+	viper.SetDefault("worker.retry_delay", 30)
+	viper.SetDefault("worker.retry_max_delay", 600)
+	viper.SetDefault("cache_gc.enabled", false)
+	viper.SetDefault("cache_gc.interval", 3600)
+	viper.SetDefault("cache_gc.older_than", 86400)
+	dataDefaults := DefaultDataConfig()
+	viper.SetDefault("data.arbitrage_lookback", dataDefaults.ArbitrageLookback)
+	viper.SetDefault("data.arbitrage_sides", dataDefaults.ArbitrageSides)
+	viper.SetDefault("data.affiliation_types", dataDefaults.AffiliationTypes)
+	viper.SetDefault("data.licensed_activities", dataDefaults.LicensedActivities)
+	viper.SetDefault("data.financial_statements_years", dataDefaults.FinancialStatementsYears)
 
 	var config Config
-	if err := viper.Unmarshal(&config); err != nil {
+	if err := viper.Unmarshal(&config, decodeHook()); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
+	if err := config.Credinform.CircuitBreaker.validate(); err != nil {
+		return nil, fmt.Errorf("invalid circuit breaker config: %w", err)
+	}
 
 	return &config, nil
 }
 
+// decodeHook дополняет стандартные преобразования viper разбором строковой
+// записи словарей и списков, которые в переменных окружения задаются одной строкой
+func decodeHook() viper.DecoderConfigOption {
+	return viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		stringToMethodRateLimitsHook,
+		stringToMethodMaxPagesHook,
+		mapstructure.StringToTimeDurationHookFunc(),
+		stringToListHook,
+	))
+}
+
+// stringToListHook разбирает список через запятую, пропуская пустые значения
+func stringToListHook(from, to reflect.Type, data interface{}) (interface{}, error) {
+	if from.Kind() != reflect.String || to != reflect.TypeOf([]string{}) {
+		return data, nil
+	}
+	return splitList(data.(string)), nil
+}
+
+// stringToMethodRateLimitsHook разбирает лимиты методов, заданные строкой
+func stringToMethodRateLimitsHook(from, to reflect.Type, data interface{}) (interface{}, error) {
+	if from.Kind() != reflect.String || to != reflect.TypeOf(map[string]MethodRateLimit{}) {
+		return data, nil
+	}
+	return parseMethodRateLimits(data.(string))
+}
+
+// parseMethodRateLimits разбирает лимиты методов в формате "Method=rps[:burst],..."
+func parseMethodRateLimits(value string) (map[string]MethodRateLimit, error) {
+	result := make(map[string]MethodRateLimit)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		method, limit, ok := strings.Cut(item, "=")
+		if !ok || method == "" {
+			return nil, fmt.Errorf("invalid rate limit %q, expected <method>=<rps>[:<burst>]", item)
+		}
+
+		rpsValue, burstValue, hasBurst := strings.Cut(limit, ":")
+		rps, err := strconv.ParseFloat(rpsValue, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid requests per second in rate limit %q: %w", item, err)
+		}
+		burst := 1
+		if hasBurst {
+			burst, err = strconv.Atoi(burstValue)
+			if err != nil {
+				return nil, fmt.Errorf("invalid burst in rate limit %q: %w", item, err)
+			}
+		}
+		result[strings.TrimSpace(method)] = MethodRateLimit{RequestsPerSecond: rps, Burst: burst}
+	}
+	return result, nil
+}
+
+// stringToMethodMaxPagesHook разбирает пределы страниц методов, заданные строкой
+func stringToMethodMaxPagesHook(from, to reflect.Type, data interface{}) (interface{}, error) {
+	if from.Kind() != reflect.String || to != reflect.TypeOf(map[string]int{}) {
+		return data, nil
+	}
+	return parseMethodMaxPages(data.(string))
+}
+
+// parseMethodMaxPages разбирает пределы страниц методов в формате "Method=pages,..."
+func parseMethodMaxPages(value string) (map[string]int, error) {
+	result := make(map[string]int)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		method, pagesValue, ok := strings.Cut(item, "=")
+		if !ok || method == "" {
+			return nil, fmt.Errorf("invalid page limit %q, expected <method>=<pages>", item)
+		}
+		pages, err := strconv.Atoi(pagesValue)
+		if err != nil || pages <= 0 {
+			return nil, fmt.Errorf("invalid page limit %q, expected positive number of pages", item)
+		}
+		result[strings.TrimSpace(method)] = pages
+	}
+	return result, nil
+}
+
+// splitList разбирает список значений через запятую, пропуская пустые
+func splitList(value string) []string {
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
 func (c *Config) DatabaseDSN() string {
 	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
 		c.Database.Host, c.Database.Port, c.Database.User, c.Database.Password, c.Database.DBName, c.Database.SSLMode)
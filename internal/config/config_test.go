@@ -2,7 +2,11 @@ package config
 
 import (
 	"os"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/spf13/viper"
 )
 
 func TestLoad(t *testing.T) {
@@ -10,9 +14,10 @@ func TestLoad(t *testing.T) {
 	originalEnvVars := make(map[string]string)
 	envVarsToTest := []string{
 		"DATABASE_HOST", "DATABASE_PORT", "DATABASE_USER", "DATABASE_PASSWORD",
-		"DATABASE_DBNAME", "DATABASE_SSLMODE", "NATS_URL", "LOG_LEVEL", "LOG_JSON",
+		"DATABASE_DBNAME", "DATABASE_SSLMODE", "DATABASE_AUTO_MIGRATE", "NATS_URL", "NATS_QUEUE_GROUP", "LOG_LEVEL", "LOG_JSON",
 		"CREDINFORM_BASE_URL", "CREDINFORM_USERNAME", "CREDINFORM_PASSWORD",
 		"CREDINFORM_TIMEOUT", "CREDINFORM_RETRY_ATTEMPTS", "CREDINFORM_RETRY_DELAY",
+		"CREDINFORM_RETRY_MAX_DELAY", "CREDINFORM_ACCESS_KEY_LIFETIME", "CREDINFORM_ACCESS_KEY_REFRESH_BEFORE",
 		"WORKER_CONCURRENCY",
 	}
 
@@ -50,19 +55,23 @@ func TestLoad(t *testing.T) {
 					SSLMode:  "disable",
 				},
 				NATS: NATSConfig{
-					URL: "nats://localhost:4222",
+					URL:        "nats://localhost:4222",
+					QueueGroup: "scoring_worker",
 				},
 				Log: LogConfig{
 					Level: "info",
 					JSON:  false,
 				},
 				Credinform: CredinformConfig{
-					BaseURL:       "https://restapi.credinform.ru",
-					Username:      "",
-					Password:      "",
-					Timeout:       30,
-					RetryAttempts: 3,
-					RetryDelay:    1,
+					BaseURL:                "https://restapi.credinform.ru",
+					Username:               "",
+					Password:               "",
+					Timeout:                30,
+					RetryAttempts:          3,
+					RetryDelay:             1,
+					RetryMaxDelay:          30,
+					AccessKeyLifetime:      3600,
+					AccessKeyRefreshBefore: 60,
 				},
 				WorkerConcurrency: 5,
 			},
@@ -71,36 +80,42 @@ func TestLoad(t *testing.T) {
 		{
 			name: "custom_database_config",
 			envVars: map[string]string{
-				"DATABASE_HOST":     "db.example.com",
-				"DATABASE_PORT":     "5433",
-				"DATABASE_USER":     "testuser",
-				"DATABASE_PASSWORD": "testpass",
-				"DATABASE_DBNAME":   "testdb",
-				"DATABASE_SSLMODE":  "require",
+				"DATABASE_HOST":         "db.example.com",
+				"DATABASE_PORT":         "5433",
+				"DATABASE_USER":         "testuser",
+				"DATABASE_PASSWORD":     "testpass",
+				"DATABASE_DBNAME":       "testdb",
+				"DATABASE_SSLMODE":      "require",
+				"DATABASE_AUTO_MIGRATE": "true",
 			},
 			expectedConfig: &Config{
 				Database: DatabaseConfig{
-					Host:     "db.example.com",
-					Port:     5433,
-					User:     "testuser",
-					Password: "testpass",
-					DBName:   "testdb",
-					SSLMode:  "require",
+					Host:        "db.example.com",
+					Port:        5433,
+					User:        "testuser",
+					Password:    "testpass",
+					DBName:      "testdb",
+					SSLMode:     "require",
+					AutoMigrate: true,
 				},
 				NATS: NATSConfig{
-					URL: "nats://localhost:4222",
+					URL:        "nats://localhost:4222",
+					QueueGroup: "scoring_worker",
 				},
 				Log: LogConfig{
 					Level: "info",
 					JSON:  false,
 				},
 				Credinform: CredinformConfig{
-					BaseURL:       "https://restapi.credinform.ru",
-					Username:      "",
-					Password:      "",
-					Timeout:       30,
-					RetryAttempts: 3,
-					RetryDelay:    1,
+					BaseURL:                "https://restapi.credinform.ru",
+					Username:               "",
+					Password:               "",
+					Timeout:                30,
+					RetryAttempts:          3,
+					RetryDelay:             1,
+					RetryMaxDelay:          30,
+					AccessKeyLifetime:      3600,
+					AccessKeyRefreshBefore: 60,
 				},
 				WorkerConcurrency: 5,
 			},
@@ -109,12 +124,15 @@ func TestLoad(t *testing.T) {
 		{
 			name: "custom_credinform_config",
 			envVars: map[string]string{
-				"CREDINFORM_BASE_URL":       "https://api.credinform.com",
-				"CREDINFORM_USERNAME":       "testuser",
-				"CREDINFORM_PASSWORD":       "testpass",
-				"CREDINFORM_TIMEOUT":        "60",
-				"CREDINFORM_RETRY_ATTEMPTS": "5",
-				"CREDINFORM_RETRY_DELAY":    "2",
+				"CREDINFORM_BASE_URL":                  "https://api.credinform.com",
+				"CREDINFORM_USERNAME":                  "testuser",
+				"CREDINFORM_PASSWORD":                  "testpass",
+				"CREDINFORM_TIMEOUT":                   "60",
+				"CREDINFORM_RETRY_ATTEMPTS":            "5",
+				"CREDINFORM_RETRY_DELAY":               "2",
+				"CREDINFORM_RETRY_MAX_DELAY":           "10",
+				"CREDINFORM_ACCESS_KEY_LIFETIME":       "1800",
+				"CREDINFORM_ACCESS_KEY_REFRESH_BEFORE": "120",
 			},
 			expectedConfig: &Config{
 				Database: DatabaseConfig{
@@ -126,19 +144,23 @@ func TestLoad(t *testing.T) {
 					SSLMode:  "disable",
 				},
 				NATS: NATSConfig{
-					URL: "nats://localhost:4222",
+					URL:        "nats://localhost:4222",
+					QueueGroup: "scoring_worker",
 				},
 				Log: LogConfig{
 					Level: "info",
 					JSON:  false,
 				},
 				Credinform: CredinformConfig{
-					BaseURL:       "https://api.credinform.com",
-					Username:      "testuser",
-					Password:      "testpass",
-					Timeout:       60,
-					RetryAttempts: 5,
-					RetryDelay:    2,
+					BaseURL:                "https://api.credinform.com",
+					Username:               "testuser",
+					Password:               "testpass",
+					Timeout:                60,
+					RetryAttempts:          5,
+					RetryDelay:             2,
+					RetryMaxDelay:          10,
+					AccessKeyLifetime:      1800,
+					AccessKeyRefreshBefore: 120,
 				},
 				WorkerConcurrency: 5,
 			},
@@ -159,24 +181,66 @@ func TestLoad(t *testing.T) {
 					SSLMode:  "disable",
 				},
 				NATS: NATSConfig{
-					URL: "nats://localhost:4222",
+					URL:        "nats://localhost:4222",
+					QueueGroup: "scoring_worker",
 				},
 				Log: LogConfig{
 					Level: "info",
 					JSON:  false,
 				},
 				Credinform: CredinformConfig{
-					BaseURL:       "https://restapi.credinform.ru",
-					Username:      "",
-					Password:      "",
-					Timeout:       30,
-					RetryAttempts: 3,
-					RetryDelay:    1,
+					BaseURL:                "https://restapi.credinform.ru",
+					Username:               "",
+					Password:               "",
+					Timeout:                30,
+					RetryAttempts:          3,
+					RetryDelay:             1,
+					RetryMaxDelay:          30,
+					AccessKeyLifetime:      3600,
+					AccessKeyRefreshBefore: 60,
 				},
 				WorkerConcurrency: 10,
 			},
 			expectedError: false,
 		},
+		{
+			name: "custom_nats_config",
+			envVars: map[string]string{
+				"NATS_URL":         "nats://nats.example.com:4222",
+				"NATS_QUEUE_GROUP": "scoring_worker_eu",
+			},
+			expectedConfig: &Config{
+				Database: DatabaseConfig{
+					Host:     "localhost",
+					Port:     5432,
+					User:     "postgres",
+					Password: "postgres",
+					DBName:   "scoring",
+					SSLMode:  "disable",
+				},
+				NATS: NATSConfig{
+					URL:        "nats://nats.example.com:4222",
+					QueueGroup: "scoring_worker_eu",
+				},
+				Log: LogConfig{
+					Level: "info",
+					JSON:  false,
+				},
+				Credinform: CredinformConfig{
+					BaseURL:                "https://restapi.credinform.ru",
+					Username:               "",
+					Password:               "",
+					Timeout:                30,
+					RetryAttempts:          3,
+					RetryDelay:             1,
+					RetryMaxDelay:          30,
+					AccessKeyLifetime:      3600,
+					AccessKeyRefreshBefore: 60,
+				},
+				WorkerConcurrency: 5,
+			},
+			expectedError: false,
+		},
 		{
 			name: "custom_log_config",
 			envVars: map[string]string{
@@ -193,19 +257,23 @@ func TestLoad(t *testing.T) {
 					SSLMode:  "disable",
 				},
 				NATS: NATSConfig{
-					URL: "nats://localhost:4222",
+					URL:        "nats://localhost:4222",
+					QueueGroup: "scoring_worker",
 				},
 				Log: LogConfig{
 					Level: "debug",
 					JSON:  true,
 				},
 				Credinform: CredinformConfig{
-					BaseURL:       "https://restapi.credinform.ru",
-					Username:      "",
-					Password:      "",
-					Timeout:       30,
-					RetryAttempts: 3,
-					RetryDelay:    1,
+					BaseURL:                "https://restapi.credinform.ru",
+					Username:               "",
+					Password:               "",
+					Timeout:                30,
+					RetryAttempts:          3,
+					RetryDelay:             1,
+					RetryMaxDelay:          30,
+					AccessKeyLifetime:      3600,
+					AccessKeyRefreshBefore: 60,
 				},
 				WorkerConcurrency: 5,
 			},
@@ -263,11 +331,17 @@ func TestLoad(t *testing.T) {
 			if config.Database.SSLMode != tt.expectedConfig.Database.SSLMode {
 				t.Errorf("expected database ssl mode '%s', but got '%s'", tt.expectedConfig.Database.SSLMode, config.Database.SSLMode)
 			}
+			if config.Database.AutoMigrate != tt.expectedConfig.Database.AutoMigrate {
+				t.Errorf("expected database auto migrate %t, but got %t", tt.expectedConfig.Database.AutoMigrate, config.Database.AutoMigrate)
+			}
 
 			// Проверяем NATS конфигурацию
 			if config.NATS.URL != tt.expectedConfig.NATS.URL {
 				t.Errorf("expected NATS URL '%s', but got '%s'", tt.expectedConfig.NATS.URL, config.NATS.URL)
 			}
+			if config.NATS.QueueGroup != tt.expectedConfig.NATS.QueueGroup {
+				t.Errorf("expected NATS queue group '%s', but got '%s'", tt.expectedConfig.NATS.QueueGroup, config.NATS.QueueGroup)
+			}
 
 			// Проверяем Log конфигурацию
 			if config.Log.Level != tt.expectedConfig.Log.Level {
@@ -296,6 +370,15 @@ func TestLoad(t *testing.T) {
 			if config.Credinform.RetryDelay != tt.expectedConfig.Credinform.RetryDelay {
 				t.Errorf("expected credinform retry delay %d, but got %d", tt.expectedConfig.Credinform.RetryDelay, config.Credinform.RetryDelay)
 			}
+			if config.Credinform.RetryMaxDelay != tt.expectedConfig.Credinform.RetryMaxDelay {
+				t.Errorf("expected credinform retry max delay %d, but got %d", tt.expectedConfig.Credinform.RetryMaxDelay, config.Credinform.RetryMaxDelay)
+			}
+			if config.Credinform.AccessKeyLifetime != tt.expectedConfig.Credinform.AccessKeyLifetime {
+				t.Errorf("expected credinform access key lifetime %d, but got %d", tt.expectedConfig.Credinform.AccessKeyLifetime, config.Credinform.AccessKeyLifetime)
+			}
+			if config.Credinform.AccessKeyRefreshBefore != tt.expectedConfig.Credinform.AccessKeyRefreshBefore {
+				t.Errorf("expected credinform access key refresh before %d, but got %d", tt.expectedConfig.Credinform.AccessKeyRefreshBefore, config.Credinform.AccessKeyRefreshBefore)
+			}
 
 			// Проверяем Worker конфигурацию
 			if config.WorkerConcurrency != tt.expectedConfig.WorkerConcurrency {
@@ -582,3 +665,683 @@ func TestWorkerConcurrencyValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestNATSJetStreamConfig(t *testing.T) {
+	envVars := []string{
+		"NATS_JETSTREAM_ENABLED", "NATS_JETSTREAM_STREAM", "NATS_JETSTREAM_DURABLE",
+		"NATS_JETSTREAM_ACK_WAIT", "NATS_JETSTREAM_MAX_DELIVER",
+		"NATS_JETSTREAM_NAK_DELAY", "NATS_JETSTREAM_NAK_MAX_DELAY",
+	}
+	for _, envVar := range envVars {
+		original := os.Getenv(envVar)
+		defer func(key, value string) {
+			if value == "" {
+				os.Unsetenv(key)
+			} else {
+				os.Setenv(key, value)
+			}
+		}(envVar, original)
+		os.Unsetenv(envVar)
+	}
+
+	tests := []struct {
+		name     string
+		envVars  map[string]string
+		expected JetStreamConfig
+	}{
+		{
+			name:    "default_values",
+			envVars: map[string]string{},
+			expected: JetStreamConfig{
+				Enabled:     false,
+				Stream:      "VERIFICATIONS",
+				Durable:     "scoring_worker",
+				AckWait:     30,
+				MaxDeliver:  5,
+				NakDelay:    1,
+				NakMaxDelay: 60,
+			},
+		},
+		{
+			name: "custom_values",
+			envVars: map[string]string{
+				"NATS_JETSTREAM_ENABLED":     "true",
+				"NATS_JETSTREAM_STREAM":      "SCORING",
+				"NATS_JETSTREAM_DURABLE":     "worker",
+				"NATS_JETSTREAM_MAX_DELIVER": "10",
+			},
+			expected: JetStreamConfig{
+				Enabled:     true,
+				Stream:      "SCORING",
+				Durable:     "worker",
+				AckWait:     30,
+				MaxDeliver:  10,
+				NakDelay:    1,
+				NakMaxDelay: 60,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, envVar := range envVars {
+				os.Unsetenv(envVar)
+			}
+			for key, value := range tt.envVars {
+				os.Setenv(key, value)
+			}
+
+			config, err := Load()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if config.NATS.JetStream != tt.expected {
+				t.Errorf("expected jetstream config %+v, but got %+v", tt.expected, config.NATS.JetStream)
+			}
+		})
+	}
+}
+
+func TestWorkerConfig(t *testing.T) {
+	envVars := []string{
+		"WORKER_ID", "WORKER_SHUTDOWN_GRACE_PERIOD", "WORKER_LEASE_TTL",
+		"WORKER_LEASE_RENEW_INTERVAL", "WORKER_RECOVERY_INTERVAL",
+	}
+	for _, envVar := range envVars {
+		original := os.Getenv(envVar)
+		defer func(key, value string) {
+			if value == "" {
+				os.Unsetenv(key)
+			} else {
+				os.Setenv(key, value)
+			}
+		}(envVar, original)
+	}
+
+	tests := []struct {
+		name     string
+		envVars  map[string]string
+		expected WorkerConfig
+	}{
+		{
+			name:    "default_values",
+			envVars: map[string]string{},
+			expected: WorkerConfig{
+				ID:                  "",
+				ShutdownGracePeriod: 30,
+				LeaseTTL:            60,
+				LeaseRenewInterval:  20,
+				RecoveryInterval:    30,
+				MaxAttempts:         5,
+				RetryDelay:          30,
+				RetryMaxDelay:       600,
+			},
+		},
+		{
+			name: "custom_values",
+			envVars: map[string]string{
+				"WORKER_ID":                    "worker-1",
+				"WORKER_SHUTDOWN_GRACE_PERIOD": "120",
+				"WORKER_LEASE_TTL":             "90",
+				"WORKER_LEASE_RENEW_INTERVAL":  "30",
+				"WORKER_RECOVERY_INTERVAL":     "10",
+				"WORKER_MAX_ATTEMPTS":          "3",
+				"WORKER_RETRY_DELAY":           "5",
+				"WORKER_RETRY_MAX_DELAY":       "60",
+			},
+			expected: WorkerConfig{
+				ID:                  "worker-1",
+				ShutdownGracePeriod: 120,
+				LeaseTTL:            90,
+				LeaseRenewInterval:  30,
+				RecoveryInterval:    10,
+				MaxAttempts:         3,
+				RetryDelay:          5,
+				RetryMaxDelay:       60,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, envVar := range envVars {
+				os.Unsetenv(envVar)
+			}
+			for key, value := range tt.envVars {
+				os.Setenv(key, value)
+			}
+
+			config, err := Load()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if config.Worker != tt.expected {
+				t.Errorf("expected worker config %+v, but got %+v", tt.expected, config.Worker)
+			}
+		})
+	}
+}
+
+func TestCacheGCConfig(t *testing.T) {
+	envVars := []string{"CACHE_GC_ENABLED", "CACHE_GC_INTERVAL", "CACHE_GC_OLDER_THAN"}
+	for _, envVar := range envVars {
+		original := os.Getenv(envVar)
+		defer func(key, value string) {
+			if value == "" {
+				os.Unsetenv(key)
+			} else {
+				os.Setenv(key, value)
+			}
+		}(envVar, original)
+	}
+
+	tests := []struct {
+		name     string
+		envVars  map[string]string
+		expected CacheGCConfig
+	}{
+		{
+			name:    "default_values",
+			envVars: map[string]string{},
+			expected: CacheGCConfig{
+				Enabled:   false,
+				Interval:  3600,
+				OlderThan: 86400,
+			},
+		},
+		{
+			name: "custom_values",
+			envVars: map[string]string{
+				"CACHE_GC_ENABLED":    "true",
+				"CACHE_GC_INTERVAL":   "600",
+				"CACHE_GC_OLDER_THAN": "3600",
+			},
+			expected: CacheGCConfig{
+				Enabled:   true,
+				Interval:  600,
+				OlderThan: 3600,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, envVar := range envVars {
+				os.Unsetenv(envVar)
+			}
+			for key, value := range tt.envVars {
+				os.Setenv(key, value)
+			}
+
+			config, err := Load()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if config.CacheGC != tt.expected {
+				t.Errorf("expected cache gc config %+v, but got %+v", tt.expected, config.CacheGC)
+			}
+		})
+	}
+}
+
+func TestCredinformRateLimitConfig(t *testing.T) {
+	envVars := []string{
+		"CREDINFORM_RATE_LIMIT_ENABLED", "CREDINFORM_RATE_LIMIT_REQUESTS_PER_SECOND",
+		"CREDINFORM_RATE_LIMIT_BURST", "CREDINFORM_RATE_LIMIT_DISTRIBUTED", "CREDINFORM_RATE_LIMIT_METHODS",
+	}
+	for _, envVar := range envVars {
+		original := os.Getenv(envVar)
+		defer func(key, value string) {
+			if value == "" {
+				os.Unsetenv(key)
+			} else {
+				os.Setenv(key, value)
+			}
+		}(envVar, original)
+	}
+
+	tests := []struct {
+		name          string
+		envVars       map[string]string
+		expected      RateLimitConfig
+		expectedError bool
+	}{
+		{
+			name:    "default_values",
+			envVars: map[string]string{},
+			expected: RateLimitConfig{
+				RequestsPerSecond: 10,
+				Burst:             10,
+				Methods:           map[string]MethodRateLimit{},
+			},
+		},
+		{
+			name: "custom_values",
+			envVars: map[string]string{
+				"CREDINFORM_RATE_LIMIT_ENABLED":             "true",
+				"CREDINFORM_RATE_LIMIT_REQUESTS_PER_SECOND": "2.5",
+				"CREDINFORM_RATE_LIMIT_BURST":               "5",
+				"CREDINFORM_RATE_LIMIT_DISTRIBUTED":         "true",
+				"CREDINFORM_RATE_LIMIT_METHODS":             "Search/SearchCompany=1:2, CompanyInformation/BasicInformation=0.5",
+			},
+			expected: RateLimitConfig{
+				Enabled:           true,
+				RequestsPerSecond: 2.5,
+				Burst:             5,
+				Distributed:       true,
+				Methods: map[string]MethodRateLimit{
+					"Search/SearchCompany":                {RequestsPerSecond: 1, Burst: 2},
+					"CompanyInformation/BasicInformation": {RequestsPerSecond: 0.5, Burst: 1},
+				},
+			},
+		},
+		{
+			name:          "invalid_methods",
+			envVars:       map[string]string{"CREDINFORM_RATE_LIMIT_METHODS": "Search/SearchCompany"},
+			expectedError: true,
+		},
+		{
+			name:          "invalid_method_burst",
+			envVars:       map[string]string{"CREDINFORM_RATE_LIMIT_METHODS": "Search/SearchCompany=1:many"},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, envVar := range envVars {
+				os.Unsetenv(envVar)
+			}
+			for key, value := range tt.envVars {
+				os.Setenv(key, value)
+			}
+
+			config, err := Load()
+			if tt.expectedError {
+				if err == nil {
+					t.Error("expected error, but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(config.Credinform.RateLimit, tt.expected) {
+				t.Errorf("expected rate limit config %+v, but got %+v", tt.expected, config.Credinform.RateLimit)
+			}
+		})
+	}
+}
+
+func TestDecodeHook(t *testing.T) {
+	tests := []struct {
+		name          string
+		yaml          string
+		expected      Config
+		expectedError bool
+	}{
+		{
+			// viper приводит ключи словарей из файла к нижнему регистру
+			name: "rate_limit_methods_map",
+			yaml: `
+credinform:
+  rate_limit:
+    methods:
+      Search/SearchCompany:
+        requests_per_second: 2
+        burst: 5
+      CompanyInformation/BasicInformation:
+        requests_per_second: 0.5
+`,
+			expected: Config{Credinform: CredinformConfig{RateLimit: RateLimitConfig{
+				Methods: map[string]MethodRateLimit{
+					"search/searchcompany":                {RequestsPerSecond: 2, Burst: 5},
+					"companyinformation/basicinformation": {RequestsPerSecond: 0.5},
+				},
+			}}},
+		},
+		{
+			name: "rate_limit_methods_string",
+			yaml: `
+credinform:
+  rate_limit:
+    methods: "Search/SearchCompany=2:5"
+`,
+			expected: Config{Credinform: CredinformConfig{RateLimit: RateLimitConfig{
+				Methods: map[string]MethodRateLimit{"Search/SearchCompany": {RequestsPerSecond: 2, Burst: 5}},
+			}}},
+		},
+		{
+			name: "pagination_methods_map",
+			yaml: `
+credinform:
+  pagination:
+    methods:
+      CompanyInformation/StateContracts: 30
+`,
+			expected: Config{Credinform: CredinformConfig{Pagination: PaginationConfig{
+				MethodMaxPages: map[string]int{"companyinformation/statecontracts": 30},
+			}}},
+		},
+		{
+			name: "data_lists",
+			yaml: `
+data:
+  arbitrage_sides: [Claimant, Defendant]
+  licensed_activities: "86, 47.73"
+`,
+			expected: Config{Data: DataConfig{
+				ArbitrageSides:     []string{"Claimant", "Defendant"},
+				LicensedActivities: []string{"86", "47.73"},
+			}},
+		},
+		{
+			name: "invalid_rate_limit_methods_string",
+			yaml: `
+credinform:
+  rate_limit:
+    methods: "Search/SearchCompany"
+`,
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := viper.New()
+			v.SetConfigType("yaml")
+			if err := v.ReadConfig(strings.NewReader(tt.yaml)); err != nil {
+				t.Fatalf("failed to read config: %v", err)
+			}
+
+			var config Config
+			err := v.Unmarshal(&config, decodeHook())
+			if tt.expectedError {
+				if err == nil {
+					t.Error("expected error, but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(config, tt.expected) {
+				t.Errorf("expected config %+v, but got %+v", tt.expected, config)
+			}
+		})
+	}
+}
+
+func TestCredinformCircuitBreakerConfig(t *testing.T) {
+	envVars := []string{
+		"CREDINFORM_CIRCUIT_BREAKER_ENABLED", "CREDINFORM_CIRCUIT_BREAKER_ERROR_RATE",
+		"CREDINFORM_CIRCUIT_BREAKER_MIN_REQUESTS", "CREDINFORM_CIRCUIT_BREAKER_WINDOW",
+		"CREDINFORM_CIRCUIT_BREAKER_OPEN_TIMEOUT",
+	}
+	for _, envVar := range envVars {
+		original := os.Getenv(envVar)
+		defer func(key, value string) {
+			if value == "" {
+				os.Unsetenv(key)
+			} else {
+				os.Setenv(key, value)
+			}
+		}(envVar, original)
+	}
+
+	tests := []struct {
+		name          string
+		envVars       map[string]string
+		expected      CircuitBreakerConfig
+		expectedError bool
+	}{
+		{
+			name:    "default_values",
+			envVars: map[string]string{},
+			expected: CircuitBreakerConfig{
+				Enabled:     false,
+				ErrorRate:   0.5,
+				MinRequests: 10,
+				Window:      60,
+				OpenTimeout: 30,
+			},
+		},
+		{
+			name: "custom_values",
+			envVars: map[string]string{
+				"CREDINFORM_CIRCUIT_BREAKER_ENABLED":      "true",
+				"CREDINFORM_CIRCUIT_BREAKER_ERROR_RATE":   "0.25",
+				"CREDINFORM_CIRCUIT_BREAKER_MIN_REQUESTS": "20",
+				"CREDINFORM_CIRCUIT_BREAKER_WINDOW":       "120",
+				"CREDINFORM_CIRCUIT_BREAKER_OPEN_TIMEOUT": "10",
+			},
+			expected: CircuitBreakerConfig{
+				Enabled:     true,
+				ErrorRate:   0.25,
+				MinRequests: 20,
+				Window:      120,
+				OpenTimeout: 10,
+			},
+		},
+		{
+			// Выключенный breaker не проверяется
+			name: "disabled_with_zero_window",
+			envVars: map[string]string{
+				"CREDINFORM_CIRCUIT_BREAKER_WINDOW": "0",
+			},
+			expected: CircuitBreakerConfig{
+				ErrorRate:   0.5,
+				MinRequests: 10,
+				OpenTimeout: 30,
+			},
+		},
+		{
+			name: "zero_window",
+			envVars: map[string]string{
+				"CREDINFORM_CIRCUIT_BREAKER_ENABLED": "true",
+				"CREDINFORM_CIRCUIT_BREAKER_WINDOW":  "0",
+			},
+			expectedError: true,
+		},
+		{
+			name: "zero_min_requests",
+			envVars: map[string]string{
+				"CREDINFORM_CIRCUIT_BREAKER_ENABLED":      "true",
+				"CREDINFORM_CIRCUIT_BREAKER_MIN_REQUESTS": "0",
+			},
+			expectedError: true,
+		},
+		{
+			name: "zero_error_rate",
+			envVars: map[string]string{
+				"CREDINFORM_CIRCUIT_BREAKER_ENABLED":    "true",
+				"CREDINFORM_CIRCUIT_BREAKER_ERROR_RATE": "0",
+			},
+			expectedError: true,
+		},
+		{
+			name: "error_rate_above_one",
+			envVars: map[string]string{
+				"CREDINFORM_CIRCUIT_BREAKER_ENABLED":    "true",
+				"CREDINFORM_CIRCUIT_BREAKER_ERROR_RATE": "1.5",
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, envVar := range envVars {
+				os.Unsetenv(envVar)
+			}
+			for key, value := range tt.envVars {
+				os.Setenv(key, value)
+			}
+
+			config, err := Load()
+			if tt.expectedError {
+				if err == nil {
+					t.Error("expected error for invalid circuit breaker config, but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if config.Credinform.CircuitBreaker != tt.expected {
+				t.Errorf("expected circuit breaker config %+v, but got %+v", tt.expected, config.Credinform.CircuitBreaker)
+			}
+		})
+	}
+}
+
+func TestDataConfig(t *testing.T) {
+	envVars := []string{"DATA_ARBITRAGE_LOOKBACK", "DATA_ARBITRAGE_SIDES", "DATA_AFFILIATION_TYPES", "DATA_LICENSED_ACTIVITIES", "DATA_FINANCIAL_STATEMENTS_YEARS"}
+	for _, envVar := range envVars {
+		original := os.Getenv(envVar)
+		defer func(key, value string) {
+			if value == "" {
+				os.Unsetenv(key)
+			} else {
+				os.Setenv(key, value)
+			}
+		}(envVar, original)
+	}
+
+	tests := []struct {
+		name     string
+		envVars  map[string]string
+		expected DataConfig
+	}{
+		{
+			name:    "default_values",
+			envVars: map[string]string{},
+			expected: DataConfig{
+				ArbitrageLookback: "2025-01-01",
+				ArbitrageSides:    []string{"Claimant", "Defendant", "ThirdPartiesAndOthers"},
+				AffiliationTypes: []string{
+					"ByManagementOrShareholdersNaturalPersons",
+					"ByLiquidatorOrBankruptcyAdministrator",
+					"UnderAdministrationOfTheCompany",
+					"ByManagingLegalPersons",
+					"ByShareholdersLegalPersons",
+				},
+				LicensedActivities:       []string{"21.20", "41.20", "42", "43", "47.73", "64.19", "65", "71.11", "71.12", "80.10", "85", "86"},
+				FinancialStatementsYears: 5,
+			},
+		},
+		{
+			name: "custom_values",
+			envVars: map[string]string{
+				"DATA_ARBITRAGE_LOOKBACK":         "2024-06-01",
+				"DATA_ARBITRAGE_SIDES":            "Defendant, ",
+				"DATA_AFFILIATION_TYPES":          "ByShareholdersLegalPersons,ByManagingLegalPersons",
+				"DATA_LICENSED_ACTIVITIES":        "86, 47.73",
+				"DATA_FINANCIAL_STATEMENTS_YEARS": "3",
+			},
+			expected: DataConfig{
+				ArbitrageLookback:        "2024-06-01",
+				ArbitrageSides:           []string{"Defendant"},
+				AffiliationTypes:         []string{"ByShareholdersLegalPersons", "ByManagingLegalPersons"},
+				LicensedActivities:       []string{"86", "47.73"},
+				FinancialStatementsYears: 3,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, envVar := range envVars {
+				os.Unsetenv(envVar)
+			}
+			for key, value := range tt.envVars {
+				os.Setenv(key, value)
+			}
+
+			config, err := Load()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(config.Data, tt.expected) {
+				t.Errorf("expected data config %+v, but got %+v", tt.expected, config.Data)
+			}
+		})
+	}
+}
+
+func TestCredinformPaginationConfig(t *testing.T) {
+	envVars := []string{"CREDINFORM_PAGINATION_PAGE_SIZE", "CREDINFORM_PAGINATION_MAX_PAGES", "CREDINFORM_PAGINATION_METHODS"}
+	for _, envVar := range envVars {
+		original := os.Getenv(envVar)
+		defer func(key, value string) {
+			if value == "" {
+				os.Unsetenv(key)
+			} else {
+				os.Setenv(key, value)
+			}
+		}(envVar, original)
+	}
+
+	tests := []struct {
+		name          string
+		envVars       map[string]string
+		expected      PaginationConfig
+		expectedError bool
+	}{
+		{
+			name:     "default_values",
+			envVars:  map[string]string{},
+			expected: PaginationConfig{PageSize: 100, MaxPages: 10, MethodMaxPages: map[string]int{}},
+		},
+		{
+			name: "custom_values",
+			envVars: map[string]string{
+				"CREDINFORM_PAGINATION_PAGE_SIZE": "50",
+				"CREDINFORM_PAGINATION_MAX_PAGES": "3",
+				"CREDINFORM_PAGINATION_METHODS":   "CompanyInformation/StateContracts=30, CompanyInformation/ArbitrageCases=5",
+			},
+			expected: PaginationConfig{PageSize: 50, MaxPages: 3, MethodMaxPages: map[string]int{
+				"CompanyInformation/StateContracts": 30,
+				"CompanyInformation/ArbitrageCases": 5,
+			}},
+		},
+		{
+			name:          "invalid_method_limit",
+			envVars:       map[string]string{"CREDINFORM_PAGINATION_METHODS": "CompanyInformation/StateContracts=0"},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, envVar := range envVars {
+				os.Unsetenv(envVar)
+			}
+			for key, value := range tt.envVars {
+				os.Setenv(key, value)
+			}
+
+			config, err := Load()
+			if tt.expectedError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(config.Credinform.Pagination, tt.expected) {
+				t.Errorf("expected pagination config %+v, but got %+v", tt.expected, config.Credinform.Pagination)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package credinform
+
+import (
+	"context"
+
+	"scoring_worker/internal/credinform/types"
+)
+
+// CredinformAPI описывает операции Credinform API, используемые сервисом проверок.
+// Позволяет подменять клиент моками в тестах и оборачивать его декораторами
+// (кэширование, метрики и т.п.).
+type CredinformAPI interface {
+	SearchCompany(ctx context.Context, inn string) (*CompanyData, error)
+	GetBasicInformation(ctx context.Context, companyID string, params BasicInformationParams) (*types.BasicInformation, error)
+	GetActivities(ctx context.Context, companyID string, params ActivitiesParams) (*types.Activities, error)
+	GetAddressesByCredinform(ctx context.Context, companyID string, params AddressesByCredinformParams) (*types.AddressesByCredinform, error)
+	GetAddressesByUnifiedStateRegister(ctx context.Context, companyID string, params AddressesByUnifiedStateRegisterParams) (*types.AddressesByUnifiedStateRegister, error)
+	GetAffiliatedCompanies(ctx context.Context, companyID string, params AffiliatedCompaniesParams) (*types.AffiliatedCompanies, error)
+	GetArbitrageStatistics(ctx context.Context, companyID string, params ArbitrageStatisticsParams) (*types.ArbitrageStatistics, error)
+	GetFinancialStatements(ctx context.Context, companyID string, params FinancialStatementsParams) (*types.FinancialStatements, error)
+	GetArbitrageCases(ctx context.Context, companyID string, params ArbitrageCasesParams) (*types.ArbitrageCases, error)
+	GetBankruptcyMessages(ctx context.Context, companyID string, params BankruptcyMessagesParams) (*types.BankruptcyMessages, error)
+	GetEnforcementProceedings(ctx context.Context, companyID string, params EnforcementProceedingsParams) (*types.EnforcementProceedings, error)
+	GetStateContracts(ctx context.Context, companyID string, params StateContractsParams) (*types.StateContracts, error)
+	GetLicenses(ctx context.Context, companyID string, params LicensesParams) (*types.Licenses, error)
+	GetPledgesAndLeasing(ctx context.Context, companyID string, params PledgesAndLeasingParams) (*types.PledgesAndLeasing, error)
+}
+
+var _ CredinformAPI = (*Client)(nil)
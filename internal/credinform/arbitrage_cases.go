@@ -0,0 +1,27 @@
+package credinform
+
+import (
+	"context"
+	"fmt"
+	"scoring_worker/internal/credinform/types"
+)
+
+type ArbitrageCasesParams struct {
+	LastCaseChangeDateRange struct {
+		From string `json:"from"`
+	} `json:"lastCaseChangeDateRange"`
+	ArbitrageSideCommonType []string `json:"arbitrageSideCommonType"`
+}
+
+// GetArbitrageCases получает дела со всех страниц списка в пределах credinform.pagination.max_pages
+func (c *Client) GetArbitrageCases(ctx context.Context, companyID string, params ArbitrageCasesParams) (*types.ArbitrageCases, error) {
+	cases, truncated, err := getPages[*types.ArbitrageCase](ctx, c, "CompanyInformation/ArbitrageCases", companyID, params, "arbitrageCaseList")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get arbitrage cases: %w", err)
+	}
+
+	if cases == nil {
+		cases = []*types.ArbitrageCase{}
+	}
+	return &types.ArbitrageCases{CaseList: cases, Truncated: truncated}, nil
+}
@@ -0,0 +1,183 @@
+package credinform
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"scoring_worker/internal/config"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func TestGetArbitrageCasesPagination(t *testing.T) {
+	tests := []struct {
+		name           string
+		totalCases     int
+		withTotalCount bool
+		// serverPageSize — размер, до которого сервер урезает страницы; 0 — запрошенный
+		serverPageSize    int
+		maxPages          int
+		expectedCases     int
+		expectedRequests  int
+		expectedTruncated bool
+	}{
+		{
+			// Последняя страница неполная
+			name:             "partial_last_page",
+			totalCases:       5,
+			maxPages:         10,
+			expectedCases:    5,
+			expectedRequests: 3,
+		},
+		{
+			// Без totalCount полная последняя страница требует ещё одного запроса
+			name:             "full_last_page",
+			totalCases:       4,
+			maxPages:         10,
+			expectedCases:    4,
+			expectedRequests: 3,
+		},
+		{
+			name:             "total_count",
+			totalCases:       4,
+			withTotalCount:   true,
+			maxPages:         10,
+			expectedCases:    4,
+			expectedRequests: 2,
+		},
+		{
+			name:              "page_limit",
+			totalCases:        9,
+			maxPages:          2,
+			expectedCases:     4,
+			expectedRequests:  2,
+			expectedTruncated: true,
+		},
+		{
+			// Урезанные сервером страницы неполные, список заканчивается пустой страницей
+			name:             "server_capped_page_size",
+			totalCases:       3,
+			serverPageSize:   1,
+			maxPages:         10,
+			expectedCases:    3,
+			expectedRequests: 4,
+		},
+		{
+			name:             "no_cases",
+			totalCases:       0,
+			maxPages:         10,
+			expectedCases:    0,
+			expectedRequests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var pages []int
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/api/Authorization/GetAccessKey" {
+					json.NewEncoder(w).Encode(AuthResponse{AccessKey: "key"})
+					return
+				}
+				body, _ := io.ReadAll(r.Body)
+				var req struct {
+					Paging                  Paging                 `json:"paging"`
+					LastCaseChangeDateRange map[string]interface{} `json:"lastCaseChangeDateRange"`
+				}
+				json.Unmarshal(body, &req)
+				if req.LastCaseChangeDateRange["from"] != "2024-01-01T00:00:00" {
+					t.Errorf("expected filters on every page, got %s", body)
+				}
+				mu.Lock()
+				pages = append(pages, req.Paging.PageNumber)
+				mu.Unlock()
+
+				pageSize := req.Paging.PageSize
+				if tt.serverPageSize > 0 {
+					pageSize = tt.serverPageSize
+				}
+				var items []string
+				for i := (req.Paging.PageNumber - 1) * pageSize; i < tt.totalCases && i < req.Paging.PageNumber*pageSize; i++ {
+					items = append(items, fmt.Sprintf(`{"caseNumber":"А40-%d/2024"}`, i+1))
+				}
+				paging := fmt.Sprintf(`{"pageNumber":%d,"pageSize":%d}`, req.Paging.PageNumber, pageSize)
+				if tt.withTotalCount {
+					paging = fmt.Sprintf(`{"pageNumber":%d,"pageSize":%d,"totalCount":%d}`, req.Paging.PageNumber, pageSize, tt.totalCases)
+				}
+				fmt.Fprintf(w, `{"data":{"arbitrageCaseList":[%s],"paging":%s}}`, strings.Join(items, ","), paging)
+			}))
+			defer srv.Close()
+
+			client := NewClient(&config.CredinformConfig{
+				BaseURL:       srv.URL,
+				Username:      "user",
+				Password:      base64.StdEncoding.EncodeToString([]byte("secret")),
+				Timeout:       5,
+				RetryAttempts: 1,
+				Pagination:    config.PaginationConfig{PageSize: 2, MaxPages: tt.maxPages},
+			}, zaptest.NewLogger(t))
+
+			params := ArbitrageCasesParams{ArbitrageSideCommonType: []string{"Defendant"}}
+			params.LastCaseChangeDateRange.From = "2024-01-01T00:00:00"
+			cases, err := client.GetArbitrageCases(context.Background(), "company-id", params)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(cases.CaseList) != tt.expectedCases {
+				t.Errorf("expected %d cases, got %d", tt.expectedCases, len(cases.CaseList))
+			}
+			if len(pages) != tt.expectedRequests {
+				t.Errorf("expected %d page requests, got %v", tt.expectedRequests, pages)
+			}
+			for i, page := range pages {
+				if page != i+1 {
+					t.Errorf("expected pages requested in order, got %v", pages)
+					break
+				}
+			}
+			if cases.Truncated != tt.expectedTruncated {
+				t.Errorf("expected truncated %t, got %t", tt.expectedTruncated, cases.Truncated)
+			}
+			if len(cases.CaseList) > 0 && *cases.CaseList[0].CaseNumber != "А40-1/2024" {
+				t.Errorf("expected cases in page order, got %s", *cases.CaseList[0].CaseNumber)
+			}
+		})
+	}
+}
+
+func TestGetArbitrageCasesPageError(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/Authorization/GetAccessKey" {
+			json.NewEncoder(w).Encode(AuthResponse{AccessKey: "key"})
+			return
+		}
+		requests++
+		if requests == 2 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		items := make([]string, defaultPageSize)
+		for i := range items {
+			items[i] = `{"caseNumber":"А40-1/2024"}`
+		}
+		fmt.Fprintf(w, `{"data":{"arbitrageCaseList":[%s]}}`, strings.Join(items, ","))
+	}))
+	defer srv.Close()
+
+	// Ошибка на любой странице не даёт сохранить неполный список как полный
+	client := newTestClient(t, srv.URL)
+	if _, err := client.GetArbitrageCases(context.Background(), "company-id", ArbitrageCasesParams{}); err == nil || !strings.Contains(err.Error(), "page 2") {
+		t.Errorf("expected error for page 2, got %v", err)
+	}
+}
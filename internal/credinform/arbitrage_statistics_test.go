@@ -0,0 +1,84 @@
+package credinform
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"scoring_worker/internal/credinform/types"
+)
+
+func TestGetArbitrageStatistics(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/Authorization/GetAccessKey" {
+			json.NewEncoder(w).Encode(AuthResponse{AccessKey: "key"})
+			return
+		}
+		w.Write([]byte(`{"data":{
+			"caseCount":5,"caseSum":1500.5,"reportDate":"2025-03-01",
+			"arbitrageSideStatisticsList":[
+				{"arbitrageSideCommonType":"Claimant","caseCount":2,"caseSum":500,
+				 "yearList":[{"year":2024,"caseCount":2,"caseSum":500,"instance":"first"}]},
+				{"arbitrageSideCommonType":"Defendant","caseCount":3,"caseSum":1000.5}
+			],
+			"caseCategoryStatisticsList":[
+				{"caseCategoryCode":"BANKRUPTCY","caseCategoryName":"Банкротство","caseCount":1}
+			]
+		}}`))
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv.URL)
+	stats, err := client.GetArbitrageStatistics(context.Background(), "company-id", ArbitrageStatisticsParams{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if stats.CaseCount == nil || *stats.CaseCount != 5 || stats.CaseSum == nil || *stats.CaseSum != 1500.5 {
+		t.Errorf("expected totals to be parsed, got %+v", stats)
+	}
+	defendant := stats.Side(types.ArbitrageSideDefendant)
+	if defendant == nil || *defendant.CaseCount != 3 {
+		t.Errorf("expected defendant statistics, got %+v", defendant)
+	}
+	claimant := stats.Side(types.ArbitrageSideClaimant)
+	if claimant == nil || claimant.Year(2024) == nil || *claimant.Year(2024).CaseSum != 500 {
+		t.Errorf("expected claimant 2024 statistics, got %+v", claimant)
+	}
+	if stats.Side(types.ArbitrageSideThirdPartiesAndOthers) != nil {
+		t.Error("expected no statistics for missing side")
+	}
+	if len(stats.CategoryList) != 1 || *stats.CategoryList[0].CategoryCode != "BANKRUPTCY" {
+		t.Errorf("expected case category to be parsed, got %+v", stats.CategoryList)
+	}
+
+	// Неизвестные поля сохраняются на своём уровне и возвращаются при сериализации
+	if string(stats.Extension["reportDate"]) != `"2025-03-01"` {
+		t.Errorf("expected unknown top-level field to be kept, got %v", stats.Extension)
+	}
+	if _, ok := stats.Extension["caseCount"]; ok {
+		t.Error("expected known field not to be kept in extension")
+	}
+
+	data, err := json.Marshal(stats)
+	if err != nil {
+		t.Fatalf("unexpected marshal error: %v", err)
+	}
+	var roundTrip map[string]interface{}
+	if err := json.Unmarshal(data, &roundTrip); err != nil {
+		t.Fatalf("unexpected unmarshal error: %v", err)
+	}
+	if roundTrip["reportDate"] != "2025-03-01" || roundTrip["caseCount"] != float64(5) {
+		t.Errorf("expected known and unknown fields in serialized data, got %s", data)
+	}
+	sides, _ := roundTrip["arbitrageSideStatisticsList"].([]interface{})
+	if len(sides) != 2 {
+		t.Fatalf("expected two sides in serialized data, got %s", data)
+	}
+	years, _ := sides[0].(map[string]interface{})["yearList"].([]interface{})
+	if len(years) != 1 || years[0].(map[string]interface{})["instance"] != "first" {
+		t.Errorf("expected nested unknown field in serialized data, got %s", data)
+	}
+}
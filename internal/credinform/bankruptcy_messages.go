@@ -0,0 +1,29 @@
+package credinform
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"scoring_worker/internal/credinform/types"
+)
+
+type BankruptcyMessagesParams struct{}
+
+func (c *Client) GetBankruptcyMessages(ctx context.Context, companyID string, params BankruptcyMessagesParams) (*types.BankruptcyMessages, error) {
+	body, err := c.getCompanyData(ctx, "CompanyInformation/BankruptcyMessages", companyID, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bankruptcy messages: %w", err)
+	}
+
+	var response struct {
+		Data types.BankruptcyMessagesResponse `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal bankruptcy messages response: %w", err)
+	}
+	if response.Data.MessageList == nil {
+		response.Data.MessageList = []*types.BankruptcyMessage{}
+	}
+
+	return &response.Data, nil
+}
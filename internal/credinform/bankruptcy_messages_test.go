@@ -0,0 +1,75 @@
+package credinform
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"scoring_worker/internal/credinform/types"
+)
+
+func TestGetBankruptcyMessages(t *testing.T) {
+	tests := []struct {
+		name          string
+		response      string
+		expected      []types.BankruptcyMessage
+		expectedError bool
+	}{
+		{
+			name: "messages",
+			response: `{"data":{"bankruptcyMessageList":[
+				{"messageNumber":"12345","messageType":"ProcedureIntroduced","publicationDate":"2024-05-10T00:00:00",
+				 "procedureStage":"Observation","caseNumber":"А40-1/2024","publisherName":"Арбитражный управляющий","text":"Введено наблюдение"},
+				{"messageNumber":"12346","messageType":"IntentionToFileForBankruptcy"}
+			]}}`,
+			expected: []types.BankruptcyMessage{
+				{
+					MessageNumber:   ptr("12345"),
+					Kind:            ptr(types.BankruptcyMessageProcedureIntroduced),
+					PublicationDate: ptr("2024-05-10T00:00:00"),
+					Stage:           ptr(types.BankruptcyStageObservation),
+					CaseNumber:      ptr("А40-1/2024"),
+					Publisher:       ptr("Арбитражный управляющий"),
+					Text:            ptr("Введено наблюдение"),
+				},
+				{MessageNumber: ptr("12346"), Kind: ptr(types.BankruptcyMessageIntention)},
+			},
+		},
+		{
+			name:     "no_messages",
+			response: `{"data":{}}`,
+			expected: []types.BankruptcyMessage{},
+		},
+		{
+			name:          "malformed_response",
+			response:      `{"data":{"bankruptcyMessageList":{}}}`,
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := serveCompanyInformation(t, "CompanyInformation/BankruptcyMessages", tt.response, func(body []byte) {
+				var req struct {
+					CompanyID string `json:"companyId"`
+				}
+				json.Unmarshal(body, &req)
+				if req.CompanyID != "company-id" {
+					t.Errorf("expected companyId in request, got %s", body)
+				}
+			})
+			messages, err := client.GetBankruptcyMessages(context.Background(), "company-id", BankruptcyMessagesParams{})
+			if tt.expectedError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			assertItems(t, "messages", messages.MessageList, tt.expected)
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package credinform
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"scoring_worker/internal/config"
+)
+
+// ErrCircuitOpen возвращается без обращения к Credinform, пока breaker разомкнут
+var ErrCircuitOpen = errors.New("credinform circuit breaker is open")
+
+// CircuitState — состояние circuit breaker клиента
+type CircuitState int
+
+const (
+	// CircuitClosed — запросы выполняются как обычно
+	CircuitClosed CircuitState = iota
+	// CircuitOpen — запросы отклоняются с ErrCircuitOpen
+	CircuitOpen
+	// CircuitHalfOpen — таймаут истёк, следующий запрос станет пробным
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// circuitBreaker считает исходы запросов в фиксированном окне и размыкается,
+// когда доля временных ошибок достигает errorRate. После openTimeout пропускается
+// один пробный запрос: его успех замыкает breaker, ошибка размыкает снова.
+// Нулевой *circuitBreaker пропускает все запросы.
+type circuitBreaker struct {
+	errorRate   float64
+	minRequests int
+	window      time.Duration
+	openTimeout time.Duration
+	now         func() time.Time
+
+	mu          sync.Mutex
+	state       CircuitState
+	openedAt    time.Time
+	windowStart time.Time
+	requests    int
+	failures    int
+	probing     bool
+}
+
+func newCircuitBreaker(cfg *config.CircuitBreakerConfig) *circuitBreaker {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &circuitBreaker{
+		errorRate:   cfg.ErrorRate,
+		minRequests: max(cfg.MinRequests, 1),
+		window:      time.Duration(cfg.Window) * time.Second,
+		openTimeout: time.Duration(cfg.OpenTimeout) * time.Second,
+		now:         time.Now,
+	}
+}
+
+// State возвращает текущее состояние с учётом истёкшего таймаута размыкания
+func (b *circuitBreaker) State() CircuitState {
+	if b == nil {
+		return CircuitClosed
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stateLocked()
+}
+
+func (b *circuitBreaker) stateLocked() CircuitState {
+	if b.state == CircuitOpen && !b.now().Before(b.openedAt.Add(b.openTimeout)) {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+// allow разрешает запрос или возвращает ErrCircuitOpen. В полуоткрытом состоянии
+// разрешается только один пробный запрос за раз.
+func (b *circuitBreaker) allow() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.stateLocked() {
+	case CircuitOpen:
+		return ErrCircuitOpen
+	case CircuitHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.state = CircuitHalfOpen
+		b.probing = true
+	}
+	return nil
+}
+
+// record учитывает исход разрешённого запроса. Ошибкой сервиса считаются только
+// временные сбои: отсутствие компании или отказ в доступе говорят о том, что
+// Credinform отвечает.
+func (b *circuitBreaker) record(err error) {
+	if b == nil {
+		return
+	}
+	failed := IsRetryable(err)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitHalfOpen:
+		if !b.probing {
+			return
+		}
+		b.probing = false
+		if errors.Is(err, context.Canceled) {
+			return // Проба прервана вызывающим, следующий запрос станет пробным
+		}
+		if failed {
+			b.openLocked()
+		} else {
+			b.closeLocked()
+		}
+		return
+	case CircuitOpen:
+		return // Запрос начался до размыкания
+	}
+
+	now := b.now()
+	if now.Sub(b.windowStart) >= b.window {
+		b.windowStart = now
+		b.requests, b.failures = 0, 0
+	}
+	b.requests++
+	if failed {
+		b.failures++
+	}
+	if failed && b.requests >= b.minRequests && float64(b.failures) >= b.errorRate*float64(b.requests) {
+		b.openLocked()
+	}
+}
+
+func (b *circuitBreaker) openLocked() {
+	b.state = CircuitOpen
+	b.openedAt = b.now()
+}
+
+func (b *circuitBreaker) closeLocked() {
+	b.state = CircuitClosed
+	b.windowStart = b.now()
+	b.requests, b.failures = 0, 0
+}
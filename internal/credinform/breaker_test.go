@@ -0,0 +1,158 @@
+package credinform
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"scoring_worker/internal/config"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	serverErr := &APIError{StatusCode: http.StatusServiceUnavailable}
+	notFound := fmt.Errorf("search: %w", ErrCompanyNotFound)
+
+	tests := []struct {
+		name          string
+		outcomes      []error
+		expectedState CircuitState
+	}{
+		{
+			name:          "below_min_requests",
+			outcomes:      []error{serverErr, serverErr, serverErr},
+			expectedState: CircuitClosed,
+		},
+		{
+			name:          "error_rate_reached",
+			outcomes:      []error{nil, serverErr, nil, serverErr},
+			expectedState: CircuitOpen,
+		},
+		{
+			name:          "error_rate_below_threshold",
+			outcomes:      []error{nil, nil, nil, serverErr, nil},
+			expectedState: CircuitClosed,
+		},
+		{
+			// Окончательные ошибки означают, что Credinform отвечает
+			name:          "permanent_errors_ignored",
+			outcomes:      []error{notFound, notFound, notFound, notFound},
+			expectedState: CircuitClosed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newCircuitBreaker(&config.CircuitBreakerConfig{
+				Enabled: true, ErrorRate: 0.5, MinRequests: 4, Window: 60, OpenTimeout: 30,
+			})
+			for _, err := range tt.outcomes {
+				if allowErr := b.allow(); allowErr != nil {
+					t.Fatalf("unexpected rejection: %v", allowErr)
+				}
+				b.record(err)
+			}
+			if state := b.State(); state != tt.expectedState {
+				t.Errorf("expected state %s, got %s", tt.expectedState, state)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	now := time.Now()
+	b := newCircuitBreaker(&config.CircuitBreakerConfig{
+		Enabled: true, ErrorRate: 0.5, MinRequests: 1, Window: 60, OpenTimeout: 30,
+	})
+	b.now = func() time.Time { return now }
+
+	b.allow()
+	b.record(&APIError{StatusCode: http.StatusBadGateway})
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen while open, got %v", err)
+	}
+
+	// После таймаута пропускается ровно один пробный запрос
+	now = now.Add(30 * time.Second)
+	if state := b.State(); state != CircuitHalfOpen {
+		t.Fatalf("expected half-open after timeout, got %s", state)
+	}
+	if err := b.allow(); err != nil {
+		t.Fatalf("expected probe to be allowed, got %v", err)
+	}
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected concurrent request to be rejected during probe, got %v", err)
+	}
+
+	// Неудачная проба снова размыкает breaker
+	b.record(&APIError{StatusCode: http.StatusBadGateway})
+	if state := b.State(); state != CircuitOpen {
+		t.Fatalf("expected open after failed probe, got %s", state)
+	}
+
+	// Успешная проба замыкает breaker
+	now = now.Add(30 * time.Second)
+	if err := b.allow(); err != nil {
+		t.Fatalf("expected probe to be allowed, got %v", err)
+	}
+	b.record(nil)
+	if state := b.State(); state != CircuitClosed {
+		t.Fatalf("expected closed after successful probe, got %s", state)
+	}
+	if err := b.allow(); err != nil {
+		t.Errorf("expected requests to pass after recovery, got %v", err)
+	}
+}
+
+func TestCircuitBreakerCanceledProbe(t *testing.T) {
+	now := time.Now()
+	b := newCircuitBreaker(&config.CircuitBreakerConfig{
+		Enabled: true, ErrorRate: 0.5, MinRequests: 1, Window: 60, OpenTimeout: 30,
+	})
+	b.now = func() time.Time { return now }
+
+	b.allow()
+	b.record(&APIError{StatusCode: http.StatusBadGateway})
+	now = now.Add(30 * time.Second)
+
+	b.allow()
+	b.record(context.Canceled)
+	if err := b.allow(); err != nil {
+		t.Errorf("expected a new probe after canceled one, got %v", err)
+	}
+}
+
+func TestClientFailsFastWhenCircuitOpen(t *testing.T) {
+	fake := &scriptedCredinform{statuses: []int{http.StatusServiceUnavailable}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	client := newTestClient(t, srv.URL)
+	client.retry = retryPolicy{attempts: 0, jitter: func() float64 { return 0 }}
+	client.breaker = newCircuitBreaker(&config.CircuitBreakerConfig{
+		Enabled: true, ErrorRate: 0.5, MinRequests: 2, Window: 60, OpenTimeout: 60,
+	})
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetBasicInformation(context.Background(), "company-id", BasicInformationParams{}); errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("request %d rejected before breaker opened", i)
+		}
+	}
+	if state := client.CircuitState(); state != CircuitOpen {
+		t.Fatalf("expected breaker to open, got %s", state)
+	}
+
+	_, err := client.GetBasicInformation(context.Background(), "company-id", BasicInformationParams{})
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if IsRetryable(err) {
+		t.Errorf("expected open breaker error not to be retried, got %v", err)
+	}
+	if fake.dataCalls != 2 {
+		t.Errorf("expected no request to reach Credinform while open, got %d data requests", fake.dataCalls)
+	}
+}
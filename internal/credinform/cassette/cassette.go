@@ -0,0 +1,132 @@
+// Package cassette записывает обмен с Credinform API в файлы-кассеты и
+// воспроизводит его без обращения к сети. Пароль и ключ доступа в кассету
+// не попадают, поэтому записи можно прикладывать к задачам и хранить в тестах.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Redacted заменяет секреты в записанных запросах и ответах
+const Redacted = "REDACTED"
+
+// authPath — окончание пути метода авторизации, тело которого содержит секреты
+const authPath = "/Authorization/GetAccessKey"
+
+// recordedHeaders — заголовки ответа, которые сохраняются в кассете
+var recordedHeaders = []string{"Content-Type", "Retry-After"}
+
+// Cassette — записанные запросы и ответы в порядке выполнения
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+type Request struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Body   Body   `json:"body"`
+}
+
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       Body        `json:"body"`
+}
+
+// Body хранит JSON тело как есть, чтобы кассету было удобно читать и править,
+// а тело, не являющееся JSON, — строкой в Raw
+type Body struct {
+	JSON json.RawMessage `json:"json,omitempty"`
+	Raw  string          `json:"raw,omitempty"`
+}
+
+func newBody(data []byte) Body {
+	if len(bytes.TrimSpace(data)) > 0 && json.Valid(data) {
+		return Body{JSON: json.RawMessage(data)}
+	}
+	return Body{Raw: string(data)}
+}
+
+// Bytes возвращает тело в исходном виде
+func (b Body) Bytes() []byte {
+	if b.JSON != nil {
+		return b.JSON
+	}
+	return []byte(b.Raw)
+}
+
+// Load читает кассету из файла
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// Save записывает кассету в файл, заменяя его целиком
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cassette: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to save cassette: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save cassette: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save cassette: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to save cassette: %w", err)
+	}
+	return nil
+}
+
+func isAuth(path string) bool {
+	return strings.HasSuffix(path, authPath)
+}
+
+// redactFields заменяет значения указанных полей верхнего уровня JSON объекта
+func redactFields(data []byte, fields ...string) []byte {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return data
+	}
+	redacted, _ := json.Marshal(Redacted)
+	changed := false
+	for _, field := range fields {
+		if _, ok := obj[field]; ok {
+			obj[field] = redacted
+			changed = true
+		}
+	}
+	if !changed {
+		return data
+	}
+	result, err := json.Marshal(obj)
+	if err != nil {
+		return data
+	}
+	return result
+}
@@ -0,0 +1,215 @@
+package cassette_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"scoring_worker/internal/config"
+	"scoring_worker/internal/credinform"
+	"scoring_worker/internal/credinform/cassette"
+	"scoring_worker/internal/credinform/fakecredinform"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func newClient(t *testing.T, baseURL string, transport http.RoundTripper) *credinform.Client {
+	t.Helper()
+
+	client := credinform.NewClient(&config.CredinformConfig{
+		BaseURL:       baseURL,
+		Username:      "user",
+		Password:      base64.StdEncoding.EncodeToString([]byte("secret")),
+		Timeout:       5,
+		RetryAttempts: 2,
+	}, zaptest.NewLogger(t), credinform.WithTransport(transport))
+	if client == nil {
+		t.Fatal("failed to create client")
+	}
+	return client
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	fake := fakecredinform.New(os.DirFS("../fakecredinform/testdata"), fakecredinform.WithCredentials("user", "secret"))
+	srv := httptest.NewServer(fake)
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	ctx := context.Background()
+	recording := newClient(t, srv.URL, cassette.NewRecorder(path, nil))
+	recorded, err := recording.SearchCompany(ctx, "7700000001")
+	if err != nil {
+		t.Fatalf("unexpected search error: %v", err)
+	}
+	recordedInfo, err := recording.GetBasicInformation(ctx, recorded.CompanyID, credinform.BasicInformationParams{})
+	if err != nil {
+		t.Fatalf("unexpected basic information error: %v", err)
+	}
+	srv.Close()
+
+	// Секреты не должны попасть в кассету
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read cassette: %v", err)
+	}
+	for _, secret := range []string{"secret", "fake-access-key"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette contains secret %q:\n%s", secret, data)
+		}
+	}
+
+	c, err := cassette.Load(path)
+	if err != nil {
+		t.Fatalf("failed to load cassette: %v", err)
+	}
+	if len(c.Interactions) != 3 {
+		t.Fatalf("expected 3 recorded interactions, got %d", len(c.Interactions))
+	}
+
+	// Воспроизведение не обращается к остановленному серверу
+	replaying := newClient(t, srv.URL, cassette.NewReplayer(c))
+	replayed, err := replaying.SearchCompany(ctx, "7700000001")
+	if err != nil {
+		t.Fatalf("unexpected replayed search error: %v", err)
+	}
+	if *replayed != *recorded {
+		t.Errorf("expected replayed company %+v, got %+v", recorded, replayed)
+	}
+	replayedInfo, err := replaying.GetBasicInformation(ctx, replayed.CompanyID, credinform.BasicInformationParams{})
+	if err != nil {
+		t.Fatalf("unexpected replayed basic information error: %v", err)
+	}
+	if *replayedInfo.TaxNumber != *recordedInfo.TaxNumber {
+		t.Errorf("expected replayed tax number %s, got %s", *recordedInfo.TaxNumber, *replayedInfo.TaxNumber)
+	}
+}
+
+func TestReplayCassetteFromTestdata(t *testing.T) {
+	c, err := cassette.Load("testdata/basic_information.json")
+	if err != nil {
+		t.Fatalf("failed to load cassette: %v", err)
+	}
+
+	client := newClient(t, "http://credinform.invalid", cassette.NewReplayer(c))
+	info, err := client.GetBasicInformation(context.Background(), "fake-0001", credinform.BasicInformationParams{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.LegalForm == nil || info.LegalForm.ShortName == nil || *info.LegalForm.ShortName != "ООО" {
+		t.Errorf("expected legal form to be parsed, got %+v", info.LegalForm)
+	}
+
+	// Запрос другой компании в кассете не записан
+	_, err = client.GetBasicInformation(context.Background(), "fake-0002", credinform.BasicInformationParams{})
+	if !errors.Is(err, cassette.ErrNoInteraction) {
+		t.Errorf("expected ErrNoInteraction, got %v", err)
+	}
+}
+
+func TestReplayRepeatedRequestsInOrder(t *testing.T) {
+	search := cassette.Request{
+		Method: http.MethodPost,
+		Path:   "/api/Search/SearchCompany",
+		Body:   cassette.Body{JSON: []byte(`{"language":"Russian","searchCompanyParameters":{"taxNumber":"7700000001"}}`)},
+	}
+	c := &cassette.Cassette{Interactions: []cassette.Interaction{
+		{
+			Request:  cassette.Request{Method: http.MethodPost, Path: "/api/Authorization/GetAccessKey"},
+			Response: cassette.Response{StatusCode: http.StatusOK, Body: cassette.Body{JSON: []byte(`{"accessKey":"REDACTED"}`)}},
+		},
+		{
+			Request:  search,
+			Response: cassette.Response{StatusCode: http.StatusServiceUnavailable, Body: cassette.Body{Raw: "Service Unavailable"}},
+		},
+		{
+			Request:  search,
+			Response: cassette.Response{StatusCode: http.StatusOK, Body: cassette.Body{JSON: []byte(`{"companyDataList":[{"companyId":"fake-0001"}]}`)}},
+		},
+	}}
+
+	client := newClient(t, "http://credinform.invalid", cassette.NewReplayer(c))
+
+	// Первый ответ 503 повторяется клиентом, второй запрос получает следующую запись
+	company, err := client.SearchCompany(context.Background(), "7700000001")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if company.CompanyID != "fake-0001" {
+		t.Errorf("expected fake-0001, got %s", company.CompanyID)
+	}
+
+	// Когда записи исчерпаны, повторяется последняя
+	if _, err := client.SearchCompany(context.Background(), "7700000001"); err != nil {
+		t.Errorf("expected last interaction to be replayed again, got %v", err)
+	}
+}
+
+func TestReplayIgnoresDateRanges(t *testing.T) {
+	c := &cassette.Cassette{Interactions: []cassette.Interaction{
+		{
+			Request:  cassette.Request{Method: http.MethodPost, Path: "/api/Authorization/GetAccessKey"},
+			Response: cassette.Response{StatusCode: http.StatusOK, Body: cassette.Body{JSON: []byte(`{"accessKey":"REDACTED"}`)}},
+		},
+		{
+			Request: cassette.Request{
+				Method: http.MethodPost,
+				Path:   "/api/CompanyInformation/ArbitrageStatistics",
+				Body:   cassette.Body{JSON: []byte(`{"companyId":"fake-0001","language":"Russian","lastCaseChangeDateRange":{"from":"2023-01-01"},"arbitrageSideCommonType":["Defendant"]}`)},
+			},
+			Response: cassette.Response{StatusCode: http.StatusOK, Body: cassette.Body{JSON: []byte(`{"data":{}}`)}},
+		},
+	}}
+
+	client := newClient(t, "http://credinform.invalid", cassette.NewReplayer(c))
+
+	// Относительное окно к моменту воспроизведения сдвинулось, запись всё равно подходит
+	params := credinform.ArbitrageStatisticsParams{ArbitrageSideCommonType: []string{"Defendant"}}
+	params.LastCaseChangeDateRange.From = "2023-02-15"
+	if _, err := client.GetArbitrageStatistics(context.Background(), "fake-0001", params); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Остальные параметры по-прежнему учитываются
+	params.ArbitrageSideCommonType = []string{"Claimant"}
+	_, err := client.GetArbitrageStatistics(context.Background(), "fake-0001", params)
+	if !errors.Is(err, cassette.ErrNoInteraction) {
+		t.Errorf("expected ErrNoInteraction, got %v", err)
+	}
+}
+
+func TestReplayIgnoresStatementPeriod(t *testing.T) {
+	c := &cassette.Cassette{Interactions: []cassette.Interaction{
+		{
+			Request:  cassette.Request{Method: http.MethodPost, Path: "/api/Authorization/GetAccessKey"},
+			Response: cassette.Response{StatusCode: http.StatusOK, Body: cassette.Body{JSON: []byte(`{"accessKey":"REDACTED"}`)}},
+		},
+		{
+			Request: cassette.Request{
+				Method: http.MethodPost,
+				Path:   "/api/CompanyInformation/AccountingStatements",
+				Body:   cassette.Body{JSON: []byte(`{"companyId":"fake-0001","language":"Russian","period":{"from":2020,"to":2024},"forms":["BalanceSheet"]}`)},
+			},
+			Response: cassette.Response{StatusCode: http.StatusOK, Body: cassette.Body{JSON: []byte(`{"data":{}}`)}},
+		},
+	}}
+
+	client := newClient(t, "http://credinform.invalid", cassette.NewReplayer(c))
+
+	// Запись сделана годом раньше: период отчётности сдвинулся
+	params := credinform.FinancialStatementsParams{Forms: []string{"BalanceSheet"}}
+	params.Period.From, params.Period.To = 2021, 2025
+	if _, err := client.GetFinancialStatements(context.Background(), "fake-0001", params); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	params.Forms = []string{"ProfitAndLoss"}
+	_, err := client.GetFinancialStatements(context.Background(), "fake-0001", params)
+	if !errors.Is(err, cassette.ErrNoInteraction) {
+		t.Errorf("expected ErrNoInteraction, got %v", err)
+	}
+}
@@ -0,0 +1,215 @@
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"scoring_worker/internal/canonicaljson"
+)
+
+// ErrNoInteraction возвращается при воспроизведении запроса, которого нет в кассете
+var ErrNoInteraction = errors.New("no recorded interaction for request")
+
+// Recorder — http.RoundTripper, который выполняет запросы через next и дописывает
+// каждый обмен в кассету. Файл перезаписывается после каждого запроса, чтобы
+// запись сохранилась при аварийной остановке.
+type Recorder struct {
+	path string
+	next http.RoundTripper
+
+	mu       sync.Mutex
+	cassette Cassette
+	secrets  [][]byte
+}
+
+// NewRecorder создаёт Recorder, пишущий в path. Если next равен nil, используется http.DefaultTransport.
+func NewRecorder(path string, next http.RoundTripper) *Recorder {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Recorder{path: path, next: next}
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	// Исходный запрос не изменяется: дальше уходит копия с прочитанным телом
+	out := req.Clone(req.Context())
+	if reqBody != nil {
+		out.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	resp, err := r.next.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := readBody(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	header := make(http.Header)
+	for _, name := range recordedHeaders {
+		if value := resp.Header.Get(name); value != "" {
+			header.Set(name, value)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if isAuth(req.URL.Path) {
+		reqBody = redactFields(reqBody, "password")
+		respBody = r.redactAccessKey(respBody)
+	}
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: Request{
+			Method: req.Method,
+			Path:   req.URL.Path,
+			Body:   newBody(r.redactSecrets(reqBody)),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     header,
+			Body:       newBody(r.redactSecrets(respBody)),
+		},
+	})
+	if err := r.cassette.Save(r.path); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
+}
+
+// redactAccessKey запоминает выданный ключ доступа и убирает его из ответа авторизации
+func (r *Recorder) redactAccessKey(body []byte) []byte {
+	var auth struct {
+		AccessKey string `json:"accessKey"`
+	}
+	if err := json.Unmarshal(body, &auth); err == nil && auth.AccessKey != "" {
+		r.secrets = append(r.secrets, []byte(auth.AccessKey))
+	}
+	return redactFields(body, "accessKey")
+}
+
+// redactSecrets убирает известные ключи доступа из любого тела
+func (r *Recorder) redactSecrets(body []byte) []byte {
+	for _, secret := range r.secrets {
+		body = bytes.ReplaceAll(body, secret, []byte(Redacted))
+	}
+	return body
+}
+
+// Replayer — http.RoundTripper, который отвечает записанными ответами, не обращаясь
+// к сети. Запрос сопоставляется по методу, пути и телу без учёта форматирования JSON;
+// тело авторизации не сравнивается, так как пароль в кассете скрыт. Одинаковые
+// запросы получают записанные ответы по порядку, после чего повторяется последний.
+type Replayer struct {
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+func NewReplayer(c *Cassette) *Replayer {
+	return &Replayer{
+		interactions: c.Interactions,
+		used:         make([]bool, len(c.Interactions)),
+	}
+}
+
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	key := matchKey(req.Method, req.URL.Path, body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	last := -1
+	for i, in := range r.interactions {
+		if matchKey(in.Request.Method, in.Request.Path, in.Request.Body.Bytes()) != key {
+			continue
+		}
+		if !r.used[i] {
+			r.used[i] = true
+			return newResponse(req, in.Response), nil
+		}
+		last = i
+	}
+	if last >= 0 {
+		return newResponse(req, r.interactions[last].Response), nil
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, req.Method, req.URL.Path)
+}
+
+// matchKey сопоставляет запрос с записью. Окна дат (поля *DateRange) и период
+// отчётности (period) не учитываются: они отсчитываются от момента обработки,
+// и запись иначе перестала бы воспроизводиться на следующий день или год.
+func matchKey(method, path string, body []byte) string {
+	if isAuth(path) {
+		return method + " " + path
+	}
+	return method + " " + path + " " + canonicaljson.Format(string(withoutWindows(body)))
+}
+
+// withoutWindows удаляет из тела запроса поля верхнего уровня с суффиксом DateRange и period
+func withoutWindows(body []byte) []byte {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return body
+	}
+	removed := false
+	for name := range fields {
+		if strings.HasSuffix(name, "DateRange") || name == "period" {
+			delete(fields, name)
+			removed = true
+		}
+	}
+	if !removed {
+		return body
+	}
+	result, err := json.Marshal(fields)
+	if err != nil {
+		return body
+	}
+	return result
+}
+
+func newResponse(req *http.Request, recorded Response) *http.Response {
+	body := recorded.Body.Bytes()
+	header := recorded.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// readBody читает и закрывает тело запроса или ответа
+func readBody(body io.ReadCloser) ([]byte, error) {
+	if body == nil || body == http.NoBody {
+		return nil, nil
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
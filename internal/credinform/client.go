@@ -5,10 +5,10 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"strings"
 	"time"
 
 	"scoring_worker/internal/config"
@@ -20,7 +20,10 @@ type Client struct {
 	httpClient *http.Client
 	config     *config.CredinformConfig
 	logger     *zap.Logger
-	accessKey  string
+	tokens     *tokenManager
+	retry      retryPolicy
+	limiter    Limiter
+	breaker    *circuitBreaker
 	username   string
 	password   string
 	apiVersion string
@@ -76,18 +79,47 @@ type FoundationDate struct {
 	Date  string `json:"date"`
 }
 
+// CompanyInformationRequest — запрос данных компании. Параметры метода из Extra
+// передаются на верхнем уровне запроса рядом с companyId и language.
 type CompanyInformationRequest struct {
 	CompanyID string                 `json:"companyId"`
 	Language  string                 `json:"language"`
 	Extra     map[string]interface{} `json:"-"`
 }
 
+func (r CompanyInformationRequest) MarshalJSON() ([]byte, error) {
+	fields := make(map[string]interface{}, len(r.Extra)+2)
+	for k, v := range r.Extra {
+		fields[k] = v
+	}
+	fields["companyId"] = r.CompanyID
+	fields["language"] = r.Language
+	return json.Marshal(fields)
+}
+
 type CompanyInformationResponse struct {
 	Data  interface{} `json:"data"`
 	Error string      `json:"error,omitempty"`
 }
 
-func NewClient(cfg *config.CredinformConfig, logger *zap.Logger) *Client {
+// Option настраивает Client при создании
+type Option func(*Client)
+
+// WithLimiter задаёт ограничение частоты запросов вместо построенного по конфигурации
+func WithLimiter(limiter Limiter) Option {
+	return func(c *Client) {
+		c.limiter = limiter
+	}
+}
+
+// WithTransport задаёт HTTP транспорт клиента, например для записи и воспроизведения обмена с API
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Client) {
+		c.httpClient.Transport = transport
+	}
+}
+
+func NewClient(cfg *config.CredinformConfig, logger *zap.Logger, opts ...Option) *Client {
 	logger.Info("Creating Credinform client",
 		zap.String("username", cfg.Username),
 		zap.String("base_url", cfg.BaseURL),
@@ -109,7 +141,7 @@ func NewClient(cfg *config.CredinformConfig, logger *zap.Logger) *Client {
 		logger.Error("failed to decode credinform password from base64", zap.Error(err))
 		return nil
 	}
-	return &Client{
+	c := &Client{
 		httpClient: &http.Client{
 			Timeout: time.Duration(cfg.Timeout) * time.Second,
 		},
@@ -118,10 +150,35 @@ func NewClient(cfg *config.CredinformConfig, logger *zap.Logger) *Client {
 		username:   cfg.Username,
 		password:   string(decodedPassword),
 		apiVersion: "1.7",
+		retry:      newRetryPolicy(cfg),
+		limiter:    unlimited{},
+		breaker:    newCircuitBreaker(&cfg.CircuitBreaker),
+	}
+	if cfg.RateLimit.Enabled {
+		c.limiter = NewLocalLimiter(&cfg.RateLimit)
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.tokens = newTokenManager(c.requestAccessKey,
+		time.Duration(cfg.AccessKeyLifetime)*time.Second,
+		time.Duration(cfg.AccessKeyRefreshBefore)*time.Second)
+	return c
+}
+
+// CircuitState возвращает состояние circuit breaker клиента
+func (c *Client) CircuitState() CircuitState {
+	return c.breaker.State()
 }
 
+// Authenticate убеждается, что у клиента есть действующий ключ доступа
 func (c *Client) Authenticate(ctx context.Context) error {
+	_, err := c.tokens.Key(ctx)
+	return err
+}
+
+// requestAccessKey получает новый ключ доступа. Вызывается только tokenManager.
+func (c *Client) requestAccessKey(ctx context.Context) (string, error) {
 	authReq := AuthRequest{
 		Username: c.username,
 		Password: c.password,
@@ -129,54 +186,51 @@ func (c *Client) Authenticate(ctx context.Context) error {
 
 	reqBody, err := json.Marshal(authReq)
 	if err != nil {
-		return fmt.Errorf("failed to marshal auth request: %w", err)
+		return "", fmt.Errorf("failed to marshal auth request: %w", err)
+	}
+
+	if err := c.limiter.Wait(ctx, "Authorization/GetAccessKey"); err != nil {
+		return "", err
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "POST",
 		fmt.Sprintf("%s/api/Authorization/GetAccessKey", c.config.BaseURL),
 		bytes.NewBuffer(reqBody))
 	if err != nil {
-		return fmt.Errorf("failed to create auth request: %w", err)
+		return "", fmt.Errorf("failed to create auth request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to send auth request: %w", err)
+		return "", fmt.Errorf("failed to send auth request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to read auth response: %w", err)
+		return "", fmt.Errorf("failed to read auth response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("auth failed with status %d: %s", resp.StatusCode, string(body))
+		return "", fmt.Errorf("auth failed: %w", &APIError{StatusCode: resp.StatusCode, Method: "Authorization/GetAccessKey", Body: string(body)})
 	}
 
 	var authResp AuthResponse
 	if err := json.Unmarshal(body, &authResp); err != nil {
-		return fmt.Errorf("failed to unmarshal auth response: %w", err)
+		return "", fmt.Errorf("failed to unmarshal auth response: %w", err)
 	}
 
 	if authResp.Error != "" {
-		return fmt.Errorf("auth error: %s", authResp.Error)
+		return "", fmt.Errorf("auth error: %w: %s", ErrUnauthorized, authResp.Error)
 	}
 
-	c.accessKey = authResp.AccessKey
 	c.logger.Info("Successfully authenticated with Credinform API")
-	return nil
+	return authResp.AccessKey, nil
 }
 
 func (c *Client) SearchCompany(ctx context.Context, inn string) (*CompanyData, error) {
-	if c.accessKey == "" {
-		if err := c.Authenticate(ctx); err != nil {
-			return nil, fmt.Errorf("failed to authenticate: %w", err)
-		}
-	}
-
 	searchReq := SearchCompanyRequest{
 		Language: "Russian",
 		SearchCompanyParameters: SearchCompanyParameters{
@@ -189,75 +243,22 @@ func (c *Client) SearchCompany(ctx context.Context, inn string) (*CompanyData, e
 		return nil, fmt.Errorf("failed to marshal search request: %w", err)
 	}
 
-	url := fmt.Sprintf("%s/api/Search/SearchCompany?apiVersion=%s",
-		c.config.BaseURL, c.apiVersion)
-
-	var result *CompanyData
-	var lastErr error
-
-	for attempt := 0; attempt <= c.config.RetryAttempts; attempt++ {
-		if attempt > 0 {
-			c.logger.Info("Retrying search request", zap.String("inn", inn), zap.Int("attempt", attempt))
-			time.Sleep(time.Duration(c.config.RetryDelay) * time.Second)
-		}
-
-		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(reqData))
-		if err != nil {
-			lastErr = fmt.Errorf("failed to create search request: %w", err)
-			continue
-		}
-
-		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("accessKey", c.accessKey)
-
-		resp, err := c.httpClient.Do(req)
-		if err != nil {
-			lastErr = fmt.Errorf("failed to send search request: %w", err)
-			continue
-		}
-
-		body, err := io.ReadAll(resp.Body)
-		resp.Body.Close()
-		if err != nil {
-			lastErr = fmt.Errorf("failed to read search response: %w", err)
-			continue
-		}
-
-		if resp.StatusCode == http.StatusUnauthorized {
-			c.logger.Info("Token expired, re-authenticating")
-			c.accessKey = ""
-			if err := c.Authenticate(ctx); err != nil {
-				lastErr = fmt.Errorf("failed to re-authenticate: %w", err)
-				continue
-			}
-			req.Header.Set("accessKey", c.accessKey)
-			continue
-		}
-
-		if resp.StatusCode != http.StatusOK {
-			lastErr = fmt.Errorf("search request failed with status %d: %s", resp.StatusCode, string(body))
-			continue
-		}
-
-		var response SearchCompanyResponse
-		if err := json.Unmarshal(body, &response); err != nil {
-			lastErr = fmt.Errorf("failed to unmarshal search response: %w", err)
-			continue
-		}
-
-		if len(response.CompanyDataList) == 0 {
-			return nil, fmt.Errorf("company not found")
-		}
+	body, err := c.doRequest(ctx, "Search/SearchCompany", reqData)
+	if err != nil {
+		return nil, err
+	}
 
-		result = &response.CompanyDataList[0]
-		c.logger.Info("Successfully found company", zap.String("inn", inn), zap.String("company_id", result.CompanyID))
-		break
+	var response SearchCompanyResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal search response: %w", err)
 	}
 
-	if result == nil {
-		return nil, fmt.Errorf("all retry attempts failed: %w", lastErr)
+	if len(response.CompanyDataList) == 0 {
+		return nil, ErrCompanyNotFound
 	}
 
+	result := &response.CompanyDataList[0]
+	c.logger.Info("Successfully found company", zap.String("inn", inn), zap.String("company_id", result.CompanyID))
 	return result, nil
 }
 
@@ -275,12 +276,6 @@ func structToMap(data interface{}) (map[string]interface{}, error) {
 }
 
 func (c *Client) getCompanyData(ctx context.Context, method string, companyID string, params interface{}) ([]byte, error) {
-	if c.accessKey == "" {
-		if err := c.Authenticate(ctx); err != nil {
-			return nil, fmt.Errorf("failed to authenticate for %s: %w", method, err)
-		}
-	}
-
 	extra, err := structToMap(params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert params to map for %s: %w", method, err)
@@ -295,62 +290,108 @@ func (c *Client) getCompanyData(ctx context.Context, method string, companyID st
 		return nil, fmt.Errorf("failed to marshal request for %s: %w", method, err)
 	}
 
+	return c.doRequest(ctx, method, reqData)
+}
+
+// doRequest выполняет POST запрос к методу API через circuit breaker
+func (c *Client) doRequest(ctx context.Context, method string, payload []byte) ([]byte, error) {
+	if err := c.breaker.allow(); err != nil {
+		return nil, fmt.Errorf("request %s rejected: %w", method, err)
+	}
+	body, err := c.doRequestWithRetry(ctx, method, payload)
+	c.breaker.record(err)
+	return body, err
+}
+
+// doRequestWithRetry выполняет запрос с повторами согласно retryPolicy. После ответа 401
+// ключ доступа обновляется и запрос сразу повторяется один раз. Повторы прекращаются,
+// если за время ожидания breaker разомкнулся из-за сбоев других запросов.
+func (c *Client) doRequestWithRetry(ctx context.Context, method string, payload []byte) ([]byte, error) {
 	url := fmt.Sprintf("%s/api/%s?apiVersion=%s", c.config.BaseURL, method, c.apiVersion)
-	var responseBody []byte
-	var allErrors []string
+	reauthenticated := false
 
-	for attempt := 0; attempt <= c.config.RetryAttempts; attempt++ {
+	var lastErr error
+	for attempt := 0; ; attempt++ {
 		if attempt > 0 {
-			c.logger.Info("Retrying request", zap.String("method", method), zap.String("companyID", companyID), zap.Int("attempt", attempt))
-			time.Sleep(time.Duration(c.config.RetryDelay) * time.Second)
+			delay := c.retry.delay(attempt, lastErr)
+			c.logger.Info("Retrying request",
+				zap.String("method", method),
+				zap.Int("attempt", attempt),
+				zap.Duration("delay", delay),
+				zap.Error(lastErr))
+			if err := wait(ctx, delay); err != nil {
+				return nil, fmt.Errorf("retry of %s interrupted: %w", method, err)
+			}
+			if c.breaker.State() == CircuitOpen {
+				return nil, fmt.Errorf("retry of %s stopped: %w: %w", method, ErrCircuitOpen, lastErr)
+			}
 		}
 
-		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(reqData))
+		accessKey, err := c.tokens.Key(ctx)
 		if err != nil {
-			errMessage := fmt.Sprintf("failed to create request for %s: %v", method, err)
-			allErrors = append(allErrors, errMessage)
-			continue
+			lastErr = fmt.Errorf("failed to authenticate for %s: %w", method, err)
+		} else {
+			var body []byte
+			body, err = c.send(ctx, method, url, payload, accessKey)
+			if err == nil {
+				return body, nil
+			}
+			lastErr = err
+
+			if errors.Is(err, ErrUnauthorized) && !reauthenticated {
+				c.logger.Info("Token expired, re-authenticating", zap.String("method", method))
+				reauthenticated = true
+				if _, err := c.tokens.Refresh(ctx, accessKey); err != nil {
+					lastErr = fmt.Errorf("failed to re-authenticate for %s: %w", method, err)
+				} else {
+					attempt-- // Повтор сразу после обновления ключа не считается попыткой
+					continue
+				}
+			}
 		}
-		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("accessKey", c.accessKey)
 
-		resp, err := c.httpClient.Do(req)
-		if err != nil {
-			errMessage := fmt.Sprintf("failed to send request for %s: %v", method, err)
-			allErrors = append(allErrors, errMessage)
-			continue
+		if !c.retry.shouldRetry(attempt, lastErr) || ctx.Err() != nil {
+			break
 		}
+	}
 
-		body, err := io.ReadAll(resp.Body)
-		resp.Body.Close()
-		if err != nil {
-			errMessage := fmt.Sprintf("failed to read response for %s: %v", method, err)
-			allErrors = append(allErrors, errMessage)
-			continue
-		}
+	if lastErr != nil && IsRetryable(lastErr) {
+		return nil, fmt.Errorf("all retry attempts failed for %s: %w", method, lastErr)
+	}
+	return nil, lastErr
+}
 
-		if resp.StatusCode == http.StatusUnauthorized {
-			c.logger.Info("Token expired, re-authenticating", zap.String("method", method))
-			allErrors = append(allErrors, "token expired (401)")
-			c.accessKey = ""
-			if err := c.Authenticate(ctx); err != nil {
-				errMessage := fmt.Sprintf("failed to re-authenticate for %s: %v", method, err)
-				allErrors = append(allErrors, errMessage)
-				continue
-			}
-			attempt-- // Retry the current request immediately after re-authenticating
-			continue
-		}
+// send выполняет одну попытку запроса. Неуспешный HTTP статус возвращается как *APIError.
+func (c *Client) send(ctx context.Context, method, url string, payload []byte, accessKey string) ([]byte, error) {
+	if err := c.limiter.Wait(ctx, method); err != nil {
+		return nil, err
+	}
 
-		if resp.StatusCode != http.StatusOK {
-			errMessage := fmt.Sprintf("request %s failed with status %d: %s", method, resp.StatusCode, string(body))
-			allErrors = append(allErrors, errMessage)
-			continue
-		}
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for %s: %w", method, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("accessKey", accessKey)
 
-		responseBody = body
-		return responseBody, nil // Success, exit loop
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request for %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response for %s: %w", method, err)
 	}
 
-	return nil, fmt.Errorf("all retry attempts failed for %s. Errors: [%s]", method, strings.Join(allErrors, "; "))
+	if resp.StatusCode != http.StatusOK {
+		return nil, &APIError{
+			StatusCode: resp.StatusCode,
+			Method:     method,
+			Body:       string(body),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+	}
+	return body, nil
 }
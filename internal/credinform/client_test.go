@@ -0,0 +1,43 @@
+package credinform
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCompanyInformationRequestIncludesParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		extra    map[string]interface{}
+		expected string
+	}{
+		{
+			name:     "no_params",
+			expected: `{"companyId":"company-id","language":"Russian"}`,
+		},
+		{
+			// Параметры метода передаются рядом с companyId и language
+			name:     "method_params",
+			extra:    map[string]interface{}{"affiliationTypes": []string{"ByManagingLegalPersons"}},
+			expected: `{"affiliationTypes":["ByManagingLegalPersons"],"companyId":"company-id","language":"Russian"}`,
+		},
+		{
+			// Параметры не заменяют идентификатор компании
+			name:     "reserved_fields",
+			extra:    map[string]interface{}{"companyId": "other"},
+			expected: `{"companyId":"company-id","language":"Russian"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(CompanyInformationRequest{CompanyID: "company-id", Language: "Russian", Extra: tt.extra})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("expected request %s, got %s", tt.expected, data)
+			}
+		})
+	}
+}
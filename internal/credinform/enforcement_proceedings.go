@@ -0,0 +1,29 @@
+package credinform
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"scoring_worker/internal/credinform/types"
+)
+
+type EnforcementProceedingsParams struct{}
+
+func (c *Client) GetEnforcementProceedings(ctx context.Context, companyID string, params EnforcementProceedingsParams) (*types.EnforcementProceedings, error) {
+	body, err := c.getCompanyData(ctx, "CompanyInformation/EnforcementProceedings", companyID, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get enforcement proceedings: %w", err)
+	}
+
+	var response struct {
+		Data types.EnforcementProceedingsResponse `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal enforcement proceedings response: %w", err)
+	}
+	if response.Data.ProceedingList == nil {
+		response.Data.ProceedingList = []*types.EnforcementProceeding{}
+	}
+
+	return &response.Data, nil
+}
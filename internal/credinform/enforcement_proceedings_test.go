@@ -0,0 +1,74 @@
+package credinform
+
+import (
+	"context"
+	"testing"
+
+	"scoring_worker/internal/credinform/types"
+)
+
+func TestGetEnforcementProceedings(t *testing.T) {
+	tests := []struct {
+		name          string
+		response      string
+		expected      []types.EnforcementProceeding
+		expectedError bool
+	}{
+		{
+			name: "proceedings",
+			response: `{"data":{"enforcementProceedingList":[
+				{"proceedingNumber":"1234/24/77001-ИП","startDate":"2024-02-01T00:00:00","status":"Open",
+				 "subject":"Налоги и сборы","amount":150000.5,"remainingDebt":100000,
+				 "bailiffDepartment":"ОСП по ЦАО №1","executiveDocument":"Акт органа, осуществляющего контрольные функции"},
+				{"proceedingNumber":"99/23/77001-ИП","endDate":"2023-12-01T00:00:00","status":"Closed","amount":5000,"remainingDebt":0}
+			]}}`,
+			expected: []types.EnforcementProceeding{
+				{
+					Number:            ptr("1234/24/77001-ИП"),
+					StartDate:         ptr("2024-02-01T00:00:00"),
+					Status:            ptr(types.EnforcementProceedingStatusOpen),
+					Subject:           ptr("Налоги и сборы"),
+					Amount:            ptr(150000.5),
+					RemainingDebt:     ptr(100000.0),
+					Department:        ptr("ОСП по ЦАО №1"),
+					ExecutiveDocument: ptr("Акт органа, осуществляющего контрольные функции"),
+				},
+				{
+					Number:        ptr("99/23/77001-ИП"),
+					EndDate:       ptr("2023-12-01T00:00:00"),
+					Status:        ptr(types.EnforcementProceedingStatusClosed),
+					Amount:        ptr(5000.0),
+					RemainingDebt: ptr(0.0),
+				},
+			},
+		},
+		{
+			name:     "no_proceedings",
+			response: `{"data":{}}`,
+			expected: []types.EnforcementProceeding{},
+		},
+		{
+			name:          "malformed_amount",
+			response:      `{"data":{"enforcementProceedingList":[{"amount":"много"}]}}`,
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := serveCompanyInformation(t, "CompanyInformation/EnforcementProceedings", tt.response, nil)
+			proceedings, err := client.GetEnforcementProceedings(context.Background(), "company-id", EnforcementProceedingsParams{})
+			if tt.expectedError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			assertItems(t, "proceedings", proceedings.ProceedingList, tt.expected)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package credinform
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+var (
+	// ErrCompanyNotFound возвращается SearchCompany, если по ИНН не найдено ни одной компании
+	ErrCompanyNotFound = errors.New("company not found")
+	// ErrUnauthorized означает, что Credinform отклонил учётные данные или ключ доступа
+	ErrUnauthorized = errors.New("credinform unauthorized")
+	// ErrRateLimited означает, что превышен лимит запросов к Credinform
+	ErrRateLimited = errors.New("credinform rate limit exceeded")
+)
+
+// APIError описывает ответ Credinform с неуспешным HTTP статусом.
+// Сопоставляется с ErrUnauthorized и ErrRateLimited через errors.Is.
+type APIError struct {
+	StatusCode int
+	Method     string
+	Body       string
+	// RetryAfter — задержка из заголовка Retry-After, если сервер её указал
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("request %s failed with status %d: %s", e.Method, e.StatusCode, e.Body)
+}
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// IsRetryable сообщает, имеет ли смысл повторить операцию позже: превышение
+// лимита, ошибки сервера и сетевые сбои считаются временными, а отсутствие
+// компании, отказ в доступе и прочие ошибки запроса — окончательными.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, ErrCompanyNotFound) || errors.Is(err, ErrUnauthorized) {
+		return false
+	}
+	if errors.Is(err, ErrRateLimited) {
+		return true
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF)
+}
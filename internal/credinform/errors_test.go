@@ -0,0 +1,75 @@
+package credinform
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+)
+
+func TestAPIErrorIs(t *testing.T) {
+	tests := []struct {
+		name           string
+		statusCode     int
+		isUnauthorized bool
+		isRateLimited  bool
+	}{
+		{name: "unauthorized", statusCode: 401, isUnauthorized: true},
+		{name: "forbidden", statusCode: 403, isUnauthorized: true},
+		{name: "rate_limited", statusCode: 429, isRateLimited: true},
+		{name: "server_error", statusCode: 500},
+		{name: "bad_request", statusCode: 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Ошибка должна распознаваться и после оборачивания
+			err := fmt.Errorf("failed to get basic information: %w", &APIError{StatusCode: tt.statusCode, Method: "CompanyInformation/BasicInformation"})
+
+			if errors.Is(err, ErrUnauthorized) != tt.isUnauthorized {
+				t.Errorf("expected errors.Is(err, ErrUnauthorized) = %v", tt.isUnauthorized)
+			}
+			if errors.Is(err, ErrRateLimited) != tt.isRateLimited {
+				t.Errorf("expected errors.Is(err, ErrRateLimited) = %v", tt.isRateLimited)
+			}
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.statusCode {
+				t.Errorf("expected errors.As to find APIError with status %d", tt.statusCode)
+			}
+		})
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "nil", err: nil, expected: false},
+		{name: "company_not_found", err: fmt.Errorf("search: %w", ErrCompanyNotFound), expected: false},
+		{name: "unauthorized", err: &APIError{StatusCode: 401}, expected: false},
+		{name: "bad_request", err: &APIError{StatusCode: 400}, expected: false},
+		{name: "rate_limited", err: &APIError{StatusCode: 429}, expected: true},
+		{name: "server_error", err: &APIError{StatusCode: 502}, expected: true},
+		{name: "network_error", err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}, expected: true},
+		{name: "deadline_exceeded", err: context.DeadlineExceeded, expected: true},
+		{name: "canceled", err: context.Canceled, expected: false},
+		{
+			name:     "joined_attempts",
+			err:      fmt.Errorf("all retry attempts failed: %w", errors.Join(&APIError{StatusCode: 503}, &APIError{StatusCode: 500})),
+			expected: true,
+		},
+		{name: "unknown", err: errors.New("unexpected"), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.expected {
+				t.Errorf("expected IsRetryable(%v) = %v, got %v", tt.err, tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package fakecredinform
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Fault описывает сбой, который сервер воспроизводит вместо обычного ответа
+type Fault struct {
+	// Method — метод API, например "Search/SearchCompany"; пустой затрагивает все методы
+	Method string
+	// Status — HTTP статус ответа. Ответ 401 также отзывает предъявленный ключ доступа,
+	// как при истечении его срока.
+	Status int
+	// RetryAfter — значение заголовка Retry-After
+	RetryAfter string
+	// Delay — задержка перед ответом
+	Delay time.Duration
+	// Malformed — ответить 200 с некорректным JSON
+	Malformed bool
+	// Times — сколько запросов затронуть; 0 — все последующие
+	Times int
+}
+
+// ParseFault разбирает сбой в формате <вид>[@<метод>][*<раз>], где вид — HTTP статус
+// (например 401, 429, 503), "delay:<длительность>" или "malformed". Для 429 и 503
+// можно указать Retry-After через двоеточие: "429:5".
+//
+// Примеры: "503@Search/SearchCompany*2", "delay:3s", "malformed@CompanyInformation/Activities*1".
+func ParseFault(spec string) (Fault, error) {
+	var f Fault
+	rest := spec
+
+	if i := strings.LastIndex(rest, "*"); i >= 0 {
+		times, err := strconv.Atoi(rest[i+1:])
+		if err != nil || times <= 0 {
+			return Fault{}, fmt.Errorf("invalid fault %q: times must be a positive number", spec)
+		}
+		f.Times = times
+		rest = rest[:i]
+	}
+	if kind, method, ok := strings.Cut(rest, "@"); ok {
+		if method == "" {
+			return Fault{}, fmt.Errorf("invalid fault %q: method is empty", spec)
+		}
+		f.Method = method
+		rest = kind
+	}
+
+	kind, arg, hasArg := strings.Cut(rest, ":")
+	switch kind {
+	case "delay":
+		d, err := time.ParseDuration(arg)
+		if err != nil || d <= 0 {
+			return Fault{}, fmt.Errorf("invalid fault %q: delay must be a positive duration", spec)
+		}
+		f.Delay = d
+	case "malformed":
+		if hasArg {
+			return Fault{}, fmt.Errorf("invalid fault %q: malformed takes no argument", spec)
+		}
+		f.Malformed = true
+	default:
+		status, err := strconv.Atoi(kind)
+		if err != nil || status < 400 || status > 599 {
+			return Fault{}, fmt.Errorf("invalid fault %q: unknown kind %q", spec, kind)
+		}
+		f.Status = status
+		if hasArg {
+			if status != http.StatusTooManyRequests && status != http.StatusServiceUnavailable {
+				return Fault{}, fmt.Errorf("invalid fault %q: Retry-After is supported for 429 and 503 only", spec)
+			}
+			f.RetryAfter = arg
+		}
+	}
+	return f, nil
+}
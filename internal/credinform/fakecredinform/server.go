@@ -0,0 +1,348 @@
+// Package fakecredinform реализует поддельный Credinform API для тестов и
+// локальной разработки без учётных данных и доступа к сети. Ответы берутся из
+// каталога фикстур, сбои задаются сценарием через Inject или управляющие запросы.
+//
+// Структура каталога фикстур:
+//
+//	search/<ИНН>.json                   — ответ Search/SearchCompany
+//	companies/<companyId>/<Метод>.json  — ответ CompanyInformation/<Метод>
+//
+// Для ИНН без фикстуры поиск возвращает пустой список компаний.
+package fakecredinform
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	authMethod   = "Authorization/GetAccessKey"
+	searchMethod = "Search/SearchCompany"
+	dataPrefix   = "CompanyInformation/"
+
+	// controlPrefix — управляющие запросы сценария сбоев, не входящие в API Credinform
+	controlPrefix = "/_fake/"
+)
+
+// Server — поддельный Credinform API. Реализует http.Handler и может использоваться
+// с httptest.NewServer.
+type Server struct {
+	fixtures    fs.FS
+	username    string
+	password    string
+	keyLifetime time.Duration
+	now         func() time.Time
+
+	mu     sync.Mutex
+	keys   map[string]time.Time
+	keySeq int
+	faults []*Fault
+	calls  map[string]int
+}
+
+// Option настраивает Server при создании
+type Option func(*Server)
+
+// WithCredentials задаёт учётные данные, которые принимает авторизация.
+// Без этой опции принимаются любые.
+func WithCredentials(username, password string) Option {
+	return func(s *Server) {
+		s.username = username
+		s.password = password
+	}
+}
+
+// WithKeyLifetime задаёт срок действия выдаваемых ключей доступа.
+// По истечении срока запросы с ключом получают 401.
+func WithKeyLifetime(d time.Duration) Option {
+	return func(s *Server) {
+		s.keyLifetime = d
+	}
+}
+
+// New создаёт сервер, отвечающий данными из fixtures
+func New(fixtures fs.FS, opts ...Option) *Server {
+	s := &Server{
+		fixtures: fixtures,
+		now:      time.Now,
+		keys:     make(map[string]time.Time),
+		calls:    make(map[string]int),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Inject добавляет сбой в сценарий. Сбои применяются в порядке добавления.
+func (s *Server) Inject(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fault := f
+	s.faults = append(s.faults, &fault)
+}
+
+// ClearFaults удаляет все сбои сценария
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// ExpireKeys отзывает все выданные ключи доступа
+func (s *Server) ExpireKeys() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.keys)
+}
+
+// Calls возвращает число запросов к методу API, например "Search/SearchCompany"
+func (s *Server) Calls(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[method]
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, controlPrefix) {
+		s.serveControl(w, r)
+		return
+	}
+
+	method, ok := strings.CutPrefix(r.URL.Path, "/api/")
+	if !ok || r.Method != http.MethodPost {
+		writeError(w, http.StatusNotFound, "unknown endpoint")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "failed to read request")
+		return
+	}
+
+	// Сбой расходуется только запросом, который дошёл бы до метода: неизвестный
+	// метод и недействительный ключ отвечают как обычно, не затрагивая сценарий
+	if method != authMethod && method != searchMethod && !strings.HasPrefix(method, dataPrefix) {
+		writeError(w, http.StatusNotFound, "unknown method "+method)
+		return
+	}
+	s.countCall(method)
+	if method != authMethod && !s.validKey(r.Header.Get("accessKey")) {
+		writeError(w, http.StatusUnauthorized, "access key is invalid or expired")
+		return
+	}
+
+	fault := s.takeFault(method)
+	if fault != nil && fault.Delay > 0 {
+		select {
+		case <-time.After(fault.Delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if fault != nil && fault.Status != 0 {
+		if fault.Status == http.StatusUnauthorized {
+			s.revokeKey(r.Header.Get("accessKey"))
+		}
+		if fault.RetryAfter != "" {
+			w.Header().Set("Retry-After", fault.RetryAfter)
+		}
+		writeError(w, fault.Status, fmt.Sprintf("injected fault: status %d", fault.Status))
+		return
+	}
+	if fault != nil && fault.Malformed {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"companyId": `))
+		return
+	}
+
+	switch {
+	case method == authMethod:
+		s.serveAuth(w, body)
+	case method == searchMethod:
+		s.serveSearch(w, body)
+	default:
+		s.serveCompanyData(w, strings.TrimPrefix(method, dataPrefix), body)
+	}
+}
+
+func (s *Server) countCall(method string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls[method]++
+}
+
+// takeFault возвращает первый подходящий методу сбой сценария и расходует его
+func (s *Server) takeFault(method string) *Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, f := range s.faults {
+		if f.Method != "" && f.Method != method {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+func (s *Server) validKey(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	issuedAt, ok := s.keys[key]
+	if !ok {
+		return false
+	}
+	if s.keyLifetime > 0 && s.now().Sub(issuedAt) >= s.keyLifetime {
+		delete(s.keys, key)
+		return false
+	}
+	return true
+}
+
+func (s *Server) revokeKey(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.keys, key)
+}
+
+func (s *Server) serveAuth(w http.ResponseWriter, body []byte) {
+	var req struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid auth request")
+		return
+	}
+	if s.username != "" && (req.Username != s.username || req.Password != s.password) {
+		writeJSON(w, map[string]string{"accessKey": "", "error": "invalid username or password"})
+		return
+	}
+
+	s.mu.Lock()
+	s.keySeq++
+	key := fmt.Sprintf("fake-access-key-%d", s.keySeq)
+	s.keys[key] = s.now()
+	s.mu.Unlock()
+
+	writeJSON(w, map[string]string{"accessKey": key})
+}
+
+func (s *Server) serveSearch(w http.ResponseWriter, body []byte) {
+	var req struct {
+		SearchCompanyParameters struct {
+			TaxNumber string `json:"taxNumber"`
+		} `json:"searchCompanyParameters"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid search request")
+		return
+	}
+
+	data, err := s.readFixture("search", req.SearchCompanyParameters.TaxNumber+".json")
+	if errors.Is(err, fs.ErrNotExist) {
+		writeJSON(w, map[string]any{"companyDataList": []any{}, "commentRu": "Компания не найдена", "commentEn": "Company not found"})
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeRaw(w, data)
+}
+
+func (s *Server) serveCompanyData(w http.ResponseWriter, name string, body []byte) {
+	var req struct {
+		CompanyID string `json:"companyId"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil || req.CompanyID == "" {
+		writeError(w, http.StatusBadRequest, "companyId is required")
+		return
+	}
+
+	data, err := s.readFixture("companies", req.CompanyID, name+".json")
+	if errors.Is(err, fs.ErrNotExist) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no %s data for company %s", name, req.CompanyID))
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeRaw(w, data)
+}
+
+// readFixture читает файл фикстуры, не допуская выхода за пределы каталога
+func (s *Server) readFixture(elem ...string) ([]byte, error) {
+	for _, e := range elem {
+		if e == "" || strings.ContainsAny(e, `/\`) || e == ".." {
+			return nil, fs.ErrNotExist
+		}
+	}
+	return fs.ReadFile(s.fixtures, path.Join(elem...))
+}
+
+// serveControl обрабатывает управляющие запросы сценария:
+//
+//	POST   /_fake/faults       — добавить сбой, тело в формате ParseFault
+//	DELETE /_fake/faults       — удалить все сбои
+//	POST   /_fake/expire-keys  — отозвать все ключи доступа
+func (s *Server) serveControl(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == controlPrefix+"faults" && r.Method == http.MethodPost:
+		spec, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "failed to read fault")
+			return
+		}
+		fault, err := ParseFault(strings.TrimSpace(string(spec)))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.Inject(fault)
+	case r.URL.Path == controlPrefix+"faults" && r.Method == http.MethodDelete:
+		s.ClearFaults()
+	case r.URL.Path == controlPrefix+"expire-keys" && r.Method == http.MethodPost:
+		s.ExpireKeys()
+	default:
+		writeError(w, http.StatusNotFound, "unknown control endpoint")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	data, _ := json.Marshal(v)
+	writeRaw(w, data)
+}
+
+func writeRaw(w http.ResponseWriter, data []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	data, _ := json.Marshal(map[string]string{"error": message})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(data)
+}
@@ -0,0 +1,322 @@
+package fakecredinform_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"scoring_worker/internal/config"
+	"scoring_worker/internal/credinform"
+	"scoring_worker/internal/credinform/fakecredinform"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func newClient(t *testing.T, baseURL string, password string) *credinform.Client {
+	t.Helper()
+
+	client := credinform.NewClient(&config.CredinformConfig{
+		BaseURL:       baseURL,
+		Username:      "user",
+		Password:      base64.StdEncoding.EncodeToString([]byte(password)),
+		Timeout:       5,
+		RetryAttempts: 2,
+	}, zaptest.NewLogger(t))
+	if client == nil {
+		t.Fatal("failed to create client")
+	}
+	return client
+}
+
+func TestServerFixtures(t *testing.T) {
+	fake := fakecredinform.New(os.DirFS("testdata"), fakecredinform.WithCredentials("user", "secret"))
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	client := newClient(t, srv.URL, "secret")
+	ctx := context.Background()
+
+	company, err := client.SearchCompany(ctx, "7700000001")
+	if err != nil {
+		t.Fatalf("unexpected search error: %v", err)
+	}
+	if company.CompanyID != "fake-0001" {
+		t.Errorf("expected company fake-0001, got %s", company.CompanyID)
+	}
+
+	info, err := client.GetBasicInformation(ctx, company.CompanyID, credinform.BasicInformationParams{})
+	if err != nil {
+		t.Fatalf("unexpected basic information error: %v", err)
+	}
+	if info.TaxNumber == nil || *info.TaxNumber != "7700000001" {
+		t.Errorf("expected basic information for 7700000001, got %+v", info)
+	}
+
+	if _, err := client.SearchCompany(ctx, "7700000002"); !errors.Is(err, credinform.ErrCompanyNotFound) {
+		t.Errorf("expected ErrCompanyNotFound for unknown INN, got %v", err)
+	}
+
+	// Для данных без фикстуры сервер отвечает 404
+	_, err = client.GetAffiliatedCompanies(ctx, company.CompanyID, credinform.AffiliatedCompaniesParams{})
+	var apiErr *credinform.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for missing fixture, got %v", err)
+	}
+}
+
+func TestServerDataFixtures(t *testing.T) {
+	fake := fakecredinform.New(os.DirFS("testdata"))
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	client := newClient(t, srv.URL, "secret")
+	ctx := context.Background()
+
+	// Фикстуры есть для всех методов, которые воркер запрашивает по умолчанию
+	tests := []struct {
+		name  string
+		fetch func() error
+	}{
+		{name: "arbitrage_cases", fetch: func() error {
+			_, err := client.GetArbitrageCases(ctx, "fake-0001", credinform.ArbitrageCasesParams{})
+			return err
+		}},
+		{name: "state_contracts", fetch: func() error {
+			_, err := client.GetStateContracts(ctx, "fake-0001", credinform.StateContractsParams{})
+			return err
+		}},
+		{name: "enforcement_proceedings", fetch: func() error {
+			_, err := client.GetEnforcementProceedings(ctx, "fake-0001", credinform.EnforcementProceedingsParams{})
+			return err
+		}},
+		{name: "bankruptcy_messages", fetch: func() error {
+			_, err := client.GetBankruptcyMessages(ctx, "fake-0001", credinform.BankruptcyMessagesParams{})
+			return err
+		}},
+		{name: "licenses", fetch: func() error {
+			_, err := client.GetLicenses(ctx, "fake-0001", credinform.LicensesParams{})
+			return err
+		}},
+		{name: "pledges_and_leasing", fetch: func() error {
+			_, err := client.GetPledgesAndLeasing(ctx, "fake-0001", credinform.PledgesAndLeasingParams{})
+			return err
+		}},
+		{name: "financial_statements", fetch: func() error {
+			_, err := client.GetFinancialStatements(ctx, "fake-0001", credinform.FinancialStatementsParams{})
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.fetch(); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestServerRejectsInvalidCredentials(t *testing.T) {
+	fake := fakecredinform.New(os.DirFS("testdata"), fakecredinform.WithCredentials("user", "secret"))
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	client := newClient(t, srv.URL, "wrong")
+	if _, err := client.SearchCompany(context.Background(), "7700000001"); !errors.Is(err, credinform.ErrUnauthorized) {
+		t.Errorf("expected ErrUnauthorized, got %v", err)
+	}
+}
+
+func TestServerFaults(t *testing.T) {
+	tests := []struct {
+		name               string
+		fault              string
+		expectedErr        string
+		expectedAuthCalls  int
+		expectedSearchCall int
+	}{
+		{
+			// Отозванный ключ обновляется клиентом, запрос повторяется
+			name:               "expired_key",
+			fault:              "401@Search/SearchCompany*1",
+			expectedAuthCalls:  2,
+			expectedSearchCall: 2,
+		},
+		{
+			name:               "rate_limited_once",
+			fault:              "429:0@Search/SearchCompany*1",
+			expectedAuthCalls:  1,
+			expectedSearchCall: 2,
+		},
+		{
+			name:               "server_errors",
+			fault:              "503@Search/SearchCompany",
+			expectedErr:        "status 503",
+			expectedAuthCalls:  1,
+			expectedSearchCall: 3,
+		},
+		{
+			name:               "malformed_response",
+			fault:              "malformed@Search/SearchCompany*1",
+			expectedErr:        "failed to unmarshal search response",
+			expectedAuthCalls:  1,
+			expectedSearchCall: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := fakecredinform.New(os.DirFS("testdata"))
+			srv := httptest.NewServer(fake)
+			defer srv.Close()
+
+			fault, err := fakecredinform.ParseFault(tt.fault)
+			if err != nil {
+				t.Fatalf("unexpected fault parse error: %v", err)
+			}
+			fake.Inject(fault)
+
+			client := newClient(t, srv.URL, "secret")
+			_, err = client.SearchCompany(context.Background(), "7700000001")
+
+			if tt.expectedErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tt.expectedErr != "" && (err == nil || !strings.Contains(err.Error(), tt.expectedErr)) {
+				t.Errorf("expected error containing %q, got %v", tt.expectedErr, err)
+			}
+			if calls := fake.Calls("Authorization/GetAccessKey"); calls != tt.expectedAuthCalls {
+				t.Errorf("expected %d auth calls, got %d", tt.expectedAuthCalls, calls)
+			}
+			if calls := fake.Calls("Search/SearchCompany"); calls != tt.expectedSearchCall {
+				t.Errorf("expected %d search calls, got %d", tt.expectedSearchCall, calls)
+			}
+		})
+	}
+}
+
+func TestServerFaultNotConsumedByRejectedRequests(t *testing.T) {
+	fake := fakecredinform.New(os.DirFS("testdata"))
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	client := newClient(t, srv.URL, "secret")
+	if _, err := client.SearchCompany(context.Background(), "7700000001"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Неизвестный метод не расходует сбой, предназначенный для всех методов
+	fake.Inject(fakecredinform.Fault{Status: http.StatusServiceUnavailable, Times: 1})
+	resp, err := http.Post(srv.URL+"/api/Unknown/Method", "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown method, got %d", resp.StatusCode)
+	}
+	fake.ClearFaults()
+
+	// Запрос с отозванным ключом получает 401, сбой достаётся повтору после авторизации
+	fake.ExpireKeys()
+	fake.Inject(fakecredinform.Fault{Method: "Search/SearchCompany", Status: http.StatusServiceUnavailable, Times: 1})
+	if _, err := client.SearchCompany(context.Background(), "7700000001"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Поиск: первый успешный, отклонённый ключ, сбой 503 и успешный повтор
+	if calls := fake.Calls("Search/SearchCompany"); calls != 4 {
+		t.Errorf("expected 4 search calls, got %d", calls)
+	}
+	if calls := fake.Calls("Unknown/Method"); calls != 0 {
+		t.Errorf("expected unknown method not to be counted, got %d", calls)
+	}
+}
+
+func TestServerDelayHonoursClientContext(t *testing.T) {
+	fake := fakecredinform.New(os.DirFS("testdata"))
+	fake.Inject(fakecredinform.Fault{Method: "Search/SearchCompany", Delay: time.Minute})
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	client := newClient(t, srv.URL, "secret")
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if _, err := client.SearchCompany(ctx, "7700000001"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded for slow response, got %v", err)
+	}
+}
+
+func TestServerControlEndpoints(t *testing.T) {
+	fake := fakecredinform.New(os.DirFS("testdata"))
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/_fake/faults", "text/plain", strings.NewReader("500@Search/SearchCompany"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected 204 for fault injection, got %d", resp.StatusCode)
+	}
+
+	client := newClient(t, srv.URL, "secret")
+	if _, err := client.SearchCompany(context.Background(), "7700000001"); err == nil {
+		t.Fatal("expected injected fault to fail the search")
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, srv.URL+"/_fake/faults", nil)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if _, err := client.SearchCompany(context.Background(), "7700000001"); err != nil {
+		t.Errorf("expected search to succeed after faults are cleared, got %v", err)
+	}
+}
+
+func TestParseFault(t *testing.T) {
+	tests := []struct {
+		spec          string
+		expected      fakecredinform.Fault
+		expectedError bool
+	}{
+		{spec: "503", expected: fakecredinform.Fault{Status: 503}},
+		{spec: "401@Search/SearchCompany*2", expected: fakecredinform.Fault{Method: "Search/SearchCompany", Status: 401, Times: 2}},
+		{spec: "429:5", expected: fakecredinform.Fault{Status: 429, RetryAfter: "5"}},
+		{spec: "delay:1500ms@CompanyInformation/Activities", expected: fakecredinform.Fault{Method: "CompanyInformation/Activities", Delay: 1500 * time.Millisecond}},
+		{spec: "malformed*1", expected: fakecredinform.Fault{Malformed: true, Times: 1}},
+		{spec: "200", expectedError: true},
+		{spec: "delay:soon", expectedError: true},
+		{spec: "500*0", expectedError: true},
+		{spec: "500:5", expectedError: true},
+		{spec: "timeout", expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			fault, err := fakecredinform.ParseFault(tt.spec)
+			if tt.expectedError {
+				if err == nil {
+					t.Errorf("expected error, got %+v", fault)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if fault != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, fault)
+			}
+		})
+	}
+}
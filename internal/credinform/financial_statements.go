@@ -0,0 +1,32 @@
+package credinform
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"scoring_worker/internal/credinform/types"
+)
+
+type FinancialStatementsParams struct {
+	Period struct {
+		From int `json:"from"`
+		To   int `json:"to"`
+	} `json:"period"`
+	Forms []string `json:"forms"`
+}
+
+func (c *Client) GetFinancialStatements(ctx context.Context, companyID string, params FinancialStatementsParams) (*types.FinancialStatements, error) {
+	body, err := c.getCompanyData(ctx, "CompanyInformation/AccountingStatements", companyID, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get financial statements: %w", err)
+	}
+
+	var response struct {
+		Data types.FinancialStatementsResponse `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal financial statements response: %w", err)
+	}
+
+	return types.NewFinancialStatements(&response.Data), nil
+}
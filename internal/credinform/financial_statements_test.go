@@ -0,0 +1,69 @@
+package credinform
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetFinancialStatements(t *testing.T) {
+	var gotRequest map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/Authorization/GetAccessKey" {
+			json.NewEncoder(w).Encode(AuthResponse{AccessKey: "key"})
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &gotRequest)
+		w.Write([]byte(`{"data":{"statementList":[
+			{"year":2024,"form":"ProfitAndLoss","lines":[{"code":"2110","value":1500},{"code":"2400","value":120}]},
+			{"year":2023,"form":"BalanceSheet","lines":[{"code":"1600","value":900},{"code":"1300","value":-50},{"code":"1150"},{"code":"1361","value":10}]},
+			{"year":2024,"form":"BalanceSheet","lines":[{"code":"1600","value":1000}]},
+			{"year":2024,"form":"CashFlow","lines":[{"code":"4100","value":1}]}
+		]}}`))
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv.URL)
+	params := FinancialStatementsParams{Forms: []string{"BalanceSheet", "ProfitAndLoss"}}
+	params.Period.From, params.Period.To = 2023, 2024
+
+	statements, err := client.GetFinancialStatements(context.Background(), "company-id", params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if period, ok := gotRequest["period"].(map[string]interface{}); !ok || period["from"] != float64(2023) {
+		t.Errorf("expected period to be sent to Credinform, got request %v", gotRequest)
+	}
+
+	// Годы упорядочены, формы одного года объединены
+	if len(statements.Years) != 2 || statements.Years[0].Year != 2023 || statements.Years[1].Year != 2024 {
+		t.Fatalf("expected years 2023 and 2024, got %+v", statements.Years)
+	}
+
+	y2023 := statements.Years[0]
+	if y2023.BalanceSheet == nil || *y2023.BalanceSheet.TotalAssets != 900 || *y2023.BalanceSheet.Equity != -50 {
+		t.Errorf("expected 2023 balance sheet lines to be mapped, got %+v", y2023.BalanceSheet)
+	}
+	if y2023.BalanceSheet.FixedAssets != nil {
+		t.Errorf("expected line without value to stay empty, got %v", *y2023.BalanceSheet.FixedAssets)
+	}
+	if y2023.BalanceSheet.OtherLines["1361"] != 10 {
+		t.Errorf("expected unmapped line 1361 to be kept, got %v", y2023.BalanceSheet.OtherLines)
+	}
+	if y2023.ProfitAndLoss != nil {
+		t.Errorf("expected no 2023 profit and loss, got %+v", y2023.ProfitAndLoss)
+	}
+
+	y2024 := statements.Years[1]
+	if y2024.ProfitAndLoss == nil || *y2024.ProfitAndLoss.Revenue != 1500 || *y2024.ProfitAndLoss.NetProfit != 120 {
+		t.Errorf("expected 2024 profit and loss lines to be mapped, got %+v", y2024.ProfitAndLoss)
+	}
+	if y2024.BalanceSheet == nil || *y2024.BalanceSheet.TotalAssets != 1000 {
+		t.Errorf("expected 2024 balance sheet lines to be mapped, got %+v", y2024.BalanceSheet)
+	}
+}
@@ -0,0 +1,32 @@
+package credinform
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"scoring_worker/internal/credinform/types"
+)
+
+type LicensesParams struct{}
+
+func (c *Client) GetLicenses(ctx context.Context, companyID string, params LicensesParams) (*types.Licenses, error) {
+	body, err := c.getCompanyData(ctx, "CompanyInformation/Licenses", companyID, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get licenses: %w", err)
+	}
+
+	var response struct {
+		Data types.LicensesResponse `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal licenses response: %w", err)
+	}
+	if response.Data.LicenseList == nil {
+		response.Data.LicenseList = []*types.License{}
+	}
+	if response.Data.SroMembershipList == nil {
+		response.Data.SroMembershipList = []*types.SroMembership{}
+	}
+
+	return &response.Data, nil
+}
@@ -0,0 +1,79 @@
+package credinform
+
+import (
+	"context"
+	"testing"
+
+	"scoring_worker/internal/credinform/types"
+)
+
+func TestGetLicenses(t *testing.T) {
+	tests := []struct {
+		name                   string
+		response               string
+		expectedLicenses       []types.License
+		expectedSroMemberships []types.SroMembership
+		expectedError          bool
+	}{
+		{
+			name: "licenses_and_sro",
+			response: `{"data":{
+				"licenseList":[
+					{"licenseNumber":"Л041-01137-77/00368","licensedActivity":"Медицинская деятельность",
+					 "issuingAuthority":"Росздравнадзор","startDate":"2020-03-01T00:00:00","status":"Active","okvedCodes":["86.10","86.21"]}
+				],
+				"sroMembershipList":[
+					{"sroName":"СРО «Строители»","sroRegistryNumber":"СРО-С-001-01","membershipNumber":"123",
+					 "startDate":"2019-01-01T00:00:00","endDate":"2023-01-01T00:00:00","status":"Terminated","okvedCodes":["41.20"]}
+				]
+			}}`,
+			expectedLicenses: []types.License{{
+				Number:           ptr("Л041-01137-77/00368"),
+				Activity:         ptr("Медицинская деятельность"),
+				IssuingAuthority: ptr("Росздравнадзор"),
+				StartDate:        ptr("2020-03-01T00:00:00"),
+				Status:           ptr(types.LicenseStatusActive),
+				ActivityCodes:    []string{"86.10", "86.21"},
+			}},
+			expectedSroMemberships: []types.SroMembership{{
+				SroName:           ptr("СРО «Строители»"),
+				SroRegistryNumber: ptr("СРО-С-001-01"),
+				MembershipNumber:  ptr("123"),
+				StartDate:         ptr("2019-01-01T00:00:00"),
+				EndDate:           ptr("2023-01-01T00:00:00"),
+				Status:            ptr(types.LicenseStatusTerminated),
+				ActivityCodes:     []string{"41.20"},
+			}},
+		},
+		{
+			name:                   "no_licenses",
+			response:               `{"data":{}}`,
+			expectedLicenses:       []types.License{},
+			expectedSroMemberships: []types.SroMembership{},
+		},
+		{
+			name:          "malformed_codes",
+			response:      `{"data":{"licenseList":[{"okvedCodes":"86.10"}]}}`,
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := serveCompanyInformation(t, "CompanyInformation/Licenses", tt.response, nil)
+			licenses, err := client.GetLicenses(context.Background(), "company-id", LicensesParams{})
+			if tt.expectedError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			assertItems(t, "licenses", licenses.LicenseList, tt.expectedLicenses)
+			assertItems(t, "SRO memberships", licenses.SroMembershipList, tt.expectedSroMemberships)
+		})
+	}
+}
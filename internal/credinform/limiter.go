@@ -0,0 +1,158 @@
+package credinform
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"scoring_worker/internal/config"
+
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+// globalBucket — имя общего бюджета запросов ко всем методам
+const globalBucket = "credinform"
+
+// Limiter ограничивает частоту запросов к методам Credinform
+type Limiter interface {
+	// Wait блокируется, пока запрос к method не уложится в лимиты, или до отмены ctx
+	Wait(ctx context.Context, method string) error
+}
+
+// bucketLimit — параметры одного бакета
+type bucketLimit struct {
+	name              string
+	requestsPerSecond float64
+	burst             int
+}
+
+// limits — бакеты из конфигурации: общий и по методам
+type limits struct {
+	global  *bucketLimit
+	methods map[string]*bucketLimit
+}
+
+func newLimits(cfg *config.RateLimitConfig) limits {
+	l := limits{methods: make(map[string]*bucketLimit)}
+	if cfg.RequestsPerSecond > 0 {
+		l.global = &bucketLimit{name: globalBucket, requestsPerSecond: cfg.RequestsPerSecond, burst: max(cfg.Burst, 1)}
+	}
+	for method, m := range cfg.Methods {
+		if m.RequestsPerSecond <= 0 {
+			continue
+		}
+		key := strings.ToLower(method)
+		l.methods[key] = &bucketLimit{name: globalBucket + ":" + key, requestsPerSecond: m.RequestsPerSecond, burst: max(m.Burst, 1)}
+	}
+	return l
+}
+
+// forMethod возвращает бакеты, через которые проходит запрос к method
+func (l limits) forMethod(method string) []*bucketLimit {
+	var result []*bucketLimit
+	if m, ok := l.methods[strings.ToLower(method)]; ok {
+		result = append(result, m)
+	}
+	if l.global != nil {
+		result = append(result, l.global)
+	}
+	return result
+}
+
+// localLimiter — token bucket в памяти процесса
+type localLimiter struct {
+	limits   limits
+	limiters map[string]*rate.Limiter
+}
+
+// NewLocalLimiter создаёт limiter, действующий в пределах одного процесса
+func NewLocalLimiter(cfg *config.RateLimitConfig) Limiter {
+	return newLocalLimiter(cfg)
+}
+
+func newLocalLimiter(cfg *config.RateLimitConfig) *localLimiter {
+	l := &localLimiter{limits: newLimits(cfg), limiters: make(map[string]*rate.Limiter)}
+	buckets := make([]*bucketLimit, 0, len(l.limits.methods)+1)
+	if l.limits.global != nil {
+		buckets = append(buckets, l.limits.global)
+	}
+	for _, b := range l.limits.methods {
+		buckets = append(buckets, b)
+	}
+	for _, b := range buckets {
+		l.limiters[b.name] = rate.NewLimiter(rate.Limit(b.requestsPerSecond), b.burst)
+	}
+	return l
+}
+
+func (l *localLimiter) Wait(ctx context.Context, method string) error {
+	return l.wait(ctx, method, l.limits.forMethod(method))
+}
+
+// wait забирает по токену из каждого бакета buckets
+func (l *localLimiter) wait(ctx context.Context, method string, buckets []*bucketLimit) error {
+	for _, b := range buckets {
+		if err := l.limiters[b.name].Wait(ctx); err != nil {
+			return fmt.Errorf("rate limit wait for %s: %w", method, err)
+		}
+	}
+	return nil
+}
+
+// TokenStore хранит общие бакеты для распределённого ограничения частоты
+type TokenStore interface {
+	// TakeToken забирает токен из бакета name. Возвращает 0, если токен получен,
+	// иначе время до появления следующего токена.
+	TakeToken(ctx context.Context, name string, requestsPerSecond float64, burst int) (time.Duration, error)
+}
+
+// distributedLimiter делит бакеты между репликами через TokenStore. Если хранилище
+// недоступно, запрос ограничивается локальным limiter, чтобы не останавливать обработку.
+type distributedLimiter struct {
+	store    TokenStore
+	limits   limits
+	fallback *localLimiter
+	logger   *zap.Logger
+}
+
+// NewDistributedLimiter создаёт limiter, общий для всех реплик, использующих store
+func NewDistributedLimiter(cfg *config.RateLimitConfig, store TokenStore, logger *zap.Logger) Limiter {
+	return &distributedLimiter{
+		store:    store,
+		limits:   newLimits(cfg),
+		fallback: newLocalLimiter(cfg),
+		logger:   logger,
+	}
+}
+
+func (l *distributedLimiter) Wait(ctx context.Context, method string) error {
+	buckets := l.limits.forMethod(method)
+	for i, b := range buckets {
+		for {
+			delay, err := l.store.TakeToken(ctx, b.name, b.requestsPerSecond, b.burst)
+			if err != nil {
+				if ctx.Err() != nil {
+					return fmt.Errorf("rate limit wait for %s: %w", method, ctx.Err())
+				}
+				l.logger.Warn("Distributed rate limiter unavailable, using local limits", zap.Error(err), zap.String("bucket", b.name))
+				// Токены предыдущих бакетов уже списаны в хранилище, локально
+				// ограничиваются только оставшиеся, чтобы не учесть запрос дважды
+				return l.fallback.wait(ctx, method, buckets[i:])
+			}
+			if delay <= 0 {
+				break
+			}
+			if err := wait(ctx, delay); err != nil {
+				return fmt.Errorf("rate limit wait for %s: %w", method, err)
+			}
+		}
+	}
+	return nil
+}
+
+// unlimited не ограничивает запросы
+type unlimited struct{}
+
+func (unlimited) Wait(ctx context.Context, method string) error { return nil }
@@ -0,0 +1,143 @@
+package credinform
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"scoring_worker/internal/config"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func TestLocalLimiterMethodBudgets(t *testing.T) {
+	limiter := NewLocalLimiter(&config.RateLimitConfig{
+		Methods: map[string]config.MethodRateLimit{
+			"Search/SearchCompany": {RequestsPerSecond: 20, Burst: 1},
+		},
+	})
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := limiter.Wait(ctx, "Search/SearchCompany"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("expected limited method to wait for tokens, took %s", elapsed)
+	}
+
+	// Методы без отдельного бюджета и без общего лимита не ограничиваются
+	start = time.Now()
+	for i := 0; i < 100; i++ {
+		if err := limiter.Wait(ctx, "CompanyInformation/BasicInformation"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("expected unlimited method not to wait, took %s", elapsed)
+	}
+}
+
+func TestLocalLimiterHonoursContext(t *testing.T) {
+	limiter := NewLocalLimiter(&config.RateLimitConfig{RequestsPerSecond: 0.1, Burst: 1})
+
+	if err := limiter.Wait(context.Background(), "Search/SearchCompany"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx, "Search/SearchCompany"); err == nil {
+		t.Error("expected error when the next token is not available before the deadline")
+	}
+}
+
+// fakeTokenStore выдаёт заданные задержки и запоминает запрошенные бакеты
+type fakeTokenStore struct {
+	mu      sync.Mutex
+	delays  []time.Duration
+	err     error
+	buckets []string
+	// failBucket — бакет, для которого хранилище возвращает err; пустой — все
+	failBucket string
+}
+
+func (s *fakeTokenStore) TakeToken(ctx context.Context, name string, requestsPerSecond float64, burst int) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buckets = append(s.buckets, name)
+	if s.err != nil && (s.failBucket == "" || s.failBucket == name) {
+		return 0, s.err
+	}
+	if len(s.delays) == 0 {
+		return 0, nil
+	}
+	delay := s.delays[0]
+	s.delays = s.delays[1:]
+	return delay, nil
+}
+
+func TestDistributedLimiter(t *testing.T) {
+	cfg := &config.RateLimitConfig{
+		RequestsPerSecond: 10,
+		Burst:             10,
+		Methods: map[string]config.MethodRateLimit{
+			"Search/SearchCompany": {RequestsPerSecond: 1, Burst: 1},
+		},
+	}
+
+	t.Run("waits_until_token_is_granted", func(t *testing.T) {
+		store := &fakeTokenStore{delays: []time.Duration{20 * time.Millisecond, 0}}
+		limiter := NewDistributedLimiter(cfg, store, zaptest.NewLogger(t))
+
+		start := time.Now()
+		if err := limiter.Wait(context.Background(), "Search/SearchCompany"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+			t.Errorf("expected limiter to wait for the store delay, took %s", elapsed)
+		}
+
+		expected := []string{"credinform:search/searchcompany", "credinform:search/searchcompany", "credinform"}
+		if len(store.buckets) != len(expected) {
+			t.Fatalf("expected buckets %v, got %v", expected, store.buckets)
+		}
+		for i := range expected {
+			if store.buckets[i] != expected[i] {
+				t.Errorf("expected buckets %v, got %v", expected, store.buckets)
+				break
+			}
+		}
+	})
+
+	t.Run("falls_back_to_local_limits", func(t *testing.T) {
+		store := &fakeTokenStore{err: errors.New("database is unavailable")}
+		limiter := NewDistributedLimiter(cfg, store, zaptest.NewLogger(t))
+
+		if err := limiter.Wait(context.Background(), "Search/SearchCompany"); err != nil {
+			t.Fatalf("expected fallback to local limiter, got %v", err)
+		}
+	})
+
+	t.Run("falls_back_only_for_remaining_buckets", func(t *testing.T) {
+		store := &fakeTokenStore{err: errors.New("database is unavailable"), failBucket: "credinform"}
+		limiter := NewDistributedLimiter(cfg, store, zaptest.NewLogger(t))
+
+		if err := limiter.Wait(context.Background(), "Search/SearchCompany"); err != nil {
+			t.Fatalf("expected fallback to local limiter, got %v", err)
+		}
+
+		// Токен бюджета метода взят в хранилище, локально списывается только общий
+		local := limiter.(*distributedLimiter).fallback.limiters
+		if tokens := local["credinform:search/searchcompany"].Tokens(); tokens < 0.99 {
+			t.Errorf("expected method bucket not to be charged locally, got %.2f tokens", tokens)
+		}
+		if tokens := local["credinform"].Tokens(); tokens > 9.01 {
+			t.Errorf("expected global bucket to be charged locally, got %.2f tokens", tokens)
+		}
+	})
+}
@@ -0,0 +1,106 @@
+package credinform
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// Значения по умолчанию, если в credinform.pagination не заданы размер страницы и предел
+const (
+	defaultPageSize = 100
+	defaultMaxPages = 10
+)
+
+// Paging — запрашиваемая страница списочного метода, нумерация с 1
+type Paging struct {
+	PageNumber int `json:"pageNumber"`
+	PageSize   int `json:"pageSize"`
+}
+
+// PageInfo — сведения о странице в ответе списочного метода
+type PageInfo struct {
+	PageNumber int  `json:"pageNumber"`
+	PageSize   int  `json:"pageSize"`
+	TotalCount *int `json:"totalCount,omitempty"`
+}
+
+// getPages запрашивает страницы списочного метода, пока они не закончатся или не будет
+// достигнут предел страниц: из credinform.pagination.methods для метода, иначе max_pages.
+// Каждая страница — отдельный запрос через ограничитель частоты и circuit breaker.
+// Возвращает элементы всех страниц из поля listField и признак того, что список
+// получен не полностью.
+func getPages[T any](ctx context.Context, c *Client, method, companyID string, params interface{}, listField string) ([]T, bool, error) {
+	pageSize, maxPages := c.config.Pagination.PageSize, c.config.Pagination.MaxPages
+	// Ключи из файла конфигурации viper приводит к нижнему регистру
+	for name, methodMaxPages := range c.config.Pagination.MethodMaxPages {
+		if strings.EqualFold(name, method) {
+			maxPages = methodMaxPages
+		}
+	}
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	if maxPages <= 0 {
+		maxPages = defaultMaxPages
+	}
+
+	pageParams, err := structToMap(params)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to convert params to map for %s: %w", method, err)
+	}
+	if pageParams == nil {
+		pageParams = make(map[string]interface{})
+	}
+
+	var items []T
+	for page := 1; ; page++ {
+		pageParams["paging"] = Paging{PageNumber: page, PageSize: pageSize}
+		body, err := c.getCompanyData(ctx, method, companyID, pageParams)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get page %d: %w", page, err)
+		}
+
+		var response struct {
+			Data map[string]json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(body, &response); err != nil {
+			return nil, false, fmt.Errorf("failed to unmarshal page %d: %w", page, err)
+		}
+		var pageItems []T
+		if raw, ok := response.Data[listField]; ok {
+			if err := json.Unmarshal(raw, &pageItems); err != nil {
+				return nil, false, fmt.Errorf("failed to unmarshal %s on page %d: %w", listField, page, err)
+			}
+		}
+		var info PageInfo
+		if raw, ok := response.Data["paging"]; ok {
+			if err := json.Unmarshal(raw, &info); err != nil {
+				return nil, false, fmt.Errorf("failed to unmarshal paging on page %d: %w", page, err)
+			}
+		}
+		items = append(items, pageItems...)
+
+		// Сервер может урезать размер страницы, поэтому неполная страница считается
+		// последней, только если он подтвердил запрошенный размер; иначе список
+		// заканчивается пустой страницей. Если известно общее число, оно точнее.
+		done := len(pageItems) == 0 || (info.PageSize == pageSize && len(pageItems) < pageSize)
+		if info.TotalCount != nil {
+			done = len(items) >= *info.TotalCount || len(pageItems) == 0
+		}
+		if done {
+			return items, false, nil
+		}
+		if page >= maxPages {
+			c.logger.Warn("Credinform list truncated by page limit",
+				zap.String("method", method),
+				zap.String("company_id", companyID),
+				zap.Int("max_pages", maxPages),
+				zap.Int("items", len(items)))
+			return items, true, nil
+		}
+	}
+}
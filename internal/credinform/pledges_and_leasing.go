@@ -0,0 +1,35 @@
+package credinform
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"scoring_worker/internal/credinform/types"
+)
+
+// PledgesAndLeasingParams — роли компании, для которых запрашиваются залоги и лизинг
+type PledgesAndLeasingParams struct {
+	ParticipationRoles []string `json:"participationRoles,omitempty"`
+}
+
+func (c *Client) GetPledgesAndLeasing(ctx context.Context, companyID string, params PledgesAndLeasingParams) (*types.PledgesAndLeasing, error) {
+	body, err := c.getCompanyData(ctx, "CompanyInformation/PledgesAndLeasing", companyID, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pledges and leasing: %w", err)
+	}
+
+	var response struct {
+		Data types.PledgesAndLeasingResponse `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal pledges and leasing response: %w", err)
+	}
+	if response.Data.PledgeList == nil {
+		response.Data.PledgeList = []*types.PledgeNotification{}
+	}
+	if response.Data.LeasingList == nil {
+		response.Data.LeasingList = []*types.LeasingContract{}
+	}
+
+	return &response.Data, nil
+}
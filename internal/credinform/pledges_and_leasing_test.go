@@ -0,0 +1,105 @@
+package credinform
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"scoring_worker/internal/credinform/types"
+)
+
+func TestGetPledgesAndLeasing(t *testing.T) {
+	tests := []struct {
+		name            string
+		response        string
+		expectedPledges []types.PledgeNotification
+		expectedLeasing []types.LeasingContract
+		expectedError   bool
+	}{
+		{
+			name: "pledges_and_leasing",
+			response: `{"data":{
+				"pledgeNotificationList":[
+					{"notificationNumber":"2024-001-123456-789","registrationDate":"2024-08-15T00:00:00","exclusionDate":"2025-02-01T00:00:00",
+					 "participationRole":"Pledgor","contractNumber":"КЛ-15","contractDate":"2024-08-01","propertyDescription":"Транспортные средства",
+					 "pledgorList":[{"name":"ООО Ромашка","taxNumber":"7700000001"}],
+					 "pledgeeList":[{"name":"ПАО Банк","taxNumber":"7707083893","registrationNumber":"1027700132195"}]}
+				],
+				"leasingContractList":[
+					{"contractNumber":"Л-1","contractDate":"2023-12-20","startDate":"2024-01-01","endDate":"2027-01-01","terminationDate":"2025-01-15",
+					 "participationRole":"Lessee","subject":"Погрузчик",
+					 "lessorList":[{"name":"ООО Лизинг"}],"lesseeList":[{"name":"ООО Ромашка","taxNumber":"7700000001"}]}
+				]
+			}}`,
+			expectedPledges: []types.PledgeNotification{
+				{
+					NotificationNumber: ptr("2024-001-123456-789"),
+					RegistrationDate:   ptr("2024-08-15T00:00:00"),
+					ExclusionDate:      ptr("2025-02-01T00:00:00"),
+					Role:               ptr(types.EncumbranceRolePledgor),
+					ContractNumber:     ptr("КЛ-15"),
+					ContractDate:       ptr("2024-08-01"),
+					Property:           ptr("Транспортные средства"),
+					PledgorList:        []*types.EncumbranceParticipant{{Name: ptr("ООО Ромашка"), TaxNumber: ptr("7700000001")}},
+					PledgeeList:        []*types.EncumbranceParticipant{{Name: ptr("ПАО Банк"), TaxNumber: ptr("7707083893"), RegistrationNumber: ptr("1027700132195")}},
+				},
+			},
+			expectedLeasing: []types.LeasingContract{
+				{
+					ContractNumber:  ptr("Л-1"),
+					ContractDate:    ptr("2023-12-20"),
+					StartDate:       ptr("2024-01-01"),
+					EndDate:         ptr("2027-01-01"),
+					TerminationDate: ptr("2025-01-15"),
+					Role:            ptr(types.EncumbranceRoleLessee),
+					Subject:         ptr("Погрузчик"),
+					LessorList:      []*types.EncumbranceParticipant{{Name: ptr("ООО Лизинг")}},
+					LesseeList:      []*types.EncumbranceParticipant{{Name: ptr("ООО Ромашка"), TaxNumber: ptr("7700000001")}},
+				},
+			},
+		},
+		{
+			name:            "no_encumbrances",
+			response:        `{"data":{}}`,
+			expectedPledges: []types.PledgeNotification{},
+			expectedLeasing: []types.LeasingContract{},
+		},
+		{
+			name:          "malformed_participants",
+			response:      `{"data":{"pledgeNotificationList":[{"pledgeeList":"ПАО Банк"}]}}`,
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roles := []string{types.EncumbranceRolePledgor, types.EncumbranceRoleLessee}
+			client := serveCompanyInformation(t, "CompanyInformation/PledgesAndLeasing", tt.response, func(body []byte) {
+				// Роли компании передаются на верхнем уровне запроса
+				var request struct {
+					ParticipationRoles []string `json:"participationRoles"`
+				}
+				if err := json.Unmarshal(body, &request); err != nil {
+					t.Errorf("unexpected request body: %v", err)
+				}
+				if !reflect.DeepEqual(request.ParticipationRoles, roles) {
+					t.Errorf("expected roles %v, got %v", roles, request.ParticipationRoles)
+				}
+			})
+			data, err := client.GetPledgesAndLeasing(context.Background(), "company-id", PledgesAndLeasingParams{ParticipationRoles: roles})
+			if tt.expectedError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			assertItems(t, "pledges", data.PledgeList, tt.expectedPledges)
+			assertItems(t, "leasing contracts", data.LeasingList, tt.expectedLeasing)
+		})
+	}
+}
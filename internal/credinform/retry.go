@@ -0,0 +1,94 @@
+package credinform
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"scoring_worker/internal/config"
+)
+
+// retryPolicy задаёт повторы запросов к Credinform: экспоненциальная задержка
+// с полным джиттером, ограниченная maxDelay. Retry-After из ответа сервера
+// имеет приоритет над вычисленной задержкой, но тоже не превышает maxDelay.
+type retryPolicy struct {
+	attempts  int
+	baseDelay time.Duration
+	maxDelay  time.Duration
+	// jitter возвращает случайное значение в [0, 1)
+	jitter func() float64
+}
+
+func newRetryPolicy(cfg *config.CredinformConfig) retryPolicy {
+	return retryPolicy{
+		attempts:  cfg.RetryAttempts,
+		baseDelay: time.Duration(cfg.RetryDelay) * time.Second,
+		maxDelay:  time.Duration(cfg.RetryMaxDelay) * time.Second,
+		jitter:    rand.Float64,
+	}
+}
+
+// shouldRetry сообщает, нужно ли повторять запрос после неудачной попытки attempt (с нуля)
+func (p retryPolicy) shouldRetry(attempt int, err error) bool {
+	return attempt < p.attempts && IsRetryable(err)
+}
+
+// delay возвращает задержку перед повтором номер retry (с единицы)
+func (p retryPolicy) delay(retry int, err error) time.Duration {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		if p.maxDelay > 0 {
+			return min(apiErr.RetryAfter, p.maxDelay)
+		}
+		return apiErr.RetryAfter
+	}
+
+	backoff := p.baseDelay
+	for i := 1; i < retry && backoff > 0; i++ {
+		backoff *= 2
+		if p.maxDelay > 0 && backoff >= p.maxDelay {
+			break
+		}
+	}
+	if p.maxDelay > 0 && backoff > p.maxDelay {
+		backoff = p.maxDelay
+	}
+	return time.Duration(p.jitter() * float64(backoff))
+}
+
+// wait ждёт d или отмены ctx
+func wait(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// parseRetryAfter разбирает заголовок Retry-After в виде числа секунд или HTTP-даты
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if d := date.Sub(now); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
@@ -0,0 +1,233 @@
+package credinform
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := retryPolicy{
+		baseDelay: time.Second,
+		maxDelay:  10 * time.Second,
+		jitter:    func() float64 { return 1 },
+	}
+
+	tests := []struct {
+		name     string
+		retry    int
+		err      error
+		jitter   float64
+		expected time.Duration
+	}{
+		{name: "first_retry", retry: 1, jitter: 1, expected: time.Second},
+		{name: "second_retry", retry: 2, jitter: 1, expected: 2 * time.Second},
+		{name: "fourth_retry", retry: 4, jitter: 1, expected: 8 * time.Second},
+		{name: "capped_by_max_delay", retry: 5, jitter: 1, expected: 10 * time.Second},
+		{name: "many_retries_capped", retry: 100, jitter: 1, expected: 10 * time.Second},
+		{name: "full_jitter", retry: 3, jitter: 0.25, expected: time.Second},
+		{name: "zero_jitter", retry: 3, jitter: 0, expected: 0},
+		{
+			name:     "retry_after_overrides_backoff",
+			retry:    1,
+			err:      &APIError{StatusCode: http.StatusTooManyRequests, RetryAfter: 5 * time.Second},
+			jitter:   1,
+			expected: 5 * time.Second,
+		},
+		{
+			name:     "retry_after_capped_by_max_delay",
+			retry:    1,
+			err:      &APIError{StatusCode: http.StatusTooManyRequests, RetryAfter: 30 * time.Second},
+			jitter:   1,
+			expected: 10 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := policy
+			p.jitter = func() float64 { return tt.jitter }
+			if got := p.delay(tt.retry, tt.err); got != tt.expected {
+				t.Errorf("expected delay %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		value    string
+		expected time.Duration
+	}{
+		{name: "empty", value: "", expected: 0},
+		{name: "seconds", value: "5", expected: 5 * time.Second},
+		{name: "negative_seconds", value: "-1", expected: 0},
+		{name: "http_date", value: now.Add(90 * time.Second).Format(http.TimeFormat), expected: 90 * time.Second},
+		{name: "past_http_date", value: now.Add(-time.Minute).Format(http.TimeFormat), expected: 0},
+		{name: "invalid", value: "soon", expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRetryAfter(tt.value, now); got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+// scriptedCredinform отвечает на запросы данных заданной последовательностью статусов
+type scriptedCredinform struct {
+	mu         sync.Mutex
+	statuses   []int
+	retryAfter string
+	dataCalls  int
+	authCalls  int
+}
+
+func (s *scriptedCredinform) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.URL.Path == "/api/Authorization/GetAccessKey" {
+		s.authCalls++
+		json.NewEncoder(w).Encode(AuthResponse{AccessKey: "key"})
+		return
+	}
+
+	status := s.statuses[len(s.statuses)-1]
+	if s.dataCalls < len(s.statuses) {
+		status = s.statuses[s.dataCalls]
+	}
+	s.dataCalls++
+
+	if s.retryAfter != "" {
+		w.Header().Set("Retry-After", s.retryAfter)
+	}
+	w.WriteHeader(status)
+	if status == http.StatusOK {
+		w.Write([]byte(`{"data":{}}`))
+	}
+}
+
+func TestDoRequestRetries(t *testing.T) {
+	tests := []struct {
+		name              string
+		statuses          []int
+		attempts          int
+		expectedDataCalls int
+		expectedAuthCalls int
+		expectedStatus    int
+		expectedRetryable bool
+	}{
+		{
+			name:              "success_after_server_error",
+			statuses:          []int{http.StatusServiceUnavailable, http.StatusOK},
+			attempts:          3,
+			expectedDataCalls: 2,
+			expectedAuthCalls: 1,
+		},
+		{
+			name:              "rate_limited_then_success",
+			statuses:          []int{http.StatusTooManyRequests, http.StatusOK},
+			attempts:          3,
+			expectedDataCalls: 2,
+			expectedAuthCalls: 1,
+		},
+		{
+			name:              "bad_request_not_retried",
+			statuses:          []int{http.StatusBadRequest},
+			attempts:          3,
+			expectedDataCalls: 1,
+			expectedAuthCalls: 1,
+			expectedStatus:    http.StatusBadRequest,
+		},
+		{
+			name:              "server_errors_exhaust_attempts",
+			statuses:          []int{http.StatusInternalServerError},
+			attempts:          2,
+			expectedDataCalls: 3,
+			expectedAuthCalls: 1,
+			expectedStatus:    http.StatusInternalServerError,
+			expectedRetryable: true,
+		},
+		{
+			// После 401 ключ обновляется один раз, повторный 401 окончателен
+			name:              "persistent_unauthorized",
+			statuses:          []int{http.StatusUnauthorized},
+			attempts:          3,
+			expectedDataCalls: 2,
+			expectedAuthCalls: 2,
+			expectedStatus:    http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &scriptedCredinform{statuses: tt.statuses}
+			srv := httptest.NewServer(fake)
+			defer srv.Close()
+
+			client := newTestClient(t, srv.URL)
+			client.retry = retryPolicy{attempts: tt.attempts, jitter: func() float64 { return 0 }}
+
+			_, err := client.GetBasicInformation(context.Background(), "company-id", BasicInformationParams{})
+
+			if fake.dataCalls != tt.expectedDataCalls {
+				t.Errorf("expected %d data requests, got %d", tt.expectedDataCalls, fake.dataCalls)
+			}
+			if fake.authCalls != tt.expectedAuthCalls {
+				t.Errorf("expected %d auth requests, got %d", tt.expectedAuthCalls, fake.authCalls)
+			}
+
+			if tt.expectedStatus == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.expectedStatus {
+				t.Fatalf("expected APIError with status %d, got %v", tt.expectedStatus, err)
+			}
+			if IsRetryable(err) != tt.expectedRetryable {
+				t.Errorf("expected IsRetryable=%v for %v", tt.expectedRetryable, err)
+			}
+		})
+	}
+}
+
+func TestDoRequestHonoursContextWhileWaiting(t *testing.T) {
+	// Сервер просит подождать минуту, но ожидание должно прерываться отменой контекста
+	fake := &scriptedCredinform{statuses: []int{http.StatusServiceUnavailable}, retryAfter: "60"}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	client := newTestClient(t, srv.URL)
+	client.retry = retryPolicy{attempts: 3, baseDelay: time.Second, jitter: func() float64 { return 1 }}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.GetBasicInformation(ctx, "company-id", BasicInformationParams{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected retry wait to stop on context cancellation, took %s", elapsed)
+	}
+	if !strings.Contains(err.Error(), "interrupted") {
+		t.Errorf("expected interrupted retry error, got %v", err)
+	}
+}
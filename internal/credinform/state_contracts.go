@@ -0,0 +1,22 @@
+package credinform
+
+import (
+	"context"
+	"fmt"
+	"scoring_worker/internal/credinform/types"
+)
+
+type StateContractsParams struct{}
+
+// GetStateContracts получает контракты со всех страниц списка в пределах предела страниц метода
+func (c *Client) GetStateContracts(ctx context.Context, companyID string, params StateContractsParams) (*types.StateContracts, error) {
+	contracts, truncated, err := getPages[*types.StateContract](ctx, c, "CompanyInformation/StateContracts", companyID, params, "stateContractList")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state contracts: %w", err)
+	}
+
+	if contracts == nil {
+		contracts = []*types.StateContract{}
+	}
+	return &types.StateContracts{ContractList: contracts, Truncated: truncated}, nil
+}
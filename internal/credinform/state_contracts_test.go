@@ -0,0 +1,101 @@
+package credinform
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"scoring_worker/internal/config"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func TestGetStateContractsMethodPageLimit(t *testing.T) {
+	tests := []struct {
+		name              string
+		methodMaxPages    map[string]int
+		expectedRequests  int
+		expectedTruncated bool
+	}{
+		{
+			name:              "global_limit",
+			expectedRequests:  2,
+			expectedTruncated: true,
+		},
+		{
+			// Предел метода заменяет общий max_pages
+			name:              "method_limit",
+			methodMaxPages:    map[string]int{"CompanyInformation/StateContracts": 4},
+			expectedRequests:  4,
+			expectedTruncated: true,
+		},
+		{
+			// Ключи из файла конфигурации приходят в нижнем регистре
+			name:              "lowercase_method_limit",
+			methodMaxPages:    map[string]int{"companyinformation/statecontracts": 3},
+			expectedRequests:  3,
+			expectedTruncated: true,
+		},
+		{
+			// Сервер не возвращает paging, поэтому список заканчивается пустой шестой страницей
+			name:             "other_method_limit",
+			methodMaxPages:   map[string]int{"CompanyInformation/StateContracts": 10, "CompanyInformation/ArbitrageCases": 1},
+			expectedRequests: 6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/api/Authorization/GetAccessKey" {
+					json.NewEncoder(w).Encode(AuthResponse{AccessKey: "key"})
+					return
+				}
+				requests++
+				body, _ := io.ReadAll(r.Body)
+				var req struct {
+					Paging Paging `json:"paging"`
+				}
+				json.Unmarshal(body, &req)
+
+				// Девять контрактов на страницах по два
+				var items []string
+				for i := (req.Paging.PageNumber - 1) * 2; i < 9 && i < req.Paging.PageNumber*2; i++ {
+					items = append(items, fmt.Sprintf(`{"registryNumber":"%d","participationRole":"Supplier"}`, i+1))
+				}
+				fmt.Fprintf(w, `{"data":{"stateContractList":[%s]}}`, strings.Join(items, ","))
+			}))
+			defer srv.Close()
+
+			client := NewClient(&config.CredinformConfig{
+				BaseURL:       srv.URL,
+				Username:      "user",
+				Password:      base64.StdEncoding.EncodeToString([]byte("secret")),
+				Timeout:       5,
+				RetryAttempts: 1,
+				Pagination:    config.PaginationConfig{PageSize: 2, MaxPages: 2, MethodMaxPages: tt.methodMaxPages},
+			}, zaptest.NewLogger(t))
+
+			contracts, err := client.GetStateContracts(context.Background(), "company-id", StateContractsParams{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if requests != tt.expectedRequests {
+				t.Errorf("expected %d page requests, got %d", tt.expectedRequests, requests)
+			}
+			if contracts.Truncated != tt.expectedTruncated {
+				t.Errorf("expected truncated %t, got %t", tt.expectedTruncated, contracts.Truncated)
+			}
+			if len(contracts.ContractList) != min(9, tt.expectedRequests*2) {
+				t.Errorf("expected contracts from all fetched pages, got %d", len(contracts.ContractList))
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package credinform
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// tokenManager хранит общий ключ доступа клиента. Обновление ключа выполняется
+// не более чем одним запросом за раз: остальные вызывающие ждут его результата.
+// Ключ обновляется заранее, за refreshBefore до истечения lifetime.
+type tokenManager struct {
+	authenticate  func(ctx context.Context) (string, error)
+	lifetime      time.Duration
+	refreshBefore time.Duration
+	now           func() time.Time
+
+	mu       sync.Mutex
+	key      string
+	issuedAt time.Time
+	inFlight *tokenRefresh
+}
+
+// tokenRefresh — выполняющееся получение ключа, результат которого разделяют все ожидающие
+type tokenRefresh struct {
+	done chan struct{}
+	key  string
+	err  error
+}
+
+func newTokenManager(authenticate func(ctx context.Context) (string, error), lifetime, refreshBefore time.Duration) *tokenManager {
+	return &tokenManager{
+		authenticate:  authenticate,
+		lifetime:      lifetime,
+		refreshBefore: refreshBefore,
+		now:           time.Now,
+	}
+}
+
+// Key возвращает действующий ключ доступа, при необходимости получая новый
+func (m *tokenManager) Key(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	if m.key != "" && !m.expiringLocked() {
+		key := m.key
+		m.mu.Unlock()
+		return key, nil
+	}
+	return m.refreshLocked(ctx)
+}
+
+// Refresh получает новый ключ взамен stale, отклонённого сервером. Если ключ
+// уже обновил другой вызывающий, возвращается текущий ключ без запроса.
+func (m *tokenManager) Refresh(ctx context.Context, stale string) (string, error) {
+	m.mu.Lock()
+	if m.key != "" && m.key != stale && !m.expiringLocked() {
+		key := m.key
+		m.mu.Unlock()
+		return key, nil
+	}
+	if m.key == stale {
+		m.key = ""
+	}
+	return m.refreshLocked(ctx)
+}
+
+// refreshLocked запускает получение ключа или присоединяется к уже выполняющемуся.
+// Вызывается с захваченным m.mu и освобождает его.
+func (m *tokenManager) refreshLocked(ctx context.Context) (string, error) {
+	refresh := m.inFlight
+	if refresh == nil {
+		refresh = &tokenRefresh{done: make(chan struct{})}
+		m.inFlight = refresh
+		go m.run(ctx, refresh)
+	}
+	m.mu.Unlock()
+
+	select {
+	case <-refresh.done:
+		return refresh.key, refresh.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func (m *tokenManager) run(ctx context.Context, refresh *tokenRefresh) {
+	// Запрос не должен прерываться отменой контекста инициатора: его результат ждут другие
+	key, err := m.authenticate(context.WithoutCancel(ctx))
+
+	m.mu.Lock()
+	if err == nil {
+		m.key = key
+		m.issuedAt = m.now()
+	}
+	m.inFlight = nil
+	m.mu.Unlock()
+
+	refresh.key, refresh.err = key, err
+	close(refresh.done)
+}
+
+// expiringLocked сообщает, что срок действия ключа истекает и его пора обновить
+func (m *tokenManager) expiringLocked() bool {
+	if m.lifetime <= 0 {
+		return false
+	}
+	return !m.now().Before(m.issuedAt.Add(m.lifetime - m.refreshBefore))
+}
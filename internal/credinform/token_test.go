@@ -0,0 +1,268 @@
+package credinform
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"scoring_worker/internal/config"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func TestTokenManagerSingleRefresh(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	m := newTokenManager(func(ctx context.Context) (string, error) {
+		n := calls.Add(1)
+		<-release
+		return fmt.Sprintf("key-%d", n), nil
+	}, 0, 0)
+
+	const callers = 20
+	keys := make([]string, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key, err := m.Key(context.Background())
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			keys[i] = key
+		}(i)
+	}
+
+	// Даём вызывающим время присоединиться к обновлению
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("expected 1 authentication, got %d", calls.Load())
+	}
+	for i, key := range keys {
+		if key != "key-1" {
+			t.Errorf("caller %d: expected key-1, got %q", i, key)
+		}
+	}
+}
+
+func TestTokenManagerRefresh(t *testing.T) {
+	var calls atomic.Int32
+	m := newTokenManager(func(ctx context.Context) (string, error) {
+		return fmt.Sprintf("key-%d", calls.Add(1)), nil
+	}, 0, 0)
+
+	key, _ := m.Key(context.Background())
+
+	// Первый вызывающий с отклонённым ключом получает новый
+	refreshed, err := m.Refresh(context.Background(), key)
+	if err != nil || refreshed != "key-2" {
+		t.Fatalf("expected key-2, got %q (err: %v)", refreshed, err)
+	}
+
+	// Второй вызывающий со старым ключом получает уже обновлённый без запроса
+	refreshed, err = m.Refresh(context.Background(), key)
+	if err != nil || refreshed != "key-2" {
+		t.Fatalf("expected key-2, got %q (err: %v)", refreshed, err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("expected 2 authentications, got %d", calls.Load())
+	}
+}
+
+func TestTokenManagerProactiveRefresh(t *testing.T) {
+	var calls atomic.Int32
+	m := newTokenManager(func(ctx context.Context) (string, error) {
+		return fmt.Sprintf("key-%d", calls.Add(1)), nil
+	}, 10*time.Minute, time.Minute)
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+
+	tests := []struct {
+		name     string
+		elapsed  time.Duration
+		expected string
+	}{
+		{name: "initial", elapsed: 0, expected: "key-1"},
+		{name: "fresh", elapsed: 5 * time.Minute, expected: "key-1"},
+		{name: "before_refresh_window", elapsed: 8*time.Minute + 59*time.Second, expected: "key-1"},
+		{name: "in_refresh_window", elapsed: 9 * time.Minute, expected: "key-2"},
+		{name: "after_refresh", elapsed: 10 * time.Minute, expected: "key-2"},
+	}
+
+	start := now
+	for _, tt := range tests {
+		now = start.Add(tt.elapsed)
+		key, err := m.Key(context.Background())
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if key != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, key)
+		}
+	}
+}
+
+func TestTokenManagerErrorIsShared(t *testing.T) {
+	var calls atomic.Int32
+	authErr := errors.New("auth failed")
+	m := newTokenManager(func(ctx context.Context) (string, error) {
+		if calls.Add(1) == 1 {
+			return "", authErr
+		}
+		return "key", nil
+	}, 0, 0)
+
+	if _, err := m.Key(context.Background()); !errors.Is(err, authErr) {
+		t.Fatalf("expected auth error, got %v", err)
+	}
+
+	// Неудачное обновление не сохраняется: следующий вызов повторяет авторизацию
+	key, err := m.Key(context.Background())
+	if err != nil || key != "key" {
+		t.Fatalf("expected key after retry, got %q (err: %v)", key, err)
+	}
+}
+
+// fakeCredinform выдаёт ключи доступа и принимает только последний выданный
+type fakeCredinform struct {
+	mu        sync.Mutex
+	current   string
+	authCalls int
+}
+
+func (f *fakeCredinform) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.URL.Path == "/api/Authorization/GetAccessKey" {
+		f.authCalls++
+		f.current = fmt.Sprintf("key-%d", f.authCalls)
+		json.NewEncoder(w).Encode(AuthResponse{AccessKey: f.current})
+		return
+	}
+
+	if r.Header.Get("accessKey") != f.current {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	w.Write([]byte(`{"data":{}}`))
+}
+
+// expire имитирует истечение ключа на стороне сервера
+func (f *fakeCredinform) expire() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.current = "expired"
+}
+
+func newTestClient(t *testing.T, baseURL string) *Client {
+	t.Helper()
+
+	client := NewClient(&config.CredinformConfig{
+		BaseURL:       baseURL,
+		Username:      "user",
+		Password:      base64.StdEncoding.EncodeToString([]byte("secret")),
+		Timeout:       5,
+		RetryAttempts: 1,
+	}, zaptest.NewLogger(t))
+	if client == nil {
+		t.Fatal("failed to create client")
+	}
+	return client
+}
+
+// serveCompanyInformation поднимает сервер Credinform, который отвечает response
+// на метод path (например, "CompanyInformation/Licenses"), и возвращает клиент к нему.
+// checkBody, если задан, получает тело запроса метода.
+func serveCompanyInformation(t *testing.T, path, response string, checkBody func(body []byte)) *Client {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/Authorization/GetAccessKey" {
+			json.NewEncoder(w).Encode(AuthResponse{AccessKey: "key"})
+			return
+		}
+		if r.URL.Path != "/api/"+path {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if checkBody != nil {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				t.Errorf("failed to read request body: %v", err)
+			}
+			checkBody(body)
+		}
+		w.Write([]byte(response))
+	}))
+	t.Cleanup(srv.Close)
+	return newTestClient(t, srv.URL)
+}
+
+// assertItems проверяет список из ответа поэлементно. Список не должен быть nil:
+// отсутствующий в ответе список сохраняется как пустой.
+func assertItems[T any](t *testing.T, name string, got []*T, expected []T) {
+	t.Helper()
+
+	if got == nil {
+		t.Errorf("expected non-nil %s", name)
+		return
+	}
+	values := make([]T, 0, len(got))
+	for _, item := range got {
+		values = append(values, *item)
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %s %+v, got %+v", name, expected, values)
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func TestClientConcurrentAuthentication(t *testing.T) {
+	fake := &fakeCredinform{}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	client := newTestClient(t, srv.URL)
+
+	run := func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := client.GetBasicInformation(context.Background(), "company-id", BasicInformationParams{}); err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	run()
+	if fake.authCalls != 1 {
+		t.Errorf("expected one authentication for concurrent callers, got %d", fake.authCalls)
+	}
+
+	// После истечения ключа все вызывающие получают 401, но обновляют ключ один раз
+	fake.expire()
+	run()
+	if fake.authCalls != 2 {
+		t.Errorf("expected one re-authentication after expiry, got %d authentications", fake.authCalls)
+	}
+}
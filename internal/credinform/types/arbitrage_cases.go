@@ -0,0 +1,48 @@
+package types
+
+// --- Арбитражные дела ---
+
+// Статусы арбитражного дела
+const (
+	ArbitrageCaseStatusOpen   = "Open"
+	ArbitrageCaseStatusClosed = "Closed"
+)
+
+// ArbitrageCases — арбитражные дела компании со всех полученных страниц
+type ArbitrageCases struct {
+	CaseList []*ArbitrageCase `json:"arbitrageCaseList"`
+	// Truncated — получены не все страницы из-за ограничения credinform.pagination.max_pages
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+type ArbitrageCase struct {
+	CaseNumber *string `json:"caseNumber,omitempty"`
+	CourtName  *string `json:"courtName,omitempty"`
+	StartDate  *string `json:"startDate,omitempty"`
+	Status     *string `json:"caseStatus,omitempty"`
+	// Side — сторона, которой в деле выступает компания
+	Side           *string                     `json:"arbitrageSideCommonType,omitempty"`
+	ClaimAmount    *float64                    `json:"claimAmount,omitempty"`
+	CategoryCode   *string                     `json:"caseCategoryCode,omitempty"`
+	CategoryName   *string                     `json:"caseCategoryName,omitempty"`
+	ClaimantList   []*ArbitrageCaseParticipant `json:"claimantList,omitempty"`
+	DefendantList  []*ArbitrageCaseParticipant `json:"defendantList,omitempty"`
+	ThirdPartyList []*ArbitrageCaseParticipant `json:"thirdPartyList,omitempty"`
+	LastEvent      *ArbitrageCaseEvent         `json:"lastEvent,omitempty"`
+}
+
+type ArbitrageCaseParticipant struct {
+	Name               *string `json:"name,omitempty"`
+	TaxNumber          *string `json:"taxNumber,omitempty"`
+	RegistrationNumber *string `json:"registrationNumber,omitempty"`
+}
+
+type ArbitrageCaseEvent struct {
+	Date *string `json:"date,omitempty"`
+	Name *string `json:"name,omitempty"`
+}
+
+// IsOpen сообщает, не завершено ли дело. Дело без статуса считается открытым.
+func (c *ArbitrageCase) IsOpen() bool {
+	return c.Status == nil || *c.Status != ArbitrageCaseStatusClosed
+}
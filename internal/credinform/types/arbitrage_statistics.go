@@ -2,6 +2,120 @@ package types
 
 // --- Арбитражная статистика ---
 
-type ArbitrageStatistics map[string]interface{}
+// Стороны дела в запросе и ответе Credinform
+const (
+	ArbitrageSideClaimant              = "Claimant"
+	ArbitrageSideDefendant             = "Defendant"
+	ArbitrageSideThirdPartiesAndOthers = "ThirdPartiesAndOthers"
+)
 
 type ArbitrageStatisticsResponse = ArbitrageStatistics
+
+// ArbitrageStatistics — статистика арбитражных дел компании по сторонам.
+// Поля ответа без соответствия в структурах сохраняются в Extension каждого уровня.
+type ArbitrageStatistics struct {
+	CaseCount    *int                           `json:"caseCount,omitempty"`
+	CaseSum      *float64                       `json:"caseSum,omitempty"`
+	SideList     []*ArbitrageSideStatistics     `json:"arbitrageSideStatisticsList,omitempty"`
+	CategoryList []*ArbitrageCategoryStatistics `json:"caseCategoryStatisticsList,omitempty"`
+
+	Extension Extension `json:"-"`
+}
+
+// ArbitrageSideStatistics — дела, в которых компания выступает одной стороной
+type ArbitrageSideStatistics struct {
+	Side      string                     `json:"arbitrageSideCommonType"`
+	CaseCount *int                       `json:"caseCount,omitempty"`
+	CaseSum   *float64                   `json:"caseSum,omitempty"`
+	YearList  []*ArbitrageYearStatistics `json:"yearList,omitempty"`
+
+	Extension Extension `json:"-"`
+}
+
+// ArbitrageYearStatistics — дела стороны за год
+type ArbitrageYearStatistics struct {
+	Year      int      `json:"year"`
+	CaseCount *int     `json:"caseCount,omitempty"`
+	CaseSum   *float64 `json:"caseSum,omitempty"`
+
+	Extension Extension `json:"-"`
+}
+
+// ArbitrageCategoryStatistics — дела одной категории (банкротство, взыскание долга и т.п.)
+type ArbitrageCategoryStatistics struct {
+	CategoryCode *string  `json:"caseCategoryCode,omitempty"`
+	CategoryName *string  `json:"caseCategoryName,omitempty"`
+	Side         *string  `json:"arbitrageSideCommonType,omitempty"`
+	CaseCount    *int     `json:"caseCount,omitempty"`
+	CaseSum      *float64 `json:"caseSum,omitempty"`
+
+	Extension Extension `json:"-"`
+}
+
+// Side возвращает статистику по стороне или nil, если её нет в ответе
+func (s *ArbitrageStatistics) Side(side string) *ArbitrageSideStatistics {
+	for _, item := range s.SideList {
+		if item != nil && item.Side == side {
+			return item
+		}
+	}
+	return nil
+}
+
+// Year возвращает статистику стороны за год или nil, если её нет в ответе
+func (s *ArbitrageSideStatistics) Year(year int) *ArbitrageYearStatistics {
+	for _, item := range s.YearList {
+		if item != nil && item.Year == year {
+			return item
+		}
+	}
+	return nil
+}
+
+func (s *ArbitrageStatistics) UnmarshalJSON(data []byte) error {
+	type plain ArbitrageStatistics
+	ext, err := unmarshalWithExtension(data, (*plain)(s))
+	s.Extension = ext
+	return err
+}
+
+func (s ArbitrageStatistics) MarshalJSON() ([]byte, error) {
+	type plain ArbitrageStatistics
+	return marshalWithExtension(plain(s), s.Extension)
+}
+
+func (s *ArbitrageSideStatistics) UnmarshalJSON(data []byte) error {
+	type plain ArbitrageSideStatistics
+	ext, err := unmarshalWithExtension(data, (*plain)(s))
+	s.Extension = ext
+	return err
+}
+
+func (s ArbitrageSideStatistics) MarshalJSON() ([]byte, error) {
+	type plain ArbitrageSideStatistics
+	return marshalWithExtension(plain(s), s.Extension)
+}
+
+func (s *ArbitrageYearStatistics) UnmarshalJSON(data []byte) error {
+	type plain ArbitrageYearStatistics
+	ext, err := unmarshalWithExtension(data, (*plain)(s))
+	s.Extension = ext
+	return err
+}
+
+func (s ArbitrageYearStatistics) MarshalJSON() ([]byte, error) {
+	type plain ArbitrageYearStatistics
+	return marshalWithExtension(plain(s), s.Extension)
+}
+
+func (s *ArbitrageCategoryStatistics) UnmarshalJSON(data []byte) error {
+	type plain ArbitrageCategoryStatistics
+	ext, err := unmarshalWithExtension(data, (*plain)(s))
+	s.Extension = ext
+	return err
+}
+
+func (s ArbitrageCategoryStatistics) MarshalJSON() ([]byte, error) {
+	type plain ArbitrageCategoryStatistics
+	return marshalWithExtension(plain(s), s.Extension)
+}
@@ -0,0 +1,40 @@
+package types
+
+// --- Сообщения о банкротстве и ликвидации (Федресурс) ---
+
+// Виды сообщений
+const (
+	// BankruptcyMessageIntention — намерение кредитора или должника обратиться в суд с заявлением о банкротстве
+	BankruptcyMessageIntention = "IntentionToFileForBankruptcy"
+	// BankruptcyMessageProcedureIntroduced — введение процедуры банкротства, стадия указана в сообщении
+	BankruptcyMessageProcedureIntroduced = "ProcedureIntroduced"
+	// BankruptcyMessageProcedureTerminated — прекращение производства по делу о банкротстве
+	BankruptcyMessageProcedureTerminated = "ProcedureTerminated"
+	BankruptcyMessageLiquidationDecision = "LiquidationDecision"
+	BankruptcyMessageReorganization      = "ReorganizationDecision"
+)
+
+// Стадии процедуры банкротства
+const (
+	BankruptcyStageObservation            = "Observation"
+	BankruptcyStageFinancialRecovery      = "FinancialRecovery"
+	BankruptcyStageExternalManagement     = "ExternalManagement"
+	BankruptcyStageCompetitiveProceedings = "CompetitiveProceedings"
+)
+
+type BankruptcyMessagesResponse = BankruptcyMessages
+
+type BankruptcyMessages struct {
+	MessageList []*BankruptcyMessage `json:"bankruptcyMessageList"`
+}
+
+type BankruptcyMessage struct {
+	MessageNumber *string `json:"messageNumber,omitempty"`
+	Kind          *string `json:"messageType,omitempty"`
+	// PublicationDate — дата публикации в формате ISO 8601
+	PublicationDate *string `json:"publicationDate,omitempty"`
+	Stage           *string `json:"procedureStage,omitempty"`
+	CaseNumber      *string `json:"caseNumber,omitempty"`
+	Publisher       *string `json:"publisherName,omitempty"`
+	Text            *string `json:"text,omitempty"`
+}
@@ -0,0 +1,16 @@
+package types
+
+import "time"
+
+// DateOf возвращает дату YYYY-MM-DD из значения ISO 8601 с временем или без него.
+// Для отсутствующего или некорректного значения ok ложно.
+func DateOf(value *string) (date string, ok bool) {
+	if value == nil || len(*value) < 10 {
+		return "", false
+	}
+	date = (*value)[:10]
+	if _, err := time.Parse(time.DateOnly, date); err != nil {
+		return "", false
+	}
+	return date, true
+}
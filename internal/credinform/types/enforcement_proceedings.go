@@ -0,0 +1,39 @@
+package types
+
+// --- Исполнительные производства (ФССП) ---
+
+// Статусы исполнительного производства
+const (
+	EnforcementProceedingStatusOpen   = "Open"
+	EnforcementProceedingStatusClosed = "Closed"
+)
+
+type EnforcementProceedingsResponse = EnforcementProceedings
+
+type EnforcementProceedings struct {
+	ProceedingList []*EnforcementProceeding `json:"enforcementProceedingList"`
+}
+
+type EnforcementProceeding struct {
+	Number *string `json:"proceedingNumber,omitempty"`
+	// StartDate — дата возбуждения производства в формате ISO 8601
+	StartDate *string `json:"startDate,omitempty"`
+	EndDate   *string `json:"endDate,omitempty"`
+	Status    *string `json:"status,omitempty"`
+	// Subject — предмет исполнения: налоги и сборы, задолженность по кредитным платежам и т.п.
+	Subject       *string  `json:"subject,omitempty"`
+	Amount        *float64 `json:"amount,omitempty"`
+	RemainingDebt *float64 `json:"remainingDebt,omitempty"`
+	Department    *string  `json:"bailiffDepartment,omitempty"`
+	// ExecutiveDocument — реквизиты исполнительного документа
+	ExecutiveDocument *string `json:"executiveDocument,omitempty"`
+}
+
+// IsOpen сообщает, не окончено ли производство. Без статуса открытым считается
+// производство без даты окончания.
+func (p *EnforcementProceeding) IsOpen() bool {
+	if p.Status != nil {
+		return *p.Status != EnforcementProceedingStatusClosed
+	}
+	return p.EndDate == nil
+}
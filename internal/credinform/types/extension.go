@@ -0,0 +1,78 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// Extension — поля ответа Credinform, для которых нет поля в структуре.
+// Сохраняются как есть, чтобы при появлении новых полей ничего не терялось.
+type Extension map[string]json.RawMessage
+
+// unmarshalWithExtension разбирает data в v (указатель на структуру без собственного
+// UnmarshalJSON) и возвращает поля, которых нет среди json-тегов структуры
+func unmarshalWithExtension(data []byte, v any) (Extension, error) {
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	known := knownFields(reflect.TypeOf(v).Elem())
+	var ext Extension
+	for name, value := range fields {
+		// encoding/json сопоставляет имена без учёта регистра
+		if known[strings.ToLower(name)] {
+			continue
+		}
+		if ext == nil {
+			ext = make(Extension)
+		}
+		ext[name] = value
+	}
+	return ext, nil
+}
+
+// marshalWithExtension сериализует v и дописывает к нему поля ext.
+// Известные поля имеют приоритет над одноимёнными полями расширения.
+func marshalWithExtension(v any, ext Extension) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(ext) == 0 {
+		return data, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for name, value := range ext {
+		if _, ok := fields[name]; !ok {
+			fields[name] = value
+		}
+	}
+	return json.Marshal(fields)
+}
+
+func knownFields(t reflect.Type) map[string]bool {
+	known := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		known[strings.ToLower(name)] = true
+	}
+	return known
+}
@@ -0,0 +1,178 @@
+package types
+
+import "sort"
+
+// --- Бухгалтерская отчётность ---
+
+// Формы отчётности в запросе и ответе Credinform
+const (
+	StatementFormBalanceSheet  = "BalanceSheet"
+	StatementFormProfitAndLoss = "ProfitAndLoss"
+)
+
+// FinancialStatementsResponse — отчётность в виде строк с кодами, как её возвращает Credinform
+type FinancialStatementsResponse struct {
+	StatementList []*FinancialStatement `json:"statementList,omitempty"`
+}
+
+type FinancialStatement struct {
+	Year  int                       `json:"year"`
+	Form  string                    `json:"form"`
+	Lines []*FinancialStatementLine `json:"lines,omitempty"`
+}
+
+type FinancialStatementLine struct {
+	Code  string   `json:"code"`
+	Name  *string  `json:"name,omitempty"`
+	Value *float64 `json:"value,omitempty"`
+}
+
+// FinancialStatements — отчётность по годам с именованными строками форм.
+// Строки, для которых нет поля, сохраняются в OtherLines по коду.
+type FinancialStatements struct {
+	Years []*FinancialYear `json:"years"`
+}
+
+type FinancialYear struct {
+	Year          int            `json:"year"`
+	BalanceSheet  *BalanceSheet  `json:"balanceSheet,omitempty"`
+	ProfitAndLoss *ProfitAndLoss `json:"profitAndLoss,omitempty"`
+}
+
+// BalanceSheet — бухгалтерский баланс (форма 1), в скобках коды строк
+type BalanceSheet struct {
+	IntangibleAssets     *float64 `json:"intangibleAssets,omitempty"`     // 1110
+	FixedAssets          *float64 `json:"fixedAssets,omitempty"`          // 1150
+	NonCurrentAssets     *float64 `json:"nonCurrentAssets,omitempty"`     // 1100
+	Inventories          *float64 `json:"inventories,omitempty"`          // 1210
+	AccountsReceivable   *float64 `json:"accountsReceivable,omitempty"`   // 1230
+	FinancialInvestments *float64 `json:"financialInvestments,omitempty"` // 1240
+	Cash                 *float64 `json:"cash,omitempty"`                 // 1250
+	CurrentAssets        *float64 `json:"currentAssets,omitempty"`        // 1200
+	TotalAssets          *float64 `json:"totalAssets,omitempty"`          // 1600
+	CharterCapital       *float64 `json:"charterCapital,omitempty"`       // 1310
+	RetainedEarnings     *float64 `json:"retainedEarnings,omitempty"`     // 1370
+	Equity               *float64 `json:"equity,omitempty"`               // 1300
+	LongTermBorrowings   *float64 `json:"longTermBorrowings,omitempty"`   // 1410
+	LongTermLiabilities  *float64 `json:"longTermLiabilities,omitempty"`  // 1400
+	ShortTermBorrowings  *float64 `json:"shortTermBorrowings,omitempty"`  // 1510
+	AccountsPayable      *float64 `json:"accountsPayable,omitempty"`      // 1520
+	ShortTermLiabilities *float64 `json:"shortTermLiabilities,omitempty"` // 1500
+	TotalLiabilities     *float64 `json:"totalLiabilities,omitempty"`     // 1700
+
+	OtherLines map[string]float64 `json:"otherLines,omitempty"`
+}
+
+func (b *BalanceSheet) lines() map[string]**float64 {
+	return map[string]**float64{
+		"1110": &b.IntangibleAssets,
+		"1150": &b.FixedAssets,
+		"1100": &b.NonCurrentAssets,
+		"1210": &b.Inventories,
+		"1230": &b.AccountsReceivable,
+		"1240": &b.FinancialInvestments,
+		"1250": &b.Cash,
+		"1200": &b.CurrentAssets,
+		"1600": &b.TotalAssets,
+		"1310": &b.CharterCapital,
+		"1370": &b.RetainedEarnings,
+		"1300": &b.Equity,
+		"1410": &b.LongTermBorrowings,
+		"1400": &b.LongTermLiabilities,
+		"1510": &b.ShortTermBorrowings,
+		"1520": &b.AccountsPayable,
+		"1500": &b.ShortTermLiabilities,
+		"1700": &b.TotalLiabilities,
+	}
+}
+
+// ProfitAndLoss — отчёт о финансовых результатах (форма 2), в скобках коды строк
+type ProfitAndLoss struct {
+	Revenue                *float64 `json:"revenue,omitempty"`                // 2110
+	CostOfSales            *float64 `json:"costOfSales,omitempty"`            // 2120
+	GrossProfit            *float64 `json:"grossProfit,omitempty"`            // 2100
+	SellingExpenses        *float64 `json:"sellingExpenses,omitempty"`        // 2210
+	AdministrativeExpenses *float64 `json:"administrativeExpenses,omitempty"` // 2220
+	SalesProfit            *float64 `json:"salesProfit,omitempty"`            // 2200
+	InterestPayable        *float64 `json:"interestPayable,omitempty"`        // 2330
+	OtherIncome            *float64 `json:"otherIncome,omitempty"`            // 2340
+	OtherExpenses          *float64 `json:"otherExpenses,omitempty"`          // 2350
+	ProfitBeforeTax        *float64 `json:"profitBeforeTax,omitempty"`        // 2300
+	IncomeTax              *float64 `json:"incomeTax,omitempty"`              // 2410
+	NetProfit              *float64 `json:"netProfit,omitempty"`              // 2400
+
+	OtherLines map[string]float64 `json:"otherLines,omitempty"`
+}
+
+func (p *ProfitAndLoss) lines() map[string]**float64 {
+	return map[string]**float64{
+		"2110": &p.Revenue,
+		"2120": &p.CostOfSales,
+		"2100": &p.GrossProfit,
+		"2210": &p.SellingExpenses,
+		"2220": &p.AdministrativeExpenses,
+		"2200": &p.SalesProfit,
+		"2330": &p.InterestPayable,
+		"2340": &p.OtherIncome,
+		"2350": &p.OtherExpenses,
+		"2300": &p.ProfitBeforeTax,
+		"2410": &p.IncomeTax,
+		"2400": &p.NetProfit,
+	}
+}
+
+// NewFinancialStatements раскладывает строки отчётности по именованным полям.
+// Годы упорядочены по возрастанию, формы неизвестного вида пропускаются.
+func NewFinancialStatements(resp *FinancialStatementsResponse) *FinancialStatements {
+	byYear := make(map[int]*FinancialYear)
+	result := &FinancialStatements{Years: []*FinancialYear{}}
+	for _, statement := range resp.StatementList {
+		if statement == nil {
+			continue
+		}
+		year, ok := byYear[statement.Year]
+		if !ok {
+			year = &FinancialYear{Year: statement.Year}
+		}
+
+		var fields map[string]**float64
+		var other *map[string]float64
+		switch statement.Form {
+		case StatementFormBalanceSheet:
+			if year.BalanceSheet == nil {
+				year.BalanceSheet = &BalanceSheet{}
+			}
+			fields, other = year.BalanceSheet.lines(), &year.BalanceSheet.OtherLines
+		case StatementFormProfitAndLoss:
+			if year.ProfitAndLoss == nil {
+				year.ProfitAndLoss = &ProfitAndLoss{}
+			}
+			fields, other = year.ProfitAndLoss.lines(), &year.ProfitAndLoss.OtherLines
+		default:
+			continue
+		}
+
+		for _, line := range statement.Lines {
+			if line == nil || line.Value == nil {
+				continue
+			}
+			value := *line.Value
+			if field, ok := fields[line.Code]; ok {
+				*field = &value
+				continue
+			}
+			if *other == nil {
+				*other = make(map[string]float64)
+			}
+			(*other)[line.Code] = value
+		}
+
+		if !ok {
+			byYear[statement.Year] = year
+			result.Years = append(result.Years, year)
+		}
+	}
+
+	sort.Slice(result.Years, func(i, j int) bool { return result.Years[i].Year < result.Years[j].Year })
+	return result
+}
@@ -0,0 +1,65 @@
+package types
+
+// --- Лицензии и членство в СРО ---
+
+// Статусы лицензии и членства в СРО
+const (
+	LicenseStatusActive     = "Active"
+	LicenseStatusSuspended  = "Suspended"
+	LicenseStatusRevoked    = "Revoked"
+	LicenseStatusTerminated = "Terminated"
+)
+
+type LicensesResponse = Licenses
+
+type Licenses struct {
+	LicenseList       []*License       `json:"licenseList"`
+	SroMembershipList []*SroMembership `json:"sroMembershipList"`
+}
+
+type License struct {
+	Number           *string `json:"licenseNumber,omitempty"`
+	Activity         *string `json:"licensedActivity,omitempty"`
+	IssuingAuthority *string `json:"issuingAuthority,omitempty"`
+	// StartDate и EndDate — срок действия в формате ISO 8601; без EndDate лицензия бессрочная
+	StartDate *string `json:"startDate,omitempty"`
+	EndDate   *string `json:"endDate,omitempty"`
+	Status    *string `json:"status,omitempty"`
+	// ActivityCodes — коды ОКВЭД, на которые распространяется лицензия
+	ActivityCodes []string `json:"okvedCodes,omitempty"`
+}
+
+type SroMembership struct {
+	SroName           *string  `json:"sroName,omitempty"`
+	SroRegistryNumber *string  `json:"sroRegistryNumber,omitempty"`
+	MembershipNumber  *string  `json:"membershipNumber,omitempty"`
+	StartDate         *string  `json:"startDate,omitempty"`
+	EndDate           *string  `json:"endDate,omitempty"`
+	Status            *string  `json:"status,omitempty"`
+	ActivityCodes     []string `json:"okvedCodes,omitempty"`
+}
+
+// IsActive сообщает, действует ли лицензия на дату date (YYYY-MM-DD)
+func (l *License) IsActive(date string) bool {
+	return validAt(l.Status, l.StartDate, l.EndDate, date)
+}
+
+// IsActive сообщает, действует ли членство в СРО на дату date (YYYY-MM-DD)
+func (m *SroMembership) IsActive(date string) bool {
+	return validAt(m.Status, m.StartDate, m.EndDate, date)
+}
+
+// validAt проверяет статус и срок действия. Без статуса учитывается только срок;
+// некорректные даты не учитываются.
+func validAt(status, start, end *string, date string) bool {
+	if status != nil && *status != LicenseStatusActive {
+		return false
+	}
+	if d, ok := DateOf(start); ok && d > date {
+		return false
+	}
+	if d, ok := DateOf(end); ok && d < date {
+		return false
+	}
+	return true
+}
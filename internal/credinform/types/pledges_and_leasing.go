@@ -0,0 +1,71 @@
+package types
+
+// --- Уведомления о залоге движимого имущества и договоры лизинга ---
+
+// Роли компании в залоге и лизинге
+const (
+	EncumbranceRolePledgor = "Pledgor"
+	EncumbranceRolePledgee = "Pledgee"
+	EncumbranceRoleLessee  = "Lessee"
+	EncumbranceRoleLessor  = "Lessor"
+)
+
+type PledgesAndLeasingResponse = PledgesAndLeasing
+
+type PledgesAndLeasing struct {
+	PledgeList  []*PledgeNotification `json:"pledgeNotificationList"`
+	LeasingList []*LeasingContract    `json:"leasingContractList"`
+}
+
+// PledgeNotification — уведомление о возникновении залога из реестра нотариальной палаты.
+// Даты в формате ISO 8601.
+type PledgeNotification struct {
+	NotificationNumber *string `json:"notificationNumber,omitempty"`
+	RegistrationDate   *string `json:"registrationDate,omitempty"`
+	// ExclusionDate — дата уведомления об исключении сведений о залоге
+	ExclusionDate  *string `json:"exclusionDate,omitempty"`
+	Role           *string `json:"participationRole,omitempty"`
+	ContractNumber *string `json:"contractNumber,omitempty"`
+	ContractDate   *string `json:"contractDate,omitempty"`
+	// Property — описание заложенного имущества
+	Property    *string                   `json:"propertyDescription,omitempty"`
+	PledgorList []*EncumbranceParticipant `json:"pledgorList,omitempty"`
+	PledgeeList []*EncumbranceParticipant `json:"pledgeeList,omitempty"`
+}
+
+// LeasingContract — сведения о договоре лизинга из Федресурса
+type LeasingContract struct {
+	ContractNumber *string `json:"contractNumber,omitempty"`
+	ContractDate   *string `json:"contractDate,omitempty"`
+	StartDate      *string `json:"startDate,omitempty"`
+	EndDate        *string `json:"endDate,omitempty"`
+	// TerminationDate — дата публикации о прекращении договора до окончания срока
+	TerminationDate *string                   `json:"terminationDate,omitempty"`
+	Role            *string                   `json:"participationRole,omitempty"`
+	Subject         *string                   `json:"subject,omitempty"`
+	LessorList      []*EncumbranceParticipant `json:"lessorList,omitempty"`
+	LesseeList      []*EncumbranceParticipant `json:"lesseeList,omitempty"`
+}
+
+type EncumbranceParticipant struct {
+	Name               *string `json:"name,omitempty"`
+	TaxNumber          *string `json:"taxNumber,omitempty"`
+	RegistrationNumber *string `json:"registrationNumber,omitempty"`
+}
+
+// IsActive сообщает, действует ли залог на дату date (YYYY-MM-DD).
+// Некорректная дата исключения не учитывается.
+func (p *PledgeNotification) IsActive(date string) bool {
+	excluded, ok := DateOf(p.ExclusionDate)
+	return !ok || excluded > date
+}
+
+// IsActive сообщает, действует ли договор лизинга на дату date (YYYY-MM-DD).
+// Некорректные даты окончания и прекращения не учитываются.
+func (l *LeasingContract) IsActive(date string) bool {
+	if terminated, ok := DateOf(l.TerminationDate); ok && terminated <= date {
+		return false
+	}
+	end, ok := DateOf(l.EndDate)
+	return !ok || end >= date
+}
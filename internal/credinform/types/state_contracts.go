@@ -0,0 +1,41 @@
+package types
+
+// --- Государственные контракты (44-ФЗ, 223-ФЗ) ---
+
+// Роли компании в контракте
+const (
+	StateContractRoleSupplier = "Supplier"
+	StateContractRoleCustomer = "Customer"
+)
+
+// Законы, по которым заключён контракт
+const (
+	StateContractLaw44FZ  = "44-FZ"
+	StateContractLaw223FZ = "223-FZ"
+)
+
+// StateContracts — контракты компании со всех полученных страниц
+type StateContracts struct {
+	ContractList []*StateContract `json:"stateContractList"`
+	// Truncated — получены не все страницы из-за предела страниц
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+type StateContract struct {
+	RegistryNumber *string `json:"registryNumber,omitempty"`
+	Law            *string `json:"law,omitempty"`
+	// Role — роль компании в контракте: поставщик или заказчик
+	Role *string `json:"participationRole,omitempty"`
+	// SignDate — дата заключения в формате ISO 8601
+	SignDate *string                   `json:"signDate,omitempty"`
+	Amount   *float64                  `json:"contractPrice,omitempty"`
+	Subject  *string                   `json:"subject,omitempty"`
+	Status   *string                   `json:"contractStatus,omitempty"`
+	Customer *StateContractParticipant `json:"customer,omitempty"`
+	Supplier *StateContractParticipant `json:"supplier,omitempty"`
+}
+
+type StateContractParticipant struct {
+	Name      *string `json:"name,omitempty"`
+	TaxNumber *string `json:"taxNumber,omitempty"`
+}
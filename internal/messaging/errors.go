@@ -0,0 +1,27 @@
+package messaging
+
+import "errors"
+
+// ErrPermanent помечает ошибки, при которых повторная доставка сообщения бесполезна
+var ErrPermanent = errors.New("permanent failure")
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+
+func (e *permanentError) Unwrap() []error { return []error{e.err, ErrPermanent} }
+
+// Permanent оборачивает ошибку обработчика так, чтобы сообщение не доставлялось повторно
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent сообщает, помечена ли ошибка как окончательная
+func IsPermanent(err error) bool {
+	return errors.Is(err, ErrPermanent)
+}
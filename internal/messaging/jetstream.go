@@ -0,0 +1,173 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"scoring_worker/internal/config"
+
+	"github.com/nats-io/nats.go/jetstream"
+	"go.uber.org/zap"
+)
+
+// jetStreamClient получает verification.create через durable pull consumer.
+// Сообщение подтверждается только после успешной обработки, при временных
+// ошибках возвращается в очередь с задержкой, а заведомо неисправимые
+// сообщения снимаются с доставки.
+type jetStreamClient struct {
+	*natsClient
+	js       jetstream.JetStream
+	cfg      *config.JetStreamConfig
+	consumer jetstream.ConsumeContext
+}
+
+func newJetStreamClient(base *natsClient, cfg *config.JetStreamConfig) (NATSClient, error) {
+	js, err := jetstream.New(base.conn)
+	if err != nil {
+		base.conn.Close()
+		return nil, fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+	return &jetStreamClient{natsClient: base, js: js, cfg: cfg}, nil
+}
+
+func (c *jetStreamClient) SubscribeVerificationCreate(ctx context.Context, handler VerificationCreateHandler) error {
+	stream, err := c.ensureStream(ctx)
+	if err != nil {
+		c.logger.Error("failed to ensure JetStream stream", zap.Error(err), zap.String("stream", c.cfg.Stream))
+		return err
+	}
+
+	consumer, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Durable:       c.cfg.Durable,
+		FilterSubject: verificationCreateSubject,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       time.Duration(c.cfg.AckWait) * time.Second,
+		MaxDeliver:    c.cfg.MaxDeliver,
+	})
+	if err != nil {
+		c.logger.Error("failed to create JetStream consumer", zap.Error(err), zap.String("durable", c.cfg.Durable))
+		return fmt.Errorf("failed to create JetStream consumer %s: %w", c.cfg.Durable, err)
+	}
+
+	consumeCtx, err := consumer.Consume(func(msg jetstream.Msg) {
+		c.handleMessage(ctx, msg, handler)
+	})
+	if err != nil {
+		c.logger.Error("failed to consume verification.create", zap.Error(err))
+		return fmt.Errorf("failed to consume verification.create: %w", err)
+	}
+	c.consumer = consumeCtx
+
+	c.logger.Info("subscribed to verification.create via JetStream",
+		zap.String("stream", c.cfg.Stream),
+		zap.String("durable", c.cfg.Durable))
+	return nil
+}
+
+// ensureStream подключается к существующему стриму или создаёт новый
+func (c *jetStreamClient) ensureStream(ctx context.Context) (jetstream.Stream, error) {
+	stream, err := c.js.Stream(ctx, c.cfg.Stream)
+	if err == nil {
+		return stream, nil
+	}
+	if !errors.Is(err, jetstream.ErrStreamNotFound) {
+		return nil, fmt.Errorf("failed to get stream %s: %w", c.cfg.Stream, err)
+	}
+
+	stream, err = c.js.CreateStream(ctx, jetstream.StreamConfig{
+		Name:      c.cfg.Stream,
+		Subjects:  []string{verificationCreateSubject},
+		Retention: jetstream.WorkQueuePolicy,
+		Storage:   jetstream.FileStorage,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stream %s: %w", c.cfg.Stream, err)
+	}
+	c.logger.Info("JetStream stream created", zap.String("stream", c.cfg.Stream))
+	return stream, nil
+}
+
+func (c *jetStreamClient) handleMessage(ctx context.Context, msg jetstream.Msg, handler VerificationCreateHandler) {
+	var m VerificationCreateMessage
+	if err := json.Unmarshal(msg.Data(), &m); err != nil {
+		c.logger.Error("failed to unmarshal verification.create, terminating message", zap.Error(err))
+		c.terminate(msg)
+		return
+	}
+
+	err := handler(m)
+	if err == nil {
+		if ackErr := msg.Ack(); ackErr != nil {
+			c.logger.Error("failed to ack verification.create", zap.Error(ackErr), zap.String("verification_id", m.VerificationID))
+		}
+		return
+	}
+
+	var delivered uint64 = 1
+	if meta, metaErr := msg.Metadata(); metaErr == nil {
+		delivered = meta.NumDelivered
+	}
+
+	if IsPermanent(err) || (c.cfg.MaxDeliver > 0 && delivered >= uint64(c.cfg.MaxDeliver)) {
+		c.logger.Error("verification.create failed permanently, terminating message",
+			zap.Error(err),
+			zap.String("verification_id", m.VerificationID),
+			zap.Uint64("delivered", delivered))
+		c.publishHandlerError(ctx, m.VerificationID, err)
+		c.terminate(msg)
+		return
+	}
+
+	delay := c.nakDelay(delivered)
+	c.logger.Warn("verification.create failed, scheduling redelivery",
+		zap.Error(err),
+		zap.String("verification_id", m.VerificationID),
+		zap.Uint64("delivered", delivered),
+		zap.Duration("delay", delay))
+	if nakErr := msg.NakWithDelay(delay); nakErr != nil {
+		c.logger.Error("failed to nak verification.create", zap.Error(nakErr), zap.String("verification_id", m.VerificationID))
+	}
+}
+
+func (c *jetStreamClient) terminate(msg jetstream.Msg) {
+	if err := msg.Term(); err != nil {
+		c.logger.Error("failed to terminate verification.create", zap.Error(err))
+	}
+}
+
+// nakDelay вычисляет экспоненциальную задержку повторной доставки
+func (c *jetStreamClient) nakDelay(delivered uint64) time.Duration {
+	delay := time.Duration(c.cfg.NakDelay) * time.Second
+	maxDelay := time.Duration(c.cfg.NakMaxDelay) * time.Second
+	for i := uint64(1); i < delivered && delay > 0; i++ {
+		delay *= 2
+		if maxDelay > 0 && delay >= maxDelay {
+			return maxDelay
+		}
+	}
+	return delay
+}
+
+func (c *jetStreamClient) Drain(ctx context.Context) error {
+	if c.consumer == nil {
+		return nil
+	}
+	c.consumer.Drain()
+	select {
+	case <-c.consumer.Closed():
+		c.logger.Info("JetStream consumer drained", zap.String("durable", c.cfg.Durable))
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to drain JetStream consumer: %w", ctx.Err())
+	}
+}
+
+func (c *jetStreamClient) Close() {
+	if c.consumer != nil {
+		c.consumer.Stop()
+	}
+	c.natsClient.Close()
+}
@@ -0,0 +1,235 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"scoring_worker/internal/config"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"go.uber.org/zap/zaptest"
+)
+
+func runJetStreamTestServer(t *testing.T) *server.Server {
+	t.Helper()
+
+	srv, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      server.RANDOM_PORT,
+		NoLog:     true,
+		NoSigs:    true,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("failed to create nats server: %v", err)
+	}
+	go srv.Start()
+	if !srv.ReadyForConnections(5 * time.Second) {
+		t.Fatal("nats server is not ready for connections")
+	}
+	t.Cleanup(srv.Shutdown)
+	return srv
+}
+
+func newTestJetStreamClient(t *testing.T, url string) NATSClient {
+	t.Helper()
+
+	cfg := &config.NATSConfig{
+		URL: url,
+		JetStream: config.JetStreamConfig{
+			Enabled:    true,
+			Stream:     "VERIFICATIONS",
+			Durable:    "scoring_worker",
+			AckWait:    30,
+			MaxDeliver: 5,
+		},
+	}
+	client, err := NewNATSClient(cfg, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create jetstream client: %v", err)
+	}
+	t.Cleanup(client.Close)
+	return client
+}
+
+// subscribeCompleted собирает сообщения verification.completed
+func subscribeCompleted(t *testing.T, url string) <-chan VerificationCompletedMessage {
+	t.Helper()
+
+	conn, err := nats.Connect(url)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(conn.Close)
+
+	ch := make(chan VerificationCompletedMessage, 10)
+	_, err = conn.Subscribe(verificationCompletedSubject, func(msg *nats.Msg) {
+		var m VerificationCompletedMessage
+		if err := json.Unmarshal(msg.Data, &m); err == nil {
+			ch <- m
+		}
+	})
+	if err != nil {
+		t.Fatalf("failed to subscribe to verification.completed: %v", err)
+	}
+	if err := conn.Flush(); err != nil {
+		t.Fatalf("failed to flush: %v", err)
+	}
+	return ch
+}
+
+func TestJetStreamRedeliversOnTransientError(t *testing.T) {
+	srv := runJetStreamTestServer(t)
+	client := newTestJetStreamClient(t, srv.ClientURL())
+
+	var mu sync.Mutex
+	attempts := 0
+	acked := make(chan struct{})
+	err := client.SubscribeVerificationCreate(context.Background(), func(msg VerificationCreateMessage) error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts < 3 {
+			return errors.New("database is unavailable")
+		}
+		close(acked)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	publishVerificationCreate(t, srv.ClientURL(), 1)
+
+	select {
+	case <-acked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for redelivery")
+	}
+
+	// После подтверждения сообщение не должно доставляться повторно
+	time.Sleep(200 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts != 3 {
+		t.Errorf("expected 3 delivery attempts, got %d", attempts)
+	}
+}
+
+func TestJetStreamTerminatesPermanentError(t *testing.T) {
+	srv := runJetStreamTestServer(t)
+	client := newTestJetStreamClient(t, srv.ClientURL())
+	completed := subscribeCompleted(t, srv.ClientURL())
+
+	var mu sync.Mutex
+	attempts := 0
+	err := client.SubscribeVerificationCreate(context.Background(), func(msg VerificationCreateMessage) error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		return Permanent(errors.New("invalid message"))
+	})
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	publishVerificationCreate(t, srv.ClientURL(), 1)
+
+	select {
+	case msg := <-completed:
+		if msg.Status != "ERROR" || msg.VerificationID != "verification-0" {
+			t.Errorf("unexpected completion message: %+v", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for error notification")
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts != 1 {
+		t.Errorf("expected permanent failure to be delivered once, got %d", attempts)
+	}
+}
+
+func TestJetStreamTerminatesMalformedMessage(t *testing.T) {
+	srv := runJetStreamTestServer(t)
+	client := newTestJetStreamClient(t, srv.ClientURL())
+
+	var mu sync.Mutex
+	calls := 0
+	err := client.SubscribeVerificationCreate(context.Background(), func(msg VerificationCreateMessage) error {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	conn, err := nats.Connect(srv.ClientURL())
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer conn.Close()
+	if err := conn.Publish(verificationCreateSubject, []byte("{not json")); err != nil {
+		t.Fatalf("failed to publish: %v", err)
+	}
+	publishVerificationCreate(t, srv.ClientURL(), 1)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		got := calls
+		mu.Unlock()
+		if got == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected valid message to be handled once, got %d calls", got)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	js := client.(*jetStreamClient)
+	info, err := js.js.Stream(context.Background(), "VERIFICATIONS")
+	if err != nil {
+		t.Fatalf("failed to get stream: %v", err)
+	}
+	state, err := info.Info(context.Background())
+	if err != nil {
+		t.Fatalf("failed to get stream info: %v", err)
+	}
+	if state.State.Msgs != 0 {
+		t.Errorf("expected work queue to be empty, got %d messages", state.State.Msgs)
+	}
+}
+
+func TestJetStreamNakDelay(t *testing.T) {
+	c := &jetStreamClient{cfg: &config.JetStreamConfig{NakDelay: 1, NakMaxDelay: 10}}
+
+	tests := []struct {
+		delivered uint64
+		expected  time.Duration
+	}{
+		{delivered: 1, expected: 1 * time.Second},
+		{delivered: 2, expected: 2 * time.Second},
+		{delivered: 3, expected: 4 * time.Second},
+		{delivered: 4, expected: 8 * time.Second},
+		{delivered: 5, expected: 10 * time.Second},
+		{delivered: 50, expected: 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := c.nakDelay(tt.delivered); got != tt.expected {
+			t.Errorf("delivered=%d: expected delay %s, got %s", tt.delivered, tt.expected, got)
+		}
+	}
+}
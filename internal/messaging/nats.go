@@ -4,20 +4,37 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
+
+	"scoring_worker/internal/config"
 
 	"github.com/nats-io/nats.go"
 	"go.uber.org/zap"
 )
 
+// VerificationCreateHandler обрабатывает verification.create. Ошибка означает,
+// что сообщение не было принято: в режиме JetStream оно будет доставлено
+// повторно, если ошибка не помечена через Permanent.
+type VerificationCreateHandler func(VerificationCreateMessage) error
+
 type NATSClient interface {
-	SubscribeVerificationCreate(ctx context.Context, handler func(VerificationCreateMessage)) error
-	PublishVerificationCompleted(ctx context.Context, verificationID string, status string, error string) error
+	SubscribeVerificationCreate(ctx context.Context, handler VerificationCreateHandler) error
+	PublishVerificationCompleted(ctx context.Context, msg VerificationCompletedMessage) error
+	// Drain прекращает приём новых сообщений и дожидается обработки уже полученных
+	Drain(ctx context.Context) error
 	Close()
 }
 
+const (
+	verificationCreateSubject    = "verification.create"
+	verificationCompletedSubject = "verification.completed"
+)
+
 type natsClient struct {
-	conn   *nats.Conn
-	logger *zap.Logger
+	conn       *nats.Conn
+	sub        *nats.Subscription
+	queueGroup string
+	logger     *zap.Logger
 }
 
 type VerificationCreateMessage struct {
@@ -25,63 +42,112 @@ type VerificationCreateMessage struct {
 	INN            string   `json:"inn"`
 	RequestedTypes []string `json:"requested_types"`
 	AuthorEmail    string   `json:"author_email"`
+	// Parameters переопределяет параметры запросов данных для этой проверки
+	Parameters json.RawMessage `json:"parameters,omitempty"`
 }
 
 type VerificationCompletedMessage struct {
 	VerificationID string `json:"verification_id"`
 	Status         string `json:"status"`
 	Error          string `json:"error,omitempty"`
+	// ActiveBankruptcyProcedure передаётся, если запрашивались bankruptcy_messages
+	ActiveBankruptcyProcedure *bool `json:"active_bankruptcy_procedure,omitempty"`
 }
 
-func NewNATSClient(url string, logger *zap.Logger) (NATSClient, error) {
-	conn, err := nats.Connect(url)
+// NewNATSClient подключается к NATS и возвращает клиент core NATS либо,
+// если включён nats.jetstream.enabled, клиент с durable consumer JetStream.
+func NewNATSClient(cfg *config.NATSConfig, logger *zap.Logger) (NATSClient, error) {
+	conn, err := nats.Connect(cfg.URL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
 	}
-	logger.Info("connected to NATS", zap.String("url", url))
-	return &natsClient{conn: conn, logger: logger}, nil
+	logger.Info("connected to NATS",
+		zap.String("url", cfg.URL),
+		zap.String("queue_group", cfg.QueueGroup),
+		zap.Bool("jetstream", cfg.JetStream.Enabled))
+
+	client := &natsClient{conn: conn, queueGroup: cfg.QueueGroup, logger: logger}
+	if cfg.JetStream.Enabled {
+		return newJetStreamClient(client, &cfg.JetStream)
+	}
+	return client, nil
 }
 
-func (c *natsClient) SubscribeVerificationCreate(ctx context.Context, handler func(VerificationCreateMessage)) error {
-	_, err := c.conn.Subscribe("verification.create", func(msg *nats.Msg) {
+// SubscribeVerificationCreate подписывается на verification.create. Если задана
+// queue group, каждое сообщение доставляется только одному экземпляру воркера.
+// В core NATS повторной доставки нет, поэтому ошибка обработчика считается
+// окончательной и о ней сразу публикуется verification.completed со статусом ERROR.
+func (c *natsClient) SubscribeVerificationCreate(ctx context.Context, handler VerificationCreateHandler) error {
+	sub, err := c.conn.QueueSubscribe(verificationCreateSubject, c.queueGroup, func(msg *nats.Msg) {
 		var m VerificationCreateMessage
 		if err := json.Unmarshal(msg.Data, &m); err != nil {
 			c.logger.Error("failed to unmarshal verification.create", zap.Error(err))
 			return
 		}
-		handler(m)
+		if err := handler(m); err != nil {
+			c.logger.Error("failed to handle verification.create", zap.Error(err), zap.String("verification_id", m.VerificationID))
+			c.publishHandlerError(ctx, m.VerificationID, err)
+		}
 	})
 	if err != nil {
 		c.logger.Error("failed to subscribe to verification.create", zap.Error(err))
 		return err
 	}
-	c.logger.Info("subscribed to verification.create")
+	c.sub = sub
+	c.logger.Info("subscribed to verification.create", zap.String("queue_group", c.queueGroup))
 	return nil
 }
 
-func (c *natsClient) PublishVerificationCompleted(ctx context.Context, verificationID string, status string, error string) error {
-	msg := VerificationCompletedMessage{
-		VerificationID: verificationID,
-		Status:         status,
-		Error:          error,
+func (c *natsClient) Drain(ctx context.Context) error {
+	if c.sub == nil {
+		return nil
+	}
+	if err := c.sub.Drain(); err != nil {
+		c.logger.Error("failed to drain verification.create subscription", zap.Error(err))
+		return fmt.Errorf("failed to drain subscription: %w", err)
 	}
 
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for c.sub.IsValid() {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("failed to drain subscription: %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+	c.logger.Info("verification.create subscription drained")
+	return nil
+}
+
+func (c *natsClient) PublishVerificationCompleted(ctx context.Context, msg VerificationCompletedMessage) error {
 	data, err := json.Marshal(msg)
 	if err != nil {
 		c.logger.Error("failed to marshal verification completed message", zap.Error(err))
 		return fmt.Errorf("failed to marshal verification completed message: %w", err)
 	}
 
-	err = c.conn.Publish("verification.completed", data)
+	err = c.conn.Publish(verificationCompletedSubject, data)
 	if err != nil {
-		c.logger.Error("failed to publish verification completed", zap.Error(err), zap.String("verification_id", verificationID))
+		c.logger.Error("failed to publish verification completed", zap.Error(err), zap.String("verification_id", msg.VerificationID))
 		return fmt.Errorf("failed to publish verification completed: %w", err)
 	}
 
-	c.logger.Info("verification completed message published", zap.String("verification_id", verificationID), zap.String("status", status))
+	c.logger.Info("verification completed message published", zap.String("verification_id", msg.VerificationID), zap.String("status", msg.Status))
 	return nil
 }
 
+// publishHandlerError сообщает об окончательной ошибке обработки verification.create
+func (c *natsClient) publishHandlerError(ctx context.Context, verificationID string, err error) {
+	if verificationID == "" {
+		return
+	}
+	completed := VerificationCompletedMessage{VerificationID: verificationID, Status: "ERROR", Error: err.Error()}
+	if pubErr := c.PublishVerificationCompleted(ctx, completed); pubErr != nil {
+		c.logger.Error("failed to publish handler error", zap.Error(pubErr), zap.String("verification_id", verificationID))
+	}
+}
+
 func (c *natsClient) Close() {
 	if c.conn != nil {
 		c.conn.Close()
@@ -0,0 +1,179 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"scoring_worker/internal/config"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"go.uber.org/zap/zaptest"
+)
+
+// runTestServer запускает встроенный NATS сервер на случайном порту
+func runTestServer(t *testing.T) *server.Server {
+	t.Helper()
+
+	srv, err := server.NewServer(&server.Options{
+		Host:   "127.0.0.1",
+		Port:   server.RANDOM_PORT,
+		NoLog:  true,
+		NoSigs: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create nats server: %v", err)
+	}
+	go srv.Start()
+	if !srv.ReadyForConnections(5 * time.Second) {
+		t.Fatal("nats server is not ready for connections")
+	}
+	t.Cleanup(srv.Shutdown)
+	return srv
+}
+
+func publishVerificationCreate(t *testing.T, url string, count int) {
+	t.Helper()
+
+	conn, err := nats.Connect(url)
+	if err != nil {
+		t.Fatalf("failed to connect publisher: %v", err)
+	}
+	defer conn.Close()
+
+	for i := 0; i < count; i++ {
+		data, _ := json.Marshal(VerificationCreateMessage{
+			VerificationID: fmt.Sprintf("verification-%d", i),
+			INN:            "1234567890",
+			RequestedTypes: []string{"basic_information"},
+		})
+		if err := conn.Publish("verification.create", data); err != nil {
+			t.Fatalf("failed to publish: %v", err)
+		}
+	}
+	if err := conn.Flush(); err != nil {
+		t.Fatalf("failed to flush publisher: %v", err)
+	}
+}
+
+func TestSubscribeVerificationCreateQueueGroup(t *testing.T) {
+	srv := runTestServer(t)
+	cfg := &config.NATSConfig{URL: srv.ClientURL(), QueueGroup: "scoring_worker"}
+
+	const messages = 50
+
+	var mu sync.Mutex
+	deliveries := make(map[string]int)
+	perClient := make([]int, 2)
+	var wg sync.WaitGroup
+	wg.Add(messages)
+
+	for i := 0; i < 2; i++ {
+		client, err := NewNATSClient(cfg, zaptest.NewLogger(t))
+		if err != nil {
+			t.Fatalf("failed to create nats client: %v", err)
+		}
+		t.Cleanup(client.Close)
+
+		idx := i
+		err = client.SubscribeVerificationCreate(context.Background(), func(msg VerificationCreateMessage) error {
+			mu.Lock()
+			deliveries[msg.VerificationID]++
+			perClient[idx]++
+			mu.Unlock()
+			wg.Done()
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("failed to subscribe: %v", err)
+		}
+		if err := client.(*natsClient).conn.Flush(); err != nil {
+			t.Fatalf("failed to flush subscription: %v", err)
+		}
+	}
+
+	publishVerificationCreate(t, srv.ClientURL(), messages)
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for messages")
+	}
+
+	// Даём время на возможные дублирующие доставки
+	time.Sleep(100 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(deliveries) != messages {
+		t.Errorf("expected %d distinct messages, got %d", messages, len(deliveries))
+	}
+	for id, count := range deliveries {
+		if count != 1 {
+			t.Errorf("expected message %s to be delivered once, got %d", id, count)
+		}
+	}
+	if perClient[0]+perClient[1] != messages {
+		t.Errorf("expected %d deliveries in total, got %d", messages, perClient[0]+perClient[1])
+	}
+}
+
+func TestSubscribeVerificationCreateWithoutQueueGroup(t *testing.T) {
+	srv := runTestServer(t)
+	cfg := &config.NATSConfig{URL: srv.ClientURL()}
+
+	var mu sync.Mutex
+	total := 0
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	for i := 0; i < 2; i++ {
+		client, err := NewNATSClient(cfg, zaptest.NewLogger(t))
+		if err != nil {
+			t.Fatalf("failed to create nats client: %v", err)
+		}
+		t.Cleanup(client.Close)
+
+		err = client.SubscribeVerificationCreate(context.Background(), func(msg VerificationCreateMessage) error {
+			mu.Lock()
+			total++
+			mu.Unlock()
+			wg.Done()
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("failed to subscribe: %v", err)
+		}
+		if err := client.(*natsClient).conn.Flush(); err != nil {
+			t.Fatalf("failed to flush subscription: %v", err)
+		}
+	}
+
+	publishVerificationCreate(t, srv.ClientURL(), 1)
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for messages")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if total != 2 {
+		t.Errorf("expected every subscriber to receive the message, got %d deliveries", total)
+	}
+}
@@ -0,0 +1,100 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadEmbedded(t *testing.T) {
+	migrations, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("expected embedded migrations, got none")
+	}
+
+	for i, m := range migrations {
+		if i > 0 && migrations[i-1].Version >= m.Version {
+			t.Errorf("migrations are not ordered: %d goes after %d", m.Version, migrations[i-1].Version)
+		}
+		if strings.TrimSpace(m.Up) == "" || strings.TrimSpace(m.Down) == "" {
+			t.Errorf("migration %d_%s has empty up or down script", m.Version, m.Name)
+		}
+	}
+
+	// Базовая миграция должна создавать таблицы, от которых зависит репозиторий
+	for _, table := range []string{"verifications", "verification_data", "verification_data_cache"} {
+		if !strings.Contains(migrations[0].Up, "CREATE TABLE IF NOT EXISTS "+table+" ") {
+			t.Errorf("expected initial migration to create table %s", table)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name             string
+		files            fstest.MapFS
+		expectedVersions []int64
+		expectedError    string
+	}{
+		{
+			name: "ordered_by_version",
+			files: fstest.MapFS{
+				"sql/0010_second.up.sql":   {Data: []byte("SELECT 2")},
+				"sql/0010_second.down.sql": {Data: []byte("SELECT -2")},
+				"sql/0002_first.up.sql":    {Data: []byte("SELECT 1")},
+				"sql/0002_first.down.sql":  {Data: []byte("SELECT -1")},
+			},
+			expectedVersions: []int64{2, 10},
+		},
+		{
+			name: "missing_down",
+			files: fstest.MapFS{
+				"sql/0001_init.up.sql": {Data: []byte("SELECT 1")},
+			},
+			expectedError: "must have both up and down files",
+		},
+		{
+			name: "invalid_file_name",
+			files: fstest.MapFS{
+				"sql/init.sql": {Data: []byte("SELECT 1")},
+			},
+			expectedError: "invalid migration file name",
+		},
+		{
+			name: "conflicting_names",
+			files: fstest.MapFS{
+				"sql/0001_init.up.sql":    {Data: []byte("SELECT 1")},
+				"sql/0001_other.down.sql": {Data: []byte("SELECT -1")},
+			},
+			expectedError: "conflicting names",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := load(tt.files, "sql")
+
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing '%s', but got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(migrations) != len(tt.expectedVersions) {
+				t.Fatalf("expected %d migrations, but got %d", len(tt.expectedVersions), len(migrations))
+			}
+			for i, version := range tt.expectedVersions {
+				if migrations[i].Version != version {
+					t.Errorf("expected migration %d at position %d, but got %d", version, i, migrations[i].Version)
+				}
+			}
+		})
+	}
+}
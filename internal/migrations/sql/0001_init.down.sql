@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS verification_data;
+DROP TABLE IF EXISTS verification_data_cache;
+DROP TABLE IF EXISTS verifications;
@@ -0,0 +1,37 @@
+-- Базовая схема воркера. IF NOT EXISTS позволяет применить миграцию
+-- к окружениям, где таблицы уже были созданы вручную.
+
+CREATE TABLE IF NOT EXISTS verifications (
+    id                   TEXT PRIMARY KEY,
+    inn                  TEXT NOT NULL,
+    status               TEXT NOT NULL,
+    author_email         TEXT NOT NULL DEFAULT '',
+    company_id           TEXT NOT NULL DEFAULT '',
+    requested_data_types TEXT[] NOT NULL DEFAULT '{}',
+    created_at           TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at           TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE verifications ADD COLUMN IF NOT EXISTS owner_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE verifications ADD COLUMN IF NOT EXISTS lease_expires_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS verifications_recovery_idx
+    ON verifications (updated_at)
+    WHERE status IN ('IN_PROCESS', 'PROCESSING');
+
+CREATE TABLE IF NOT EXISTS verification_data_cache (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    data_hash  TEXT NOT NULL UNIQUE,
+    data       JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS verification_data (
+    verification_id TEXT NOT NULL REFERENCES verifications (id) ON DELETE CASCADE,
+    data_type       TEXT NOT NULL,
+    data_hash       TEXT NOT NULL REFERENCES verification_data_cache (data_hash),
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (verification_id, data_type)
+);
+
+CREATE INDEX IF NOT EXISTS verification_data_data_hash_idx ON verification_data (data_hash);
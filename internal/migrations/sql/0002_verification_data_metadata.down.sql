@@ -0,0 +1 @@
+ALTER TABLE verification_data DROP COLUMN IF EXISTS metadata;
@@ -0,0 +1,3 @@
+-- Метаданные получения (статус, время, ошибка) хранятся рядом со ссылкой на кэш,
+-- а в verification_data_cache остаётся только содержимое ответа провайдера.
+ALTER TABLE verification_data ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}';
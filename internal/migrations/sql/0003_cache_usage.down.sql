@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS verification_data_cache_last_used_at_idx;
+ALTER TABLE verification_data_cache DROP COLUMN IF EXISTS last_used_at;
+ALTER TABLE verification_data_cache DROP COLUMN IF EXISTS hit_count;
@@ -0,0 +1,6 @@
+-- Учёт повторных сохранений для расчёта попаданий в кэш и
+-- время последнего использования для сборки мусора.
+ALTER TABLE verification_data_cache ADD COLUMN IF NOT EXISTS hit_count BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE verification_data_cache ADD COLUMN IF NOT EXISTS last_used_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+CREATE INDEX IF NOT EXISTS verification_data_cache_last_used_at_idx ON verification_data_cache (last_used_at);
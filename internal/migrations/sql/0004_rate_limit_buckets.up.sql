@@ -0,0 +1,6 @@
+-- Общие бакеты ограничения частоты запросов к Credinform для всех реплик воркера
+CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+    name       TEXT PRIMARY KEY,
+    tokens     DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS verifications_deferred_idx;
+-- Отложенные проверки возвращаются в обычную очередь восстановления
+UPDATE verifications SET status = 'IN_PROCESS', updated_at = NOW() WHERE status = 'DEFERRED';
@@ -0,0 +1,5 @@
+-- Проверки, отложенные при разомкнутом circuit breaker Credinform,
+-- подбираются восстановлением в порядке откладывания.
+CREATE INDEX IF NOT EXISTS verifications_deferred_idx
+    ON verifications (updated_at)
+    WHERE status = 'DEFERRED';
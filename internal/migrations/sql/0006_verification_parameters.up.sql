@@ -0,0 +1,3 @@
+-- Переопределения параметров запросов данных из verification.create. Хранятся
+-- с проверкой, чтобы восстановление и отложенная обработка использовали те же параметры.
+ALTER TABLE verifications ADD COLUMN IF NOT EXISTS parameters JSONB NOT NULL DEFAULT '{}';
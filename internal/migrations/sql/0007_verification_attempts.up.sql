@@ -0,0 +1,3 @@
+-- Число повторов проверки после временных ошибок провайдера. По нему растёт
+-- задержка перед повтором и ограничивается общее число попыток.
+ALTER TABLE verifications ADD COLUMN IF NOT EXISTS attempts INT NOT NULL DEFAULT 0;
@@ -5,32 +5,54 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"time"
 
-	"github.com/jackc/pgx/v5/pgxpool"
+	"scoring_worker/internal/canonicaljson"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"go.uber.org/zap"
 )
 
+// dbPool — подмножество методов pgxpool.Pool, используемое репозиториями
+type dbPool interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// gcBatchSize ограничивает число записей, удаляемых одним запросом сборки мусора
+const gcBatchSize = 1000
+
+// gcMinAge — наименьший возраст удаляемых записей. Запись, только что сохранённая
+// StoreData, ещё не связана с проверкой через AddData; более свежие записи не
+// удаляются, чтобы ссылка на хэш не оказалась на удалённую запись.
+const gcMinAge = time.Hour
+
 type DataCacheRepository interface {
 	GetDataByHash(ctx context.Context, hash string) (string, error)
 	StoreData(ctx context.Context, data string) (string, error)
 	ComputeHash(data string) string
+	Stats(ctx context.Context) (*CacheStats, error)
+	GC(ctx context.Context, olderThan time.Duration) (int64, error)
 }
 
 type dataCacheRepository struct {
-	db     *pgxpool.Pool
+	db     dbPool
 	logger *zap.Logger
 }
 
-func NewDataCacheRepository(db *pgxpool.Pool, logger *zap.Logger) DataCacheRepository {
+func NewDataCacheRepository(db dbPool, logger *zap.Logger) DataCacheRepository {
 	return &dataCacheRepository{
 		db:     db,
 		logger: logger,
 	}
 }
 
-// ComputeHash вычисляет SHA-256 хэш от JSON строки
+// ComputeHash вычисляет SHA-256 хэш от канонической формы JSON строки,
+// поэтому одинаковые данные с разным порядком ключей и форматированием
+// получают один хэш
 func (r *dataCacheRepository) ComputeHash(data string) string {
-	hash := sha256.Sum256([]byte(data))
+	hash := sha256.Sum256([]byte(canonicaljson.Format(data)))
 	return hex.EncodeToString(hash[:])
 }
 
@@ -49,20 +71,33 @@ func (r *dataCacheRepository) GetDataByHash(ctx context.Context, hash string) (s
 	return data, nil
 }
 
-// StoreData сохраняет данные в кэш и возвращает их хэш
+// StoreData сохраняет каноническую форму данных в кэш и возвращает их хэш.
+// Повторное сохранение тех же данных учитывается как попадание в кэш.
 func (r *dataCacheRepository) StoreData(ctx context.Context, data string) (string, error) {
+	data = canonicaljson.Format(data)
 	hash := r.ComputeHash(data)
 
-	_, err := r.GetDataByHash(ctx, hash)
-	if err == nil {
+	// Сначала пробуем отметить попадание, чтобы не передавать данные повторно
+	tag, err := r.db.Exec(ctx, `
+		UPDATE verification_data_cache
+		SET hit_count = hit_count + 1, last_used_at = NOW()
+		WHERE data_hash = $1
+	`, hash)
+	if err != nil {
+		r.logger.Error("failed to update cache usage", zap.Error(err), zap.String("hash", hash))
+		return "", fmt.Errorf("failed to update cache usage: %w", err)
+	}
+	if tag.RowsAffected() > 0 {
 		r.logger.Debug("data already exists in cache", zap.String("hash", hash))
 		return hash, nil
 	}
 
 	query := `
-		INSERT INTO verification_data_cache (data_hash, data, created_at)
-		VALUES ($1, $2, NOW())
-		ON CONFLICT (data_hash) DO NOTHING
+		INSERT INTO verification_data_cache (data_hash, data, created_at, last_used_at)
+		VALUES ($1, $2, NOW(), NOW())
+		ON CONFLICT (data_hash) DO UPDATE SET
+			hit_count = verification_data_cache.hit_count + 1,
+			last_used_at = NOW()
 	`
 
 	_, err = r.db.Exec(ctx, query, hash, data)
@@ -74,3 +109,76 @@ func (r *dataCacheRepository) StoreData(ctx context.Context, data string) (strin
 	r.logger.Info("data stored in cache", zap.String("hash", hash))
 	return hash, nil
 }
+
+// Stats возвращает статистику использования кэша. Доля попаданий считается
+// по всем сохранениям, доля дедупликации — по ссылкам из verification_data.
+func (r *dataCacheRepository) Stats(ctx context.Context) (*CacheStats, error) {
+	var entries, storageSize, hits int64
+	err := r.db.QueryRow(ctx, `
+		SELECT COUNT(*), COALESCE(SUM(pg_column_size(data)), 0), COALESCE(SUM(hit_count), 0)
+		FROM verification_data_cache
+	`).Scan(&entries, &storageSize, &hits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cache stats: %w", err)
+	}
+
+	var references, distinctHashes int64
+	err = r.db.QueryRow(ctx, `
+		SELECT COUNT(*), COUNT(DISTINCT data_hash)
+		FROM verification_data
+	`).Scan(&references, &distinctHashes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cache references: %w", err)
+	}
+
+	stats := &CacheStats{
+		TotalEntries: entries,
+		StorageSize:  storageSize,
+	}
+	if stores := entries + hits; stores > 0 {
+		stats.CacheHitRate = float64(hits) / float64(stores)
+	}
+	if references > 0 {
+		stats.DeduplicationRate = 1 - float64(distinctHashes)/float64(references)
+	}
+	return stats, nil
+}
+
+// GC удаляет записи кэша, на которые не ссылается ни одна проверка и которые
+// не использовались дольше olderThan, но не меньше gcMinAge. Удаление идёт пачками
+// по gcBatchSize, возвращается общее число удалённых записей.
+func (r *dataCacheRepository) GC(ctx context.Context, olderThan time.Duration) (int64, error) {
+	if olderThan < gcMinAge {
+		r.logger.Warn("cache gc age is below minimum, using minimum",
+			zap.Duration("older_than", olderThan), zap.Duration("min_age", gcMinAge))
+		olderThan = gcMinAge
+	}
+
+	query := `
+		DELETE FROM verification_data_cache
+		WHERE id IN (
+			SELECT c.id
+			FROM verification_data_cache c
+			WHERE c.last_used_at < NOW() - $1 * INTERVAL '1 millisecond'
+				AND NOT EXISTS (SELECT 1 FROM verification_data d WHERE d.data_hash = c.data_hash)
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+	`
+
+	var deleted int64
+	for {
+		tag, err := r.db.Exec(ctx, query, olderThan.Milliseconds(), gcBatchSize)
+		if err != nil {
+			r.logger.Error("failed to collect cache garbage", zap.Error(err), zap.Int64("deleted", deleted))
+			return deleted, fmt.Errorf("failed to collect cache garbage: %w", err)
+		}
+		deleted += tag.RowsAffected()
+		if tag.RowsAffected() < gcBatchSize {
+			break
+		}
+	}
+
+	r.logger.Info("cache garbage collected", zap.Int64("deleted", deleted), zap.Duration("older_than", olderThan))
+	return deleted, nil
+}
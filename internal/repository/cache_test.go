@@ -6,20 +6,17 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
+
+	"scoring_worker/internal/canonicaljson"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
-	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest"
 )
 
-// Интерфейс для pgxpool.Pool
-type dbPool interface {
-	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
-	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
-}
-
 // Mock для pgxpool.Pool
 type mockDBPool struct {
 	queryRowFunc func(ctx context.Context, sql string, args ...any) pgx.Row
@@ -81,56 +78,6 @@ func (m mockCommandTag) String() string {
 	return ""
 }
 
-// Тестовая версия dataCacheRepository
-type testDataCacheRepository struct {
-	db     dbPool
-	logger *zap.Logger
-}
-
-func (r *testDataCacheRepository) ComputeHash(data string) string {
-	hash := sha256.Sum256([]byte(data))
-	return hex.EncodeToString(hash[:])
-}
-
-func (r *testDataCacheRepository) GetDataByHash(ctx context.Context, hash string) (string, error) {
-	query := `SELECT data FROM verification_data_cache WHERE data_hash = $1`
-
-	var data string
-	err := r.db.QueryRow(ctx, query, hash).Scan(&data)
-	if err != nil {
-		r.logger.Debug("data not found in cache", zap.String("hash", hash), zap.Error(err))
-		return "", fmt.Errorf("data not found in cache for hash %s: %w", hash, err)
-	}
-
-	r.logger.Debug("data retrieved from cache", zap.String("hash", hash))
-	return data, nil
-}
-
-func (r *testDataCacheRepository) StoreData(ctx context.Context, data string) (string, error) {
-	hash := r.ComputeHash(data)
-
-	_, err := r.GetDataByHash(ctx, hash)
-	if err == nil {
-		r.logger.Debug("data already exists in cache", zap.String("hash", hash))
-		return hash, nil
-	}
-
-	query := `
-		INSERT INTO verification_data_cache (data_hash, data, created_at)
-		VALUES ($1, $2, NOW())
-		ON CONFLICT (data_hash) DO NOTHING
-	`
-
-	_, err = r.db.Exec(ctx, query, hash, data)
-	if err != nil {
-		r.logger.Error("failed to store data in cache", zap.Error(err), zap.String("hash", hash))
-		return "", fmt.Errorf("failed to store data in cache: %w", err)
-	}
-
-	r.logger.Info("data stored in cache", zap.String("hash", hash))
-	return hash, nil
-}
-
 func TestComputeHash(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -140,7 +87,7 @@ func TestComputeHash(t *testing.T) {
 		{
 			name:         "simple_json",
 			data:         `{"test": "data"}`,
-			expectedHash: computeExpectedHash(`{"test": "data"}`),
+			expectedHash: computeExpectedHash(`{"test":"data"}`),
 		},
 		{
 			name:         "empty_string",
@@ -150,17 +97,17 @@ func TestComputeHash(t *testing.T) {
 		{
 			name:         "complex_json",
 			data:         `{"company": {"name": "Test Corp", "inn": "1234567890", "data": [1,2,3]}}`,
-			expectedHash: computeExpectedHash(`{"company": {"name": "Test Corp", "inn": "1234567890", "data": [1,2,3]}}`),
+			expectedHash: computeExpectedHash(`{"company":{"data":[1,2,3],"inn":"1234567890","name":"Test Corp"}}`),
 		},
 		{
 			name:         "same_data_same_hash",
 			data:         `{"test": "data"}`,
-			expectedHash: computeExpectedHash(`{"test": "data"}`),
+			expectedHash: computeExpectedHash(`{"test":"data"}`),
 		},
 	}
 
 	logger := zaptest.NewLogger(t)
-	repo := &testDataCacheRepository{
+	repo := &dataCacheRepository{
 		db:     nil,
 		logger: logger,
 	}
@@ -238,7 +185,7 @@ func TestGetDataByHash(t *testing.T) {
 			}
 
 			logger := zaptest.NewLogger(t)
-			repo := &testDataCacheRepository{
+			repo := &dataCacheRepository{
 				db:     mockPool,
 				logger: logger,
 			}
@@ -273,7 +220,7 @@ func TestStoreData(t *testing.T) {
 		name          string
 		data          string
 		existsInCache bool
-		getDataError  error
+		updateError   error
 		execError     error
 		expectedError string
 		expectedHash  string
@@ -282,21 +229,24 @@ func TestStoreData(t *testing.T) {
 			name:          "successful_store_new_data",
 			data:          `{"test": "data"}`,
 			existsInCache: false,
-			getDataError:  pgx.ErrNoRows,
-			expectedHash:  computeExpectedHash(`{"test": "data"}`),
+			expectedHash:  computeExpectedHash(`{"test":"data"}`),
 		},
 		{
 			name:          "data_already_exists",
 			data:          `{"test": "data"}`,
 			existsInCache: true,
-			getDataError:  nil,
-			expectedHash:  computeExpectedHash(`{"test": "data"}`),
+			expectedHash:  computeExpectedHash(`{"test":"data"}`),
+		},
+		{
+			name:          "database_update_error",
+			data:          `{"test": "data"}`,
+			updateError:   errors.New("database error"),
+			expectedError: "failed to update cache usage",
 		},
 		{
 			name:          "database_exec_error",
 			data:          `{"test": "data"}`,
 			existsInCache: false,
-			getDataError:  pgx.ErrNoRows,
 			execError:     errors.New("database error"),
 			expectedError: "failed to store data in cache",
 		},
@@ -306,24 +256,21 @@ func TestStoreData(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			var storedHash string
 			var storedData string
+			var inserted bool
 
 			mockPool := &mockDBPool{
-				queryRowFunc: func(ctx context.Context, sql string, args ...any) pgx.Row {
-					return &mockRow{
-						scanFunc: func(dest ...any) error {
-							if tt.existsInCache {
-								if len(dest) > 0 {
-									if strPtr, ok := dest[0].(*string); ok {
-										*strPtr = tt.data
-									}
-								}
-								return nil
-							}
-							return tt.getDataError
-						},
-					}
-				},
 				execFunc: func(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+					if strings.Contains(sql, "UPDATE verification_data_cache") {
+						if tt.updateError != nil {
+							return pgconn.CommandTag{}, tt.updateError
+						}
+						if tt.existsInCache {
+							return pgconn.NewCommandTag("UPDATE 1"), nil
+						}
+						return pgconn.NewCommandTag("UPDATE 0"), nil
+					}
+
+					inserted = true
 					if len(args) >= 2 {
 						if hash, ok := args[0].(string); ok {
 							storedHash = hash
@@ -335,12 +282,12 @@ func TestStoreData(t *testing.T) {
 					if tt.execError != nil {
 						return pgconn.CommandTag{}, tt.execError
 					}
-					return pgconn.CommandTag{}, nil
+					return pgconn.NewCommandTag("INSERT 0 1"), nil
 				},
 			}
 
 			logger := zaptest.NewLogger(t)
-			repo := &testDataCacheRepository{
+			repo := &dataCacheRepository{
 				db:     mockPool,
 				logger: logger,
 			}
@@ -367,13 +314,16 @@ func TestStoreData(t *testing.T) {
 				t.Errorf("expected hash '%s', but got '%s'", tt.expectedHash, hash)
 			}
 
-			// Проверяем, что данные были сохранены в БД (если не существовали)
-			if !tt.existsInCache && tt.execError == nil {
+			// Данные записываются в БД только если их ещё нет в кэше
+			if inserted == tt.existsInCache {
+				t.Errorf("expected insert=%v, but got %v", !tt.existsInCache, inserted)
+			}
+			if !tt.existsInCache {
 				if storedHash != tt.expectedHash {
 					t.Errorf("expected stored hash '%s', but got '%s'", tt.expectedHash, storedHash)
 				}
-				if storedData != tt.data {
-					t.Errorf("expected stored data '%s', but got '%s'", tt.data, storedData)
+				if storedData != canonicaljson.Format(tt.data) {
+					t.Errorf("expected stored data '%s', but got '%s'", canonicaljson.Format(tt.data), storedData)
 				}
 			}
 		})
@@ -382,38 +332,30 @@ func TestStoreData(t *testing.T) {
 
 func TestStoreDataDeduplication(t *testing.T) {
 	testData := `{"company": "Test Corp", "inn": "1234567890"}`
-	expectedHash := computeExpectedHash(testData)
+	expectedHash := computeExpectedHash(`{"company":"Test Corp","inn":"1234567890"}`)
 
-	var getDataCallCount int
-	var execCallCount int
+	stored := make(map[string]bool)
+	var insertCallCount int
+	var hitCount int
 
 	mockPool := &mockDBPool{
-		queryRowFunc: func(ctx context.Context, sql string, args ...any) pgx.Row {
-			getDataCallCount++
-			return &mockRow{
-				scanFunc: func(dest ...any) error {
-					if getDataCallCount == 1 {
-						// Первый вызов - данных нет
-						return pgx.ErrNoRows
-					}
-					// Второй вызов - данные уже есть
-					if len(dest) > 0 {
-						if strPtr, ok := dest[0].(*string); ok {
-							*strPtr = testData
-						}
-					}
-					return nil
-				},
-			}
-		},
 		execFunc: func(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
-			execCallCount++
-			return pgconn.CommandTag{}, nil
+			hash := args[0].(string)
+			if strings.Contains(sql, "UPDATE verification_data_cache") {
+				if stored[hash] {
+					hitCount++
+					return pgconn.NewCommandTag("UPDATE 1"), nil
+				}
+				return pgconn.NewCommandTag("UPDATE 0"), nil
+			}
+			insertCallCount++
+			stored[hash] = true
+			return pgconn.NewCommandTag("INSERT 0 1"), nil
 		},
 	}
 
 	logger := zaptest.NewLogger(t)
-	repo := &testDataCacheRepository{
+	repo := &dataCacheRepository{
 		db:     mockPool,
 		logger: logger,
 	}
@@ -425,8 +367,8 @@ func TestStoreDataDeduplication(t *testing.T) {
 		return
 	}
 
-	// Второе сохранение тех же данных - должно найти в кэше
-	hash2, err := repo.StoreData(context.Background(), testData)
+	// Второе сохранение тех же данных в другом форматировании - должно найти в кэше
+	hash2, err := repo.StoreData(context.Background(), `{"inn":"1234567890","company":"Test Corp"}`)
 	if err != nil {
 		t.Errorf("unexpected error on second store: %v", err)
 		return
@@ -441,14 +383,180 @@ func TestStoreDataDeduplication(t *testing.T) {
 		t.Errorf("expected hash '%s', but got '%s'", expectedHash, hash1)
 	}
 
-	// Проверяем, что exec был вызван только один раз (для первого сохранения)
-	if execCallCount != 1 {
-		t.Errorf("expected exec to be called once, but was called %d times", execCallCount)
+	// Проверяем, что вставка была выполнена только один раз (для первого сохранения)
+	if insertCallCount != 1 {
+		t.Errorf("expected insert to be called once, but was called %d times", insertCallCount)
+	}
+
+	// Повторное сохранение учитывается как попадание
+	if hitCount != 1 {
+		t.Errorf("expected one cache hit, but got %d", hitCount)
+	}
+}
+
+func TestStats(t *testing.T) {
+	tests := []struct {
+		name          string
+		cacheRow      []int64
+		referencesRow []int64
+		queryError    error
+		expected      *CacheStats
+		expectedError string
+	}{
+		{
+			name:          "populated_cache",
+			cacheRow:      []int64{4, 2048, 6},
+			referencesRow: []int64{10, 4},
+			expected: &CacheStats{
+				TotalEntries:      4,
+				StorageSize:       2048,
+				CacheHitRate:      0.6,
+				DeduplicationRate: 0.6,
+			},
+		},
+		{
+			name:          "empty_cache",
+			cacheRow:      []int64{0, 0, 0},
+			referencesRow: []int64{0, 0},
+			expected:      &CacheStats{},
+		},
+		{
+			name:          "database_error",
+			queryError:    errors.New("database error"),
+			expectedError: "failed to query cache stats",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPool := &mockDBPool{
+				queryRowFunc: func(ctx context.Context, sql string, args ...any) pgx.Row {
+					values := tt.cacheRow
+					if strings.Contains(sql, "FROM verification_data\n") {
+						values = tt.referencesRow
+					}
+					return &mockRow{
+						scanFunc: func(dest ...any) error {
+							if tt.queryError != nil {
+								return tt.queryError
+							}
+							for i, d := range dest {
+								*(d.(*int64)) = values[i]
+							}
+							return nil
+						},
+					}
+				},
+			}
+
+			repo := &dataCacheRepository{db: mockPool, logger: zaptest.NewLogger(t)}
+			stats, err := repo.Stats(context.Background())
+
+			if tt.expectedError != "" {
+				if err == nil || !containsError(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing '%s', but got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if *stats != *tt.expected {
+				t.Errorf("expected stats %+v, but got %+v", *tt.expected, *stats)
+			}
+		})
+	}
+}
+
+func TestGC(t *testing.T) {
+	tests := []struct {
+		name              string
+		olderThan         time.Duration
+		batches           []int64
+		execError         error
+		expectedOlderThan time.Duration
+		expectedDeleted   int64
+		expectedCalls     int
+		expectedError     string
+	}{
+		{
+			name:              "single_partial_batch",
+			olderThan:         24 * time.Hour,
+			expectedOlderThan: 24 * time.Hour,
+			batches:           []int64{10},
+			expectedDeleted:   10,
+			expectedCalls:     1,
+		},
+		{
+			name:              "multiple_batches",
+			olderThan:         24 * time.Hour,
+			expectedOlderThan: 24 * time.Hour,
+			batches:           []int64{gcBatchSize, gcBatchSize, 5},
+			expectedDeleted:   2*gcBatchSize + 5,
+			expectedCalls:     3,
+		},
+		{
+			name:              "nothing_to_delete",
+			olderThan:         24 * time.Hour,
+			expectedOlderThan: 24 * time.Hour,
+			batches:           []int64{0},
+			expectedDeleted:   0,
+			expectedCalls:     1,
+		},
+		{
+			name:              "database_error",
+			olderThan:         24 * time.Hour,
+			expectedOlderThan: 24 * time.Hour,
+			execError:         errors.New("database error"),
+			expectedCalls:     1,
+			expectedError:     "failed to collect cache garbage",
+		},
+		{
+			// Свежие записи могут ещё не иметь ссылок из verification_data
+			name:              "below_min_age",
+			olderThan:         0,
+			batches:           []int64{3},
+			expectedOlderThan: gcMinAge,
+			expectedDeleted:   3,
+			expectedCalls:     1,
+		},
 	}
 
-	// Проверяем, что getDataByHash был вызван два раза
-	if getDataCallCount != 2 {
-		t.Errorf("expected getDataByHash to be called twice, but was called %d times", getDataCallCount)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			mockPool := &mockDBPool{
+				execFunc: func(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+					calls++
+					if tt.execError != nil {
+						return pgconn.CommandTag{}, tt.execError
+					}
+					if args[0] != tt.expectedOlderThan.Milliseconds() {
+						t.Errorf("expected olderThan %d ms, got %v", tt.expectedOlderThan.Milliseconds(), args[0])
+					}
+					return pgconn.NewCommandTag(fmt.Sprintf("DELETE %d", tt.batches[calls-1])), nil
+				},
+			}
+
+			repo := &dataCacheRepository{db: mockPool, logger: zaptest.NewLogger(t)}
+			deleted, err := repo.GC(context.Background(), tt.olderThan)
+
+			if calls != tt.expectedCalls {
+				t.Errorf("expected %d delete batches, but got %d", tt.expectedCalls, calls)
+			}
+			if tt.expectedError != "" {
+				if err == nil || !containsError(err.Error(), tt.expectedError) {
+					t.Errorf("expected error containing '%s', but got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if deleted != tt.expectedDeleted {
+				t.Errorf("expected %d deleted entries, but got %d", tt.expectedDeleted, deleted)
+			}
+		})
 	}
 }
 
@@ -463,7 +571,7 @@ func TestHashConsistency(t *testing.T) {
 	}
 
 	logger := zaptest.NewLogger(t)
-	repo := &testDataCacheRepository{
+	repo := &dataCacheRepository{
 		db:     nil,
 		logger: logger,
 	}
@@ -485,6 +593,23 @@ func TestHashConsistency(t *testing.T) {
 	}
 }
 
+func TestComputeHashIgnoresFormatting(t *testing.T) {
+	repo := &dataCacheRepository{logger: zaptest.NewLogger(t)}
+
+	// Одинаковые ответы с разным порядком ключей и отступами должны дедуплицироваться
+	first := repo.ComputeHash(`{"inn": "1234567890", "name": "Test Corp", "okved": ["62.01", "62.02"]}`)
+	second := repo.ComputeHash("{\n  \"okved\": [\"62.01\", \"62.02\"],\n  \"name\": \"Test Corp\",\n  \"inn\": \"1234567890\"\n}")
+	if first != second {
+		t.Errorf("expected equal hashes for equivalent JSON, got '%s' and '%s'", first, second)
+	}
+
+	// Порядок элементов массива значим
+	reordered := repo.ComputeHash(`{"inn": "1234567890", "name": "Test Corp", "okved": ["62.02", "62.01"]}`)
+	if first == reordered {
+		t.Error("expected different hashes for different array order")
+	}
+}
+
 // Вспомогательная функция для вычисления ожидаемого хэша
 func computeExpectedHash(data string) string {
 	hash := sha256.Sum256([]byte(data))
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// RateLimitRepository хранит token bucket в PostgreSQL, чтобы несколько реплик
+// воркера делили один лимит запросов
+type RateLimitRepository interface {
+	TakeToken(ctx context.Context, name string, requestsPerSecond float64, burst int) (time.Duration, error)
+}
+
+type rateLimitRepository struct {
+	db     *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewRateLimitRepository(db *pgxpool.Pool, logger *zap.Logger) RateLimitRepository {
+	return &rateLimitRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// TakeToken пополняет бакет за прошедшее время и забирает из него токен.
+// Строка бакета блокируется на время транзакции, поэтому реплики не
+// расходуют один и тот же токен. Возвращает 0, если токен получен,
+// иначе время до появления следующего токена.
+func (r *rateLimitRepository) TakeToken(ctx context.Context, name string, requestsPerSecond float64, burst int) (time.Duration, error) {
+	var delay time.Duration
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO rate_limit_buckets (name, tokens, updated_at)
+			VALUES ($1, $2, clock_timestamp())
+			ON CONFLICT (name) DO NOTHING
+		`, name, float64(burst))
+		if err != nil {
+			return err
+		}
+
+		var tokens float64
+		var updatedAt, now time.Time
+		err = tx.QueryRow(ctx, `
+			SELECT tokens, updated_at, clock_timestamp()
+			FROM rate_limit_buckets
+			WHERE name = $1
+			FOR UPDATE
+		`, name).Scan(&tokens, &updatedAt, &now)
+		if err != nil {
+			return err
+		}
+
+		var remaining float64
+		remaining, delay = takeToken(tokens, now.Sub(updatedAt), requestsPerSecond, burst)
+
+		_, err = tx.Exec(ctx, `
+			UPDATE rate_limit_buckets SET tokens = $2, updated_at = $3 WHERE name = $1
+		`, name, remaining, now)
+		return err
+	})
+	if err != nil {
+		r.logger.Error("failed to take rate limit token", zap.Error(err), zap.String("bucket", name))
+		return 0, fmt.Errorf("failed to take rate limit token from %s: %w", name, err)
+	}
+	return delay, nil
+}
+
+// takeToken пополняет бакет за elapsed и забирает токен, если он есть.
+// Возвращает оставшееся число токенов и задержку до следующего токена.
+func takeToken(tokens float64, elapsed time.Duration, requestsPerSecond float64, burst int) (float64, time.Duration) {
+	if elapsed > 0 {
+		tokens += elapsed.Seconds() * requestsPerSecond
+	}
+	if capacity := float64(burst); tokens > capacity {
+		tokens = capacity
+	}
+	if tokens >= 1 {
+		return tokens - 1, 0
+	}
+	return tokens, time.Duration((1 - tokens) / requestsPerSecond * float64(time.Second))
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTakeToken(t *testing.T) {
+	tests := []struct {
+		name              string
+		tokens            float64
+		elapsed           time.Duration
+		requestsPerSecond float64
+		burst             int
+		expectedRemaining float64
+		expectedDelay     time.Duration
+	}{
+		{
+			name:              "full_bucket",
+			tokens:            5,
+			requestsPerSecond: 10,
+			burst:             5,
+			expectedRemaining: 4,
+		},
+		{
+			name:              "refill_capped_by_burst",
+			tokens:            0,
+			elapsed:           time.Minute,
+			requestsPerSecond: 10,
+			burst:             5,
+			expectedRemaining: 4,
+		},
+		{
+			name:              "partial_refill_is_enough",
+			tokens:            0.5,
+			elapsed:           100 * time.Millisecond,
+			requestsPerSecond: 5,
+			burst:             5,
+			expectedRemaining: 0,
+		},
+		{
+			name:              "empty_bucket_waits",
+			tokens:            0,
+			requestsPerSecond: 4,
+			burst:             1,
+			expectedRemaining: 0,
+			expectedDelay:     250 * time.Millisecond,
+		},
+		{
+			name:              "partially_filled_bucket_waits_for_rest",
+			tokens:            0.5,
+			requestsPerSecond: 1,
+			burst:             1,
+			expectedRemaining: 0.5,
+			expectedDelay:     500 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remaining, delay := takeToken(tt.tokens, tt.elapsed, tt.requestsPerSecond, tt.burst)
+			if remaining != tt.expectedRemaining {
+				t.Errorf("expected %v remaining tokens, got %v", tt.expectedRemaining, remaining)
+			}
+			if delay != tt.expectedDelay {
+				t.Errorf("expected delay %s, got %s", tt.expectedDelay, delay)
+			}
+		})
+	}
+}
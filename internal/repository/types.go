@@ -1,6 +1,9 @@
 package repository
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // VerificationDataCache представляет запись в таблице verification_data_cache
 type VerificationDataCache struct {
@@ -17,3 +20,14 @@ type CacheStats struct {
 	StorageSize       int64   `json:"storage_size_bytes"`
 	DeduplicationRate float64 `json:"deduplication_rate"`
 }
+
+// DataMetadata описывает конкретное получение данных. Хранится в verification_data
+// отдельно от содержимого, чтобы изменчивые поля не влияли на хэш в кэше.
+type DataMetadata struct {
+	Status      string    `json:"status"`
+	CompanyID   string    `json:"company_id"`
+	ProcessedAt time.Time `json:"processed_at"`
+	Error       string    `json:"error,omitempty"`
+	// Parameters — параметры запроса к провайдеру, с которыми получены данные
+	Parameters json.RawMessage `json:"parameters,omitempty"`
+}
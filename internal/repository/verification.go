@@ -2,57 +2,99 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"go.uber.org/zap"
 )
 
+// ErrAlreadyExists возвращается Create, если проверка с таким ID уже существует
+var ErrAlreadyExists = errors.New("verification already exists")
+
+// ErrLeaseLost возвращается RenewLease, если проверка больше не принадлежит воркеру
+var ErrLeaseLost = errors.New("verification lease lost")
+
+const uniqueViolationCode = "23505"
+
 type VerificationRepository interface {
-	Create(ctx context.Context, id string, inn string, requestedTypes []string, authorEmail string) error
+	Create(ctx context.Context, id string, inn string, requestedTypes []string, authorEmail string, parameters json.RawMessage) error
 	UpdateStatus(ctx context.Context, id string, status string) error
 	UpdateCompanyID(ctx context.Context, id string, companyID string) error
-	AddData(ctx context.Context, verificationID string, dataType string, data string) error
+	AddData(ctx context.Context, verificationID string, dataType string, data string, metadata DataMetadata) error
 	GetByID(ctx context.Context, id string) (*Verification, error)
 	AcquireStaleVerification(ctx context.Context) (*Verification, error)
+	AcquireDeferredVerification(ctx context.Context) (*Verification, error)
+	ReleaseVerification(ctx context.Context, id string) error
+	RetryVerification(ctx context.Context, id string) (exhausted bool, err error)
+	DeferVerification(ctx context.Context, id string) error
+	RenewLease(ctx context.Context, id string) error
+}
+
+// LeaseConfig определяет владельца проверок и срок аренды. Проверка принадлежит
+// воркеру, пока он продлевает аренду; просроченную аренду может перехватить другой воркер.
+type LeaseConfig struct {
+	OwnerID string
+	TTL     time.Duration
+	// MaxAttempts ограничивает число повторов после временных ошибок; 0 — без ограничения
+	MaxAttempts int
+	// RetryDelay — задержка перед первым повтором, удваивается с каждой попыткой
+	// до RetryMaxDelay
+	RetryDelay    time.Duration
+	RetryMaxDelay time.Duration
 }
 
 type Verification struct {
-	ID                 string    `json:"id"`
-	Inn                string    `json:"inn"`
-	Status             string    `json:"status"`
-	AuthorEmail        string    `json:"author_email"`
-	CompanyID          string    `json:"company_id"`
-	RequestedDataTypes []string  `json:"requested_data_types"`
-	CreatedAt          time.Time `json:"created_at"`
-	UpdatedAt          time.Time `json:"updated_at"`
+	ID                 string   `json:"id"`
+	Inn                string   `json:"inn"`
+	Status             string   `json:"status"`
+	AuthorEmail        string   `json:"author_email"`
+	CompanyID          string   `json:"company_id"`
+	RequestedDataTypes []string `json:"requested_data_types"`
+	// Parameters — переопределения параметров запросов данных из verification.create
+	Parameters     json.RawMessage `json:"parameters,omitempty"`
+	OwnerID        string          `json:"owner_id"`
+	LeaseExpiresAt *time.Time      `json:"lease_expires_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
 }
 
 type verificationRepository struct {
-	db        *pgxpool.Pool
+	db        dbPool
 	cacheRepo DataCacheRepository
+	lease     LeaseConfig
 	logger    *zap.Logger
 }
 
-func NewVerificationRepository(db *pgxpool.Pool, cacheRepo DataCacheRepository, logger *zap.Logger) VerificationRepository {
+func NewVerificationRepository(db dbPool, cacheRepo DataCacheRepository, lease LeaseConfig, logger *zap.Logger) VerificationRepository {
 	return &verificationRepository{
 		db:        db,
 		cacheRepo: cacheRepo,
+		lease:     lease,
 		logger:    logger,
 	}
 }
 
-func (r *verificationRepository) Create(ctx context.Context, id string, inn string, requestedTypes []string, authorEmail string) error {
+func (r *verificationRepository) Create(ctx context.Context, id string, inn string, requestedTypes []string, authorEmail string, parameters json.RawMessage) error {
 	now := time.Now().Format(time.RFC3339)
+	if len(parameters) == 0 {
+		parameters = json.RawMessage("{}")
+	}
 
 	query := `
-		INSERT INTO verifications (id, inn, status, author_email, requested_data_types, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO verifications (id, inn, status, author_email, requested_data_types, parameters, owner_id, lease_expires_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW() + $8 * INTERVAL '1 millisecond', $9, $10)
 	`
 
-	_, err := r.db.Exec(ctx, query, id, inn, "IN_PROCESS", authorEmail, requestedTypes, now, now)
+	_, err := r.db.Exec(ctx, query, id, inn, "IN_PROCESS", authorEmail, requestedTypes, string(parameters), r.lease.OwnerID, r.lease.TTL.Milliseconds(), now, now)
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+			return fmt.Errorf("%w: %s", ErrAlreadyExists, id)
+		}
 		r.logger.Error("failed to create verification", zap.Error(err), zap.String("id", id), zap.String("inn", inn))
 		return fmt.Errorf("failed to create verification: %w", err)
 	}
@@ -89,8 +131,14 @@ func (r *verificationRepository) UpdateCompanyID(ctx context.Context, id string,
 	return nil
 }
 
-// AddData добавляет данные проверки с использованием системы кэширования
-func (r *verificationRepository) AddData(ctx context.Context, verificationID string, dataType string, data string) error {
+// AddData добавляет данные проверки с использованием системы кэширования.
+// В кэш попадает только содержимое, метаданные сохраняются в verification_data.
+func (r *verificationRepository) AddData(ctx context.Context, verificationID string, dataType string, data string, metadata DataMetadata) error {
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data metadata: %w", err)
+	}
+
 	dataHash, err := r.cacheRepo.StoreData(ctx, data)
 	if err != nil {
 		r.logger.Error("failed to store data in cache", zap.Error(err), zap.String("verification_id", verificationID))
@@ -98,14 +146,15 @@ func (r *verificationRepository) AddData(ctx context.Context, verificationID str
 	}
 
 	query := `
-		INSERT INTO verification_data (verification_id, data_type, data_hash, created_at)
-		VALUES ($1, $2, $3, NOW())
+		INSERT INTO verification_data (verification_id, data_type, data_hash, metadata, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
 		ON CONFLICT (verification_id, data_type) DO UPDATE SET
 			data_hash = EXCLUDED.data_hash,
+			metadata = EXCLUDED.metadata,
 			created_at = NOW()
 	`
 
-	_, err = r.db.Exec(ctx, query, verificationID, dataType, dataHash)
+	_, err = r.db.Exec(ctx, query, verificationID, dataType, dataHash, string(metadataJSON))
 	if err != nil {
 		r.logger.Error("failed to add verification data", zap.Error(err), zap.String("verification_id", verificationID))
 		return fmt.Errorf("failed to add verification data: %w", err)
@@ -117,14 +166,14 @@ func (r *verificationRepository) AddData(ctx context.Context, verificationID str
 
 func (r *verificationRepository) GetByID(ctx context.Context, id string) (*Verification, error) {
 	query := `
-		SELECT id, inn, status, author_email, company_id, requested_data_types, created_at, updated_at
+		SELECT id, inn, status, author_email, company_id, requested_data_types, parameters, owner_id, lease_expires_at, created_at, updated_at
 		FROM verifications
 		WHERE id = $1
 	`
 
 	var verification Verification
 	err := r.db.QueryRow(ctx, query, id).
-		Scan(&verification.ID, &verification.Inn, &verification.Status, &verification.AuthorEmail, &verification.CompanyID, &verification.RequestedDataTypes, &verification.CreatedAt, &verification.UpdatedAt)
+		Scan(&verification.ID, &verification.Inn, &verification.Status, &verification.AuthorEmail, &verification.CompanyID, &verification.RequestedDataTypes, &verification.Parameters, &verification.OwnerID, &verification.LeaseExpiresAt, &verification.CreatedAt, &verification.UpdatedAt)
 	if err != nil {
 		r.logger.Error("failed to get verification", zap.Error(err), zap.String("id", id))
 		return nil, fmt.Errorf("failed to get verification: %w", err)
@@ -133,28 +182,31 @@ func (r *verificationRepository) GetByID(ctx context.Context, id string) (*Verif
 	return &verification, nil
 }
 
+// AcquireStaleVerification захватывает незавершённую проверку, аренда которой
+// истекла или не была выдана, и оформляет её на текущего воркера
 func (r *verificationRepository) AcquireStaleVerification(ctx context.Context) (*Verification, error) {
 	query := `
 		UPDATE verifications
-		SET status = 'PROCESSING', updated_at = NOW()
+		SET status = 'PROCESSING', owner_id = $1, lease_expires_at = NOW() + $2 * INTERVAL '1 millisecond', updated_at = NOW()
 		WHERE id = (
 			SELECT id
 			FROM verifications
-			WHERE status = 'IN_PROCESS' OR status = 'PROCESSING'
+			WHERE (status = 'IN_PROCESS' OR status = 'PROCESSING')
+				AND (lease_expires_at IS NULL OR lease_expires_at < NOW())
 			ORDER BY updated_at ASC
 			FOR UPDATE SKIP LOCKED
 			LIMIT 1
 		)
-		RETURNING id, inn, status, author_email, company_id, requested_data_types, created_at, updated_at
+		RETURNING id, inn, status, author_email, company_id, requested_data_types, parameters, owner_id, lease_expires_at, created_at, updated_at
 	`
 	var v Verification
-	err := r.db.QueryRow(ctx, query).Scan(
+	err := r.db.QueryRow(ctx, query, r.lease.OwnerID, r.lease.TTL.Milliseconds()).Scan(
 		&v.ID, &v.Inn, &v.Status, &v.AuthorEmail, &v.CompanyID,
-		&v.RequestedDataTypes, &v.CreatedAt, &v.UpdatedAt,
+		&v.RequestedDataTypes, &v.Parameters, &v.OwnerID, &v.LeaseExpiresAt, &v.CreatedAt, &v.UpdatedAt,
 	)
 
 	if err != nil {
-		if err.Error() == "no rows in result set" { // pgx specific error
+		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil // No stale verification found, not an error
 		}
 		r.logger.Error("failed to acquire stale verification", zap.Error(err))
@@ -163,3 +215,136 @@ func (r *verificationRepository) AcquireStaleVerification(ctx context.Context) (
 
 	return &v, nil
 }
+
+// AcquireDeferredVerification захватывает самую давнюю из отложенных проверок
+// и оформляет её на текущего воркера
+func (r *verificationRepository) AcquireDeferredVerification(ctx context.Context) (*Verification, error) {
+	query := `
+		UPDATE verifications
+		SET status = 'PROCESSING', owner_id = $1, lease_expires_at = NOW() + $2 * INTERVAL '1 millisecond', updated_at = NOW()
+		WHERE id = (
+			SELECT id
+			FROM verifications
+			WHERE status = 'DEFERRED'
+			ORDER BY updated_at ASC
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING id, inn, status, author_email, company_id, requested_data_types, parameters, owner_id, lease_expires_at, created_at, updated_at
+	`
+	var v Verification
+	err := r.db.QueryRow(ctx, query, r.lease.OwnerID, r.lease.TTL.Milliseconds()).Scan(
+		&v.ID, &v.Inn, &v.Status, &v.AuthorEmail, &v.CompanyID,
+		&v.RequestedDataTypes, &v.Parameters, &v.OwnerID, &v.LeaseExpiresAt, &v.CreatedAt, &v.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil // No deferred verification found, not an error
+		}
+		r.logger.Error("failed to acquire deferred verification", zap.Error(err))
+		return nil, fmt.Errorf("failed to acquire deferred verification: %w", err)
+	}
+
+	return &v, nil
+}
+
+// DeferVerification откладывает проверку до восстановления Credinform. Аренда
+// снимается: проверку подберёт восстановление, когда circuit breaker пропустит запросы.
+func (r *verificationRepository) DeferVerification(ctx context.Context, id string) error {
+	query := `
+		UPDATE verifications
+		SET status = 'DEFERRED', owner_id = '', lease_expires_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND owner_id = $2 AND status IN ('IN_PROCESS', 'PROCESSING')
+	`
+	result, err := r.db.Exec(ctx, query, id, r.lease.OwnerID)
+	if err != nil {
+		r.logger.Error("failed to defer verification", zap.Error(err), zap.String("id", id))
+		return fmt.Errorf("failed to defer verification: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		r.logger.Debug("verification is finished or owned by another worker, nothing to defer", zap.String("id", id))
+		return nil
+	}
+	r.logger.Info("verification deferred", zap.String("id", id))
+	return nil
+}
+
+// ReleaseVerification возвращает незавершённую проверку в очередь восстановления,
+// например при остановке воркера посреди обработки. Аренда снимается, чтобы
+// проверку сразу мог подобрать любой воркер.
+func (r *verificationRepository) ReleaseVerification(ctx context.Context, id string) error {
+	query := `
+		UPDATE verifications
+		SET status = 'IN_PROCESS', owner_id = '', lease_expires_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND owner_id = $2 AND status IN ('IN_PROCESS', 'PROCESSING')
+	`
+	result, err := r.db.Exec(ctx, query, id, r.lease.OwnerID)
+	if err != nil {
+		r.logger.Error("failed to release verification", zap.Error(err), zap.String("id", id))
+		return fmt.Errorf("failed to release verification: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		r.logger.Debug("verification is finished or owned by another worker, nothing to release", zap.String("id", id))
+		return nil
+	}
+	r.logger.Info("verification released", zap.String("id", id))
+	return nil
+}
+
+// RetryVerification возвращает проверку в очередь после временной ошибки и
+// увеличивает счётчик попыток. Аренда не снимается, а переносится на время
+// задержки перед повтором, поэтому восстановление подберёт проверку не раньше.
+// Когда попытки исчерпаны, проверка переводится в ERROR и возвращается exhausted.
+func (r *verificationRepository) RetryVerification(ctx context.Context, id string) (bool, error) {
+	query := `
+		UPDATE verifications
+		SET attempts = attempts + 1,
+			status = CASE WHEN $3 > 0 AND attempts + 1 >= $3 THEN 'ERROR' ELSE 'IN_PROCESS' END,
+			owner_id = '',
+			lease_expires_at = CASE WHEN $3 > 0 AND attempts + 1 >= $3 THEN NULL
+				ELSE NOW() + LEAST($4::BIGINT * POWER(2, LEAST(attempts, 30)), $5::BIGINT) * INTERVAL '1 millisecond' END,
+			updated_at = NOW()
+		WHERE id = $1 AND owner_id = $2 AND status IN ('IN_PROCESS', 'PROCESSING')
+		RETURNING attempts, status
+	`
+	var (
+		attempts int
+		status   string
+	)
+	err := r.db.QueryRow(ctx, query, id, r.lease.OwnerID, r.lease.MaxAttempts,
+		r.lease.RetryDelay.Milliseconds(), r.lease.RetryMaxDelay.Milliseconds()).Scan(&attempts, &status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Debug("verification is finished or owned by another worker, nothing to retry", zap.String("id", id))
+			return false, nil
+		}
+		r.logger.Error("failed to schedule verification retry", zap.Error(err), zap.String("id", id))
+		return false, fmt.Errorf("failed to schedule verification retry: %w", err)
+	}
+	if status == "ERROR" {
+		r.logger.Warn("verification retry attempts exhausted", zap.String("id", id), zap.Int("attempts", attempts))
+		return true, nil
+	}
+	r.logger.Info("verification scheduled for retry", zap.String("id", id), zap.Int("attempts", attempts))
+	return false, nil
+}
+
+// RenewLease продлевает аренду проверки текущим воркером
+func (r *verificationRepository) RenewLease(ctx context.Context, id string) error {
+	query := `
+		UPDATE verifications
+		SET lease_expires_at = NOW() + $3 * INTERVAL '1 millisecond'
+		WHERE id = $1 AND owner_id = $2 AND status IN ('IN_PROCESS', 'PROCESSING')
+	`
+	result, err := r.db.Exec(ctx, query, id, r.lease.OwnerID, r.lease.TTL.Milliseconds())
+	if err != nil {
+		r.logger.Error("failed to renew verification lease", zap.Error(err), zap.String("id", id))
+		return fmt.Errorf("failed to renew verification lease: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %s", ErrLeaseLost, id)
+	}
+	r.logger.Debug("verification lease renewed", zap.String("id", id))
+	return nil
+}
@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap/zaptest"
+)
+
+const testOwnerID = "worker-1"
+
+// leaseRow — строка verifications, на которой mock воспроизводит условия
+// запросов аренды: захват незанятой проверки, продление и возврат в очередь
+type leaseRow struct {
+	id             string
+	status         string
+	ownerID        string
+	leaseExpiresAt *time.Time
+}
+
+func (r *leaseRow) active() bool {
+	return r.status == "IN_PROCESS" || r.status == "PROCESSING"
+}
+
+func (r *leaseRow) pool() *mockDBPool {
+	return &mockDBPool{
+		// AcquireStaleVerification: $1 — владелец, $2 — срок аренды в миллисекундах
+		queryRowFunc: func(ctx context.Context, sql string, args ...any) pgx.Row {
+			now := time.Now()
+			if !r.active() || (r.leaseExpiresAt != nil && !r.leaseExpiresAt.Before(now)) {
+				return &mockRow{scanFunc: func(dest ...any) error { return pgx.ErrNoRows }}
+			}
+			expiresAt := now.Add(time.Duration(args[1].(int64)) * time.Millisecond)
+			r.status, r.ownerID, r.leaseExpiresAt = "PROCESSING", args[0].(string), &expiresAt
+			return &mockRow{scanFunc: func(dest ...any) error {
+				*dest[0].(*string) = r.id
+				*dest[2].(*string) = r.status
+				*dest[7].(*string) = r.ownerID
+				*dest[8].(**time.Time) = r.leaseExpiresAt
+				return nil
+			}}
+		},
+		// ReleaseVerification и RenewLease: $1 — проверка, $2 — владелец
+		execFunc: func(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+			if args[0] != r.id || args[1] != r.ownerID || !r.active() {
+				return pgconn.NewCommandTag("UPDATE 0"), nil
+			}
+			if strings.Contains(sql, "owner_id = ''") {
+				r.status, r.ownerID, r.leaseExpiresAt = "IN_PROCESS", "", nil
+			} else {
+				expiresAt := time.Now().Add(time.Duration(args[2].(int64)) * time.Millisecond)
+				r.leaseExpiresAt = &expiresAt
+			}
+			return pgconn.NewCommandTag("UPDATE 1"), nil
+		},
+	}
+}
+
+func newLeaseTestRepository(t *testing.T, row *leaseRow) *verificationRepository {
+	return &verificationRepository{
+		db:     row.pool(),
+		lease:  LeaseConfig{OwnerID: testOwnerID, TTL: time.Minute},
+		logger: zaptest.NewLogger(t),
+	}
+}
+
+func timeAfter(d time.Duration) *time.Time {
+	at := time.Now().Add(d)
+	return &at
+}
+
+func TestAcquireStaleVerification(t *testing.T) {
+	tests := []struct {
+		name          string
+		row           leaseRow
+		expectedOwner string
+		expectedFound bool
+	}{
+		{
+			// Живую аренду другого воркера перехватывать нельзя
+			name:          "live_lease_of_another_owner",
+			row:           leaseRow{id: "v-1", status: "PROCESSING", ownerID: "worker-2", leaseExpiresAt: timeAfter(time.Minute)},
+			expectedOwner: "worker-2",
+		},
+		{
+			name:          "expired_lease",
+			row:           leaseRow{id: "v-1", status: "PROCESSING", ownerID: "worker-2", leaseExpiresAt: timeAfter(-time.Minute)},
+			expectedOwner: testOwnerID,
+			expectedFound: true,
+		},
+		{
+			name:          "released",
+			row:           leaseRow{id: "v-1", status: "IN_PROCESS"},
+			expectedOwner: testOwnerID,
+			expectedFound: true,
+		},
+		{
+			name: "finished",
+			row:  leaseRow{id: "v-1", status: "COMPLETED"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row := tt.row
+			repo := newLeaseTestRepository(t, &row)
+
+			verification, err := repo.AcquireStaleVerification(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (verification != nil) != tt.expectedFound {
+				t.Fatalf("expected found %v, got %+v", tt.expectedFound, verification)
+			}
+			if row.ownerID != tt.expectedOwner {
+				t.Errorf("expected owner %q, got %q", tt.expectedOwner, row.ownerID)
+			}
+			if verification == nil {
+				return
+			}
+			if verification.OwnerID != testOwnerID {
+				t.Errorf("expected acquired verification owned by %q, got %q", testOwnerID, verification.OwnerID)
+			}
+			if verification.LeaseExpiresAt == nil || !verification.LeaseExpiresAt.After(time.Now()) {
+				t.Errorf("expected lease in the future, got %v", verification.LeaseExpiresAt)
+			}
+		})
+	}
+}
+
+func TestRenewLease(t *testing.T) {
+	tests := []struct {
+		name          string
+		row           leaseRow
+		expectedError error
+	}{
+		{
+			name: "owner",
+			row:  leaseRow{id: "v-1", status: "PROCESSING", ownerID: testOwnerID, leaseExpiresAt: timeAfter(time.Second)},
+		},
+		{
+			name:          "another_owner",
+			row:           leaseRow{id: "v-1", status: "PROCESSING", ownerID: "worker-2", leaseExpiresAt: timeAfter(time.Minute)},
+			expectedError: ErrLeaseLost,
+		},
+		{
+			name:          "released",
+			row:           leaseRow{id: "v-1", status: "IN_PROCESS"},
+			expectedError: ErrLeaseLost,
+		},
+		{
+			name:          "finished",
+			row:           leaseRow{id: "v-1", status: "COMPLETED", ownerID: testOwnerID},
+			expectedError: ErrLeaseLost,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row := tt.row
+			repo := newLeaseTestRepository(t, &row)
+
+			err := repo.RenewLease(context.Background(), "v-1")
+			if tt.expectedError != nil {
+				if !errors.Is(err, tt.expectedError) {
+					t.Errorf("expected error %v, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if row.leaseExpiresAt == nil || row.leaseExpiresAt.Before(time.Now().Add(30*time.Second)) {
+				t.Errorf("expected lease renewed for the TTL, got %v", row.leaseExpiresAt)
+			}
+		})
+	}
+}
+
+func TestReleaseVerification(t *testing.T) {
+	tests := []struct {
+		name           string
+		row            leaseRow
+		expectedStatus string
+		expectedOwner  string
+	}{
+		{
+			name:           "owner",
+			row:            leaseRow{id: "v-1", status: "PROCESSING", ownerID: testOwnerID, leaseExpiresAt: timeAfter(time.Minute)},
+			expectedStatus: "IN_PROCESS",
+		},
+		{
+			// Чужую проверку возвращать в очередь нельзя
+			name:           "another_owner",
+			row:            leaseRow{id: "v-1", status: "PROCESSING", ownerID: "worker-2", leaseExpiresAt: timeAfter(time.Minute)},
+			expectedStatus: "PROCESSING",
+			expectedOwner:  "worker-2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row := tt.row
+			repo := newLeaseTestRepository(t, &row)
+
+			if err := repo.ReleaseVerification(context.Background(), "v-1"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if row.status != tt.expectedStatus {
+				t.Errorf("expected status %q, got %q", tt.expectedStatus, row.status)
+			}
+			if row.ownerID != tt.expectedOwner {
+				t.Errorf("expected owner %q, got %q", tt.expectedOwner, row.ownerID)
+			}
+			if tt.expectedOwner == "" && row.leaseExpiresAt != nil {
+				t.Errorf("expected lease cleared, got %v", row.leaseExpiresAt)
+			}
+
+			// Освобождённую проверку сразу подбирает восстановление
+			verification, err := repo.AcquireStaleVerification(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (verification != nil) != (tt.expectedOwner == "") {
+				t.Errorf("expected released verification to be acquirable, got %+v", verification)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"scoring_worker/internal/config"
+	"scoring_worker/internal/credinform"
+	"scoring_worker/internal/credinform/types"
+)
+
+// DataParams — параметры запросов данных проверки. В verification.create
+// передаются только переопределяемые поля, остальные берутся из конфигурации.
+type DataParams struct {
+	// ArbitrageLookback — глубина истории арбитражных дел: "36m", "2y", "90d"
+	// от момента обработки или фиксированная дата "2025-01-01"
+	ArbitrageLookback string   `json:"arbitrage_lookback,omitempty"`
+	ArbitrageSides    []string `json:"arbitrage_sides,omitempty"`
+	AffiliationTypes  []string `json:"affiliation_types,omitempty"`
+	// LicensedActivities — группы ОКВЭД ("86", "47.73"), требующие лицензии или членства в СРО
+	LicensedActivities []string `json:"licensed_activities,omitempty"`
+	// FinancialStatementsYears — за сколько последних отчётных лет запрашивается отчётность
+	FinancialStatementsYears int `json:"financial_statements_years,omitempty"`
+}
+
+// DefaultDataParams — параметры по умолчанию из config.DefaultDataConfig
+func DefaultDataParams() DataParams {
+	cfg := config.DefaultDataConfig()
+	return dataParamsOf(&cfg)
+}
+
+// DataParamsFromConfig возвращает параметры из конфигурации
+func DataParamsFromConfig(cfg *config.DataConfig) (DataParams, error) {
+	params := dataParamsOf(cfg)
+	if err := params.Validate(); err != nil {
+		return DataParams{}, fmt.Errorf("invalid data config: %w", err)
+	}
+	return DefaultDataParams().Merge(params), nil
+}
+
+func dataParamsOf(cfg *config.DataConfig) DataParams {
+	return DataParams{
+		ArbitrageLookback:        cfg.ArbitrageLookback,
+		ArbitrageSides:           cfg.ArbitrageSides,
+		AffiliationTypes:         cfg.AffiliationTypes,
+		LicensedActivities:       cfg.LicensedActivities,
+		FinancialStatementsYears: cfg.FinancialStatementsYears,
+	}
+}
+
+// ParseDataParams разбирает и проверяет переопределения параметров из verification.create.
+// Пустое значение означает отсутствие переопределений.
+func ParseDataParams(data []byte) (DataParams, error) {
+	var params DataParams
+	if len(data) == 0 || string(data) == "null" {
+		return params, nil
+	}
+	if err := json.Unmarshal(data, &params); err != nil {
+		return DataParams{}, fmt.Errorf("failed to parse data parameters: %w", err)
+	}
+	if err := params.Validate(); err != nil {
+		return DataParams{}, err
+	}
+	return params, nil
+}
+
+// Merge возвращает параметры p, в которых заданные поля override заменяют исходные
+func (p DataParams) Merge(override DataParams) DataParams {
+	if override.ArbitrageLookback != "" {
+		p.ArbitrageLookback = override.ArbitrageLookback
+	}
+	if len(override.ArbitrageSides) > 0 {
+		p.ArbitrageSides = override.ArbitrageSides
+	}
+	if len(override.AffiliationTypes) > 0 {
+		p.AffiliationTypes = override.AffiliationTypes
+	}
+	if len(override.LicensedActivities) > 0 {
+		p.LicensedActivities = override.LicensedActivities
+	}
+	if override.FinancialStatementsYears > 0 {
+		p.FinancialStatementsYears = override.FinancialStatementsYears
+	}
+	return p
+}
+
+// Validate проверяет заданные поля; пустые поля допустимы
+func (p DataParams) Validate() error {
+	if p.ArbitrageLookback != "" {
+		if _, err := resolveLookback(p.ArbitrageLookback, time.Now()); err != nil {
+			return err
+		}
+	}
+	known := []string{types.ArbitrageSideClaimant, types.ArbitrageSideDefendant, types.ArbitrageSideThirdPartiesAndOthers}
+	for _, side := range p.ArbitrageSides {
+		if !slices.Contains(known, side) {
+			return fmt.Errorf("unknown arbitrage side %q", side)
+		}
+	}
+	for _, affiliationType := range p.AffiliationTypes {
+		if strings.TrimSpace(affiliationType) == "" {
+			return errors.New("empty affiliation type")
+		}
+	}
+	for _, group := range p.LicensedActivities {
+		if !isActivityGroup(group) {
+			return fmt.Errorf("invalid licensed activity group %q", group)
+		}
+	}
+	if p.FinancialStatementsYears < 0 {
+		return fmt.Errorf("invalid financial statements years %d", p.FinancialStatementsYears)
+	}
+	return nil
+}
+
+// requestParams — параметры запросов к Credinform, вычисленные на момент обработки
+type requestParams struct {
+	arbitrage           credinform.ArbitrageStatisticsParams
+	arbitrageCases      credinform.ArbitrageCasesParams
+	affiliated          credinform.AffiliatedCompaniesParams
+	financialStatements credinform.FinancialStatementsParams
+	licensedActivities  []string
+	pledgesAndLeasing   credinform.PledgesAndLeasingParams
+	// now — момент обработки, от которого считаются сводки за период
+	now time.Time
+}
+
+// resolve вычисляет параметры запросов; относительные окна отсчитываются от now
+func (p DataParams) resolve(now time.Time) (requestParams, error) {
+	from, err := resolveLookback(p.ArbitrageLookback, now)
+	if err != nil {
+		return requestParams{}, err
+	}
+
+	params := requestParams{now: now}
+	params.arbitrage.ArbitrageSideCommonType = p.ArbitrageSides
+	params.arbitrage.LastCaseChangeDateRange.From = from.Format("2006-01-02T15:04:05")
+	params.arbitrageCases.ArbitrageSideCommonType = p.ArbitrageSides
+	params.arbitrageCases.LastCaseChangeDateRange.From = params.arbitrage.LastCaseChangeDateRange.From
+	params.affiliated.AffiliationTypes = p.AffiliationTypes
+	params.financialStatements = financialStatementsParams(p.FinancialStatementsYears, now)
+	params.licensedActivities = p.LicensedActivities
+	params.pledgesAndLeasing.ParticipationRoles = []string{types.EncumbranceRolePledgor, types.EncumbranceRoleLessee}
+	return params, nil
+}
+
+// forType возвращает параметры запроса типа данных для аудита или nil,
+// если тип запрашивается без параметров
+func (p requestParams) forType(dataType string) interface{} {
+	switch strings.ToLower(dataType) {
+	case "affiliated_companies":
+		return p.affiliated
+	case "arbitrage_statistics":
+		return p.arbitrage
+	case "arbitrage_cases":
+		return p.arbitrageCases
+	case "financial_statements", "financial_analysis":
+		return p.financialStatements
+	case "pledges_and_leasing":
+		return p.pledgesAndLeasing
+	case "licenses":
+		return struct {
+			LicensedActivities []string `json:"licensed_activities"`
+		}{p.licensedActivities}
+	default:
+		return nil
+	}
+}
+
+// financialStatementsParams запрашивает баланс и отчёт о финансовых результатах за
+// years последних лет; отчётным считается год, предшествующий текущему
+func financialStatementsParams(years int, now time.Time) credinform.FinancialStatementsParams {
+	params := credinform.FinancialStatementsParams{
+		Forms: []string{types.StatementFormBalanceSheet, types.StatementFormProfitAndLoss},
+	}
+	params.Period.To = now.Year() - 1
+	params.Period.From = params.Period.To - years + 1
+	return params
+}
+
+// resolveLookback возвращает начало окна: now минус "<n>d", "<n>m" или "<n>y"
+// с точностью до дня либо фиксированную дату
+func resolveLookback(lookback string, now time.Time) (time.Time, error) {
+	if date, err := time.Parse(time.DateOnly, lookback); err == nil {
+		return date, nil
+	}
+
+	unit := lookback[max(len(lookback)-1, 0):]
+	n, err := strconv.Atoi(strings.TrimSuffix(lookback, unit))
+	if err != nil || n <= 0 {
+		return time.Time{}, fmt.Errorf("invalid lookback %q, expected <n>d, <n>m, <n>y or YYYY-MM-DD", lookback)
+	}
+
+	year, month, day := now.Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+	switch unit {
+	case "d":
+		return today.AddDate(0, 0, -n), nil
+	case "m":
+		return today.AddDate(0, -n, 0), nil
+	case "y":
+		return today.AddDate(-n, 0, 0), nil
+	default:
+		return time.Time{}, fmt.Errorf("invalid lookback %q, expected <n>d, <n>m, <n>y or YYYY-MM-DD", lookback)
+	}
+}
+
+// isActivityGroup проверяет, что группа — начало кода ОКВЭД: "86", "47.7", "47.73.1"
+func isActivityGroup(group string) bool {
+	if len(group) < 2 {
+		return false
+	}
+	for i, r := range group {
+		if r == '.' && i > 0 && group[i-1] != '.' && i < len(group)-1 {
+			continue
+		}
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
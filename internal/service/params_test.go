@@ -0,0 +1,106 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResolveLookback(t *testing.T) {
+	now := time.Date(2025, time.March, 31, 15, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		lookback      string
+		expected      string
+		expectedError bool
+	}{
+		{lookback: "36m", expected: "2022-03-31"},
+		{lookback: "2y", expected: "2023-03-31"},
+		{lookback: "90d", expected: "2024-12-31"},
+		{lookback: "2025-01-01", expected: "2025-01-01"},
+		{lookback: "", expectedError: true},
+		{lookback: "0m", expectedError: true},
+		{lookback: "36w", expectedError: true},
+		{lookback: "last 36 months", expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lookback, func(t *testing.T) {
+			from, err := resolveLookback(tt.lookback, now)
+			if tt.expectedError {
+				if err == nil {
+					t.Errorf("Expected error, got %v", from)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := from.Format(time.DateOnly); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseDataParams(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          string
+		expectedError bool
+	}{
+		{name: "empty", data: ""},
+		{name: "null", data: "null"},
+		{name: "overrides", data: `{"arbitrage_lookback":"12m","arbitrage_sides":["Defendant"]}`},
+		{name: "invalid_json", data: `{"arbitrage_sides":"Defendant"}`, expectedError: true},
+		{name: "invalid_lookback", data: `{"arbitrage_lookback":"recently"}`, expectedError: true},
+		{name: "unknown_side", data: `{"arbitrage_sides":["Witness"]}`, expectedError: true},
+		{name: "licensed_activities", data: `{"licensed_activities":["86","47.73.1"]}`},
+		{name: "invalid_licensed_activity", data: `{"licensed_activities":["86."]}`, expectedError: true},
+		{name: "short_licensed_activity", data: `{"licensed_activities":["8"]}`, expectedError: true},
+		{name: "financial_statements_years", data: `{"financial_statements_years":3}`},
+		{name: "negative_financial_statements_years", data: `{"financial_statements_years":-1}`, expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseDataParams([]byte(tt.data))
+			if tt.expectedError && err == nil {
+				t.Error("Expected error, got nil")
+			}
+			if !tt.expectedError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestFinancialStatementsParams(t *testing.T) {
+	now := time.Date(2025, time.March, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		params       DataParams
+		expectedFrom int
+	}{
+		// Отчётность за текущий год ещё не сдана, поэтому период заканчивается прошлым годом
+		{name: "default_years", params: DefaultDataParams(), expectedFrom: 2020},
+		{name: "override_years", params: DefaultDataParams().Merge(DataParams{FinancialStatementsYears: 2}), expectedFrom: 2023},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolved, err := tt.params.resolve(now)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			period := resolved.financialStatements.Period
+			if period.From != tt.expectedFrom || period.To != 2024 {
+				t.Errorf("Expected period %d-2024, got %d-%d", tt.expectedFrom, period.From, period.To)
+			}
+			if strings.Join(resolved.financialStatements.Forms, ",") != "BalanceSheet,ProfitAndLoss" {
+				t.Errorf("Expected balance sheet and profit and loss forms, got %v", resolved.financialStatements.Forms)
+			}
+		})
+	}
+}
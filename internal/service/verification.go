@@ -3,80 +3,174 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"scoring_worker/internal/analysis"
 	"scoring_worker/internal/credinform"
+	"scoring_worker/internal/credinform/types"
 	"scoring_worker/internal/repository"
 
 	"go.uber.org/zap"
 )
 
+var (
+	// ErrTemporary помечает ошибки, после которых проверку имеет смысл повторить позже
+	ErrTemporary = errors.New("temporary verification failure")
+	// ErrDeferred помечает проверки, отложенные до восстановления Credinform
+	ErrDeferred = errors.New("verification deferred")
+)
+
+// markedError добавляет к ошибке признак, проверяемый через errors.Is
+type markedError struct {
+	err  error
+	mark error
+}
+
+func (e *markedError) Error() string { return e.err.Error() }
+
+func (e *markedError) Unwrap() []error { return []error{e.err, e.mark} }
+
+// IsTemporary сообщает, прервана ли проверка временной ошибкой провайдера
+func IsTemporary(err error) bool {
+	return errors.Is(err, ErrTemporary)
+}
+
+// IsDeferred сообщает, отложена ли проверка из-за разомкнутого circuit breaker
+func IsDeferred(err error) bool {
+	return errors.Is(err, ErrDeferred)
+}
+
 type VerificationService interface {
-	ProcessVerification(ctx context.Context, verificationID, inn string, requestedTypes []string) error
+	// ProcessVerification обрабатывает проверку; заданные поля overrides заменяют
+	// параметры запросов данных по умолчанию
+	ProcessVerification(ctx context.Context, verificationID, inn string, requestedTypes []string, overrides DataParams) (*Result, error)
+}
+
+// Result — сведения о завершённой проверке для события verification.completed
+type Result struct {
+	// ActiveBankruptcyProcedure — идёт ли процедура банкротства; nil, если
+	// bankruptcy_messages не запрашивались или не получены
+	ActiveBankruptcyProcedure *bool
 }
 
 type verificationService struct {
-	credinformClient *credinform.Client
+	credinformClient credinform.CredinformAPI
 	repo             repository.VerificationRepository
+	params           DataParams
 	logger           *zap.Logger
 }
 
-func NewVerificationService(client *credinform.Client, repo repository.VerificationRepository, logger *zap.Logger) VerificationService {
-	return &verificationService{
+// Option настраивает сервис при создании
+type Option func(*verificationService)
+
+// WithDataParams задаёт параметры запросов данных по умолчанию вместо DefaultDataParams
+func WithDataParams(params DataParams) Option {
+	return func(s *verificationService) {
+		s.params = params
+	}
+}
+
+func NewVerificationService(client credinform.CredinformAPI, repo repository.VerificationRepository, logger *zap.Logger, opts ...Option) VerificationService {
+	s := &verificationService{
 		credinformClient: client,
 		repo:             repo,
+		params:           DefaultDataParams(),
 		logger:           logger,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-func (s *verificationService) ProcessVerification(ctx context.Context, verificationID, inn string, requestedTypes []string) error {
+func (s *verificationService) ProcessVerification(ctx context.Context, verificationID, inn string, requestedTypes []string, overrides DataParams) (*Result, error) {
 	s.logger.Info("Starting verification processing",
 		zap.String("verification_id", verificationID),
 		zap.String("inn", inn),
 		zap.Strings("requested_types", requestedTypes))
 
+	// Относительные окна отсчитываются от момента обработки, а не создания проверки
+	params, err := s.params.Merge(overrides).resolve(time.Now())
+	if err != nil {
+		s.logger.Error("Invalid data parameters", zap.Error(err), zap.String("verification_id", verificationID))
+		_ = s.updateVerificationStatus(ctx, verificationID, "ERROR")
+		return nil, fmt.Errorf("invalid data parameters: %w", err)
+	}
+
 	companyData, err := s.searchCompany(ctx, verificationID, inn)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if err := s.prepareVerification(ctx, verificationID, companyData.CompanyID); err != nil {
-		return err
+		return nil, err
 	}
 
 	if err := s.updateVerificationStatus(ctx, verificationID, "PROCESSING"); err != nil {
-		return err
+		return nil, err
 	}
 
-	s.processDataTypes(ctx, verificationID, companyData.CompanyID, requestedTypes)
+	result, deferred := s.processDataTypes(ctx, verificationID, companyData.CompanyID, requestedTypes, params)
+
+	// Прерванная обработка не помечается завершённой, чтобы её можно было возобновить
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("verification processing interrupted: %w", err)
+	}
+
+	if deferred {
+		s.logger.Warn("Verification deferred: Credinform circuit breaker is open",
+			zap.String("verification_id", verificationID))
+		return nil, &markedError{err: credinform.ErrCircuitOpen, mark: ErrDeferred}
+	}
 
 	if err := s.updateVerificationStatus(ctx, verificationID, "COMPLETED"); err != nil {
-		return err
+		return nil, err
 	}
 
 	s.logger.Info("Verification processing completed",
 		zap.String("verification_id", verificationID),
 		zap.String("company_id", companyData.CompanyID))
-	return nil
+	return result, nil
 }
 
 func (s *verificationService) searchCompany(ctx context.Context, verificationID, inn string) (*credinform.CompanyData, error) {
 	companyData, err := s.credinformClient.SearchCompany(ctx, inn)
 	if err != nil {
-		s.logger.Error("Failed to search company", zap.Error(err), zap.String("inn", inn))
-		status := "ERROR"
-		if err.Error() == "company not found" {
-			status = "COMPANY_NOT_FOUND"
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("failed to search company (inn: %s): %w", inn, err)
+		}
+		err = fmt.Errorf("failed to search company (inn: %s): %w", inn, err)
+		if errors.Is(err, credinform.ErrCircuitOpen) {
+			s.logger.Warn("Company search deferred: Credinform circuit breaker is open", zap.String("inn", inn))
+			return nil, &markedError{err: err, mark: ErrDeferred}
 		}
-		_ = s.updateVerificationStatus(ctx, verificationID, status)
-		return nil, fmt.Errorf("failed to search company (inn: %s): %w", inn, err)
+		// Временные сбои не фиксируют итоговый статус: проверка будет повторена
+		if credinform.IsRetryable(err) {
+			s.logger.Warn("Company search failed temporarily", zap.Error(err), zap.String("inn", inn))
+			return nil, &markedError{err: err, mark: ErrTemporary}
+		}
+		s.logger.Error("Failed to search company", zap.Error(err), zap.String("inn", inn))
+		_ = s.updateVerificationStatus(ctx, verificationID, searchErrorStatus(err))
+		return nil, err
 	}
 	return companyData, nil
 }
 
+// searchErrorStatus сопоставляет ошибку поиска компании итоговому статусу проверки
+func searchErrorStatus(err error) string {
+	switch {
+	case errors.Is(err, credinform.ErrCompanyNotFound):
+		return "COMPANY_NOT_FOUND"
+	default:
+		return "ERROR"
+	}
+}
+
 func (s *verificationService) prepareVerification(ctx context.Context, verificationID, companyID string) error {
 	if err := s.repo.UpdateCompanyID(ctx, verificationID, companyID); err != nil {
 		s.logger.Error("Failed to update company_id",
@@ -89,18 +183,157 @@ func (s *verificationService) prepareVerification(ctx context.Context, verificat
 	return nil
 }
 
-func (s *verificationService) processDataTypes(ctx context.Context, verificationID, companyID string, requestedTypes []string) {
-	var wg sync.WaitGroup
+// derivedDataTypes — типы данных, вычисляемые по другим загруженным данным, и их исходный тип
+var derivedDataTypes = map[string]string{
+	"financial_analysis": "financial_statements",
+}
+
+// dataDependencies — типы данных, сводка которых дополнительно использует другой
+// загруженный тип: лицензии сверяются с видами деятельности
+var dataDependencies = map[string]string{
+	"licenses": "activities",
+}
+
+// errUnknownDataType возвращается fetchData для неподдерживаемого типа данных
+var errUnknownDataType = errors.New("unknown data type")
+
+// fetchResult — результат загрузки исходных данных для производных типов
+type fetchResult struct {
+	data interface{}
+	err  error
+}
+
+// processDataTypes загружает запрошенные данные, затем вычисляет производные типы.
+// Возвращает сведения для события о завершении и сообщает, был ли хотя бы один
+// запрос отклонён разомкнутым circuit breaker.
+func (s *verificationService) processDataTypes(ctx context.Context, verificationID, companyID string, requestedTypes []string, params requestParams) (*Result, bool) {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		deferred atomic.Bool
+	)
+	results := make(map[string]fetchResult)
+	collect := func(dataType string, data interface{}, err error) {
+		if errors.Is(err, credinform.ErrCircuitOpen) {
+			deferred.Store(true)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		results[strings.ToLower(dataType)] = fetchResult{data: data, err: err}
+	}
+
 	for _, dataType := range requestedTypes {
+		key := strings.ToLower(dataType)
+		if _, ok := derivedDataTypes[key]; ok {
+			continue
+		}
 		wg.Add(1)
-		go s.fetchAndSaveData(ctx, &wg, verificationID, companyID, dataType)
+		go func() {
+			defer wg.Done()
+			// Данные с зависимостью сохраняются после загрузки зависимости
+			if _, ok := dataDependencies[key]; ok {
+				data, err := s.fetchData(ctx, companyID, dataType, params)
+				collect(dataType, data, err)
+				return
+			}
+			data, err := s.fetchAndSaveData(ctx, verificationID, companyID, dataType, params)
+			collect(dataType, data, err)
+		}()
+	}
+	// Исходные данные, не запрошенные сами по себе, загружаются без сохранения
+	for _, source := range missingSources(requestedTypes) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data, err := s.fetchData(ctx, companyID, source, params)
+			collect(source, data, err)
+		}()
 	}
 	wg.Wait()
+
+	for _, dataType := range requestedTypes {
+		key := strings.ToLower(dataType)
+		if source, ok := derivedDataTypes[key]; ok {
+			s.deriveAndSaveData(ctx, verificationID, companyID, dataType, results[source], params)
+		}
+		if dependency, ok := dataDependencies[key]; ok {
+			s.combineAndSaveData(ctx, verificationID, companyID, dataType, results[key], results[dependency], params)
+		}
+	}
+	return newResult(results), deferred.Load()
 }
 
-func (s *verificationService) fetchAndSaveData(ctx context.Context, wg *sync.WaitGroup, verificationID, companyID, dataType string) {
-	defer wg.Done()
+// newResult собирает сведения для события о завершении из загруженных данных
+func newResult(results map[string]fetchResult) *Result {
+	result := &Result{}
+	if report, ok := results["bankruptcy_messages"].data.(*analysis.BankruptcyReport); ok && report != nil {
+		active := report.Summary.ActiveProcedure
+		result.ActiveBankruptcyProcedure = &active
+	}
+	return result
+}
 
+// missingSources возвращает исходные типы и зависимости запрошенных данных, которые не запрошены явно
+func missingSources(requestedTypes []string) []string {
+	requested := make(map[string]bool, len(requestedTypes))
+	for _, dataType := range requestedTypes {
+		requested[strings.ToLower(dataType)] = true
+	}
+	var sources []string
+	for _, dataType := range requestedTypes {
+		for _, dependencies := range []map[string]string{derivedDataTypes, dataDependencies} {
+			source, ok := dependencies[strings.ToLower(dataType)]
+			if ok && !requested[source] {
+				requested[source] = true
+				sources = append(sources, source)
+			}
+		}
+	}
+	return sources
+}
+
+// fetchAndSaveData загружает и сохраняет один тип данных. Возвращает загруженные
+// данные или ошибку провайдера, если их получить не удалось.
+func (s *verificationService) fetchAndSaveData(ctx context.Context, verificationID, companyID, dataType string, params requestParams) (interface{}, error) {
+	dataForDB, err := s.fetchData(ctx, companyID, dataType, params)
+	if errors.Is(err, errUnknownDataType) {
+		s.logger.Warn("Unknown data type requested", zap.String("type", dataType))
+		return nil, nil
+	}
+	s.saveFetchedData(ctx, verificationID, companyID, dataType, dataForDB, err, params)
+	return dataForDB, err
+}
+
+// saveFetchedData сохраняет загруженные данные или ошибку провайдера. Прерванная
+// или отложенная загрузка не сохраняется: она будет повторена вместе с проверкой.
+func (s *verificationService) saveFetchedData(ctx context.Context, verificationID, companyID, dataType string, dataForDB interface{}, err error, params requestParams) {
+	if err != nil {
+		if ctx.Err() != nil {
+			s.logger.Info("Data fetching interrupted",
+				zap.String("type", dataType),
+				zap.String("verification_id", verificationID))
+			return
+		}
+		// Данные будут загружены при повторной обработке отложенной проверки
+		if errors.Is(err, credinform.ErrCircuitOpen) {
+			s.logger.Warn("Data fetching deferred: Credinform circuit breaker is open",
+				zap.String("type", dataType),
+				zap.String("verification_id", verificationID))
+			return
+		}
+		s.logger.Error("Failed to get company data",
+			zap.Error(err),
+			zap.String("type", dataType),
+			zap.String("company_id", companyID))
+		s.saveErrorData(ctx, verificationID, companyID, dataType, params.forType(dataType), err)
+		return
+	}
+
+	s.saveSuccessData(ctx, verificationID, companyID, dataType, params.forType(dataType), dataForDB)
+}
+
+// fetchData загружает из Credinform один тип данных
+func (s *verificationService) fetchData(ctx context.Context, companyID, dataType string, params requestParams) (interface{}, error) {
 	var dataForDB interface{}
 	var err error
 
@@ -114,73 +347,125 @@ func (s *verificationService) fetchAndSaveData(ctx context.Context, wg *sync.Wai
 	case "addresses_by_unified_state_register":
 		dataForDB, err = s.credinformClient.GetAddressesByUnifiedStateRegister(ctx, companyID, credinform.AddressesByUnifiedStateRegisterParams{})
 	case "affiliated_companies":
-		params := credinform.AffiliatedCompaniesParams{
-			AffiliationTypes: []string{
-				"ByManagementOrShareholdersNaturalPersons",
-				"ByLiquidatorOrBankruptcyAdministrator",
-				"UnderAdministrationOfTheCompany",
-				"ByManagingLegalPersons",
-				"ByShareholdersLegalPersons",
-			},
-		}
-		dataForDB, err = s.credinformClient.GetAffiliatedCompanies(ctx, companyID, params)
+		dataForDB, err = s.credinformClient.GetAffiliatedCompanies(ctx, companyID, params.affiliated)
 	case "arbitrage_statistics":
-		params := credinform.ArbitrageStatisticsParams{
-			ArbitrageSideCommonType: []string{
-				"Claimant",
-				"Defendant",
-				"ThirdPartiesAndOthers",
-			},
-		}
-		params.LastCaseChangeDateRange.From = "2025-01-01T00:00:00"
-		dataForDB, err = s.credinformClient.GetArbitrageStatistics(ctx, companyID, params)
+		dataForDB, err = s.credinformClient.GetArbitrageStatistics(ctx, companyID, params.arbitrage)
+	case "enforcement_proceedings":
+		var proceedings *types.EnforcementProceedings
+		if proceedings, err = s.credinformClient.GetEnforcementProceedings(ctx, companyID, credinform.EnforcementProceedingsParams{}); err == nil {
+			dataForDB = analysis.SummarizeEnforcementProceedings(proceedings, params.now)
+		}
+	case "financial_statements":
+		dataForDB, err = s.credinformClient.GetFinancialStatements(ctx, companyID, params.financialStatements)
+	case "bankruptcy_messages":
+		var messages *types.BankruptcyMessages
+		if messages, err = s.credinformClient.GetBankruptcyMessages(ctx, companyID, credinform.BankruptcyMessagesParams{}); err == nil {
+			dataForDB = analysis.SummarizeBankruptcyMessages(messages)
+		}
+	case "arbitrage_cases":
+		var cases *types.ArbitrageCases
+		if cases, err = s.credinformClient.GetArbitrageCases(ctx, companyID, params.arbitrageCases); err == nil {
+			dataForDB = analysis.SummarizeArbitrageCases(cases)
+		}
+	case "state_contracts":
+		var contracts *types.StateContracts
+		if contracts, err = s.credinformClient.GetStateContracts(ctx, companyID, credinform.StateContractsParams{}); err == nil {
+			dataForDB = analysis.SummarizeStateContracts(contracts)
+		}
+	case "licenses":
+		dataForDB, err = s.credinformClient.GetLicenses(ctx, companyID, credinform.LicensesParams{})
+	case "pledges_and_leasing":
+		var encumbrances *types.PledgesAndLeasing
+		if encumbrances, err = s.credinformClient.GetPledgesAndLeasing(ctx, companyID, params.pledgesAndLeasing); err == nil {
+			dataForDB = analysis.SummarizePledgesAndLeasing(encumbrances, params.now)
+		}
 	default:
-		s.logger.Warn("Unknown data type requested", zap.String("type", dataType))
+		return nil, fmt.Errorf("%w: %s", errUnknownDataType, dataType)
+	}
+	return dataForDB, err
+}
+
+// deriveAndSaveData вычисляет производный тип данных по результату загрузки исходного
+// и сохраняет его. Если исходные данные получить не удалось, сохраняется ошибка;
+// прерванная или отложенная загрузка будет повторена вместе с проверкой.
+func (s *verificationService) deriveAndSaveData(ctx context.Context, verificationID, companyID, dataType string, source fetchResult, params requestParams) {
+	if source.err != nil {
+		if ctx.Err() != nil || errors.Is(source.err, credinform.ErrCircuitOpen) {
+			return
+		}
+		s.saveErrorData(ctx, verificationID, companyID, dataType, params.forType(dataType), fmt.Errorf("source data unavailable: %w", source.err))
 		return
 	}
 
-	if err != nil {
-		s.logger.Error("Failed to get company data",
-			zap.Error(err),
+	var derived interface{}
+	switch strings.ToLower(dataType) {
+	case "financial_analysis":
+		statements, _ := source.data.(*types.FinancialStatements)
+		derived = analysis.AnalyzeFinancials(statements)
+	}
+	s.saveSuccessData(ctx, verificationID, companyID, dataType, params.forType(dataType), derived)
+}
+
+// combineAndSaveData дополняет загруженные данные сводкой с учётом зависимости и
+// сохраняет их. Если зависимость получить не удалось, данные сохраняются со сводкой
+// без сверки; прерванная или отложенная загрузка будет повторена вместе с проверкой.
+func (s *verificationService) combineAndSaveData(ctx context.Context, verificationID, companyID, dataType string, own, dependency fetchResult, params requestParams) {
+	if own.err == nil && dependency.err != nil {
+		if ctx.Err() != nil || errors.Is(dependency.err, credinform.ErrCircuitOpen) {
+			return
+		}
+		s.logger.Warn("Dependent data unavailable, saving without it",
+			zap.Error(dependency.err),
 			zap.String("type", dataType),
-			zap.String("company_id", companyID))
-		s.saveErrorData(ctx, verificationID, companyID, dataType, err)
-		return
+			zap.String("verification_id", verificationID))
 	}
 
-	s.saveSuccessData(ctx, verificationID, companyID, dataType, dataForDB)
+	var combined interface{}
+	if own.err == nil {
+		switch strings.ToLower(dataType) {
+		case "licenses":
+			licenses, _ := own.data.(*types.Licenses)
+			activities, _ := dependency.data.(*types.Activities)
+			combined = analysis.SummarizeLicenses(licenses, activities, params.licensedActivities, params.now)
+		}
+	}
+	s.saveFetchedData(ctx, verificationID, companyID, dataType, combined, own.err, params)
 }
 
-func (s *verificationService) saveErrorData(ctx context.Context, verificationID, companyID, dataType string, err error) {
+func (s *verificationService) saveErrorData(ctx context.Context, verificationID, companyID, dataType string, usedParams interface{}, err error) {
 	errorData := map[string]interface{}{
-		"error":        err.Error(),
-		"type":         dataType,
-		"company_id":   companyID,
-		"processed_at": time.Now().Format(time.RFC3339),
+		"error": err.Error(),
 	}
 	dataJSON, _ := json.Marshal(errorData)
-	if dbErr := s.repo.AddData(ctx, verificationID, dataType, string(dataJSON)); dbErr != nil {
+	metadata := repository.DataMetadata{
+		Status:      "error",
+		CompanyID:   companyID,
+		ProcessedAt: time.Now().UTC(),
+		Error:       err.Error(),
+		Parameters:  s.marshalParams(usedParams),
+	}
+	if dbErr := s.repo.AddData(ctx, verificationID, dataType, string(dataJSON), metadata); dbErr != nil {
 		s.logger.Error("Failed to add error data to repository", zap.Error(dbErr))
 	}
 }
 
-func (s *verificationService) saveSuccessData(ctx context.Context, verificationID, companyID, dataType string, dataForDB interface{}) {
-	resultData := map[string]interface{}{
-		"data":         dataForDB,
-		"type":         dataType,
-		"company_id":   companyID,
-		"processed_at": time.Now().Format(time.RFC3339),
-		"status":       "completed",
-	}
-
-	dataJSON, err := json.Marshal(resultData)
+// saveSuccessData сохраняет ответ провайдера как есть: время и статус получения
+// передаются в метаданных, чтобы одинаковые ответы дедуплицировались в кэше
+func (s *verificationService) saveSuccessData(ctx context.Context, verificationID, companyID, dataType string, usedParams interface{}, dataForDB interface{}) {
+	dataJSON, err := json.Marshal(dataForDB)
 	if err != nil {
 		s.logger.Error("Failed to marshal result data", zap.Error(err))
-		s.saveErrorData(ctx, verificationID, companyID, dataType, err)
+		s.saveErrorData(ctx, verificationID, companyID, dataType, usedParams, err)
 		return
 	}
 
-	if err := s.repo.AddData(ctx, verificationID, dataType, string(dataJSON)); err != nil {
+	metadata := repository.DataMetadata{
+		Status:      "completed",
+		CompanyID:   companyID,
+		ProcessedAt: time.Now().UTC(),
+		Parameters:  s.marshalParams(usedParams),
+	}
+	if err := s.repo.AddData(ctx, verificationID, dataType, string(dataJSON), metadata); err != nil {
 		s.logger.Error("Failed to add verification data",
 			zap.Error(err),
 			zap.String("verification_id", verificationID),
@@ -188,6 +473,19 @@ func (s *verificationService) saveSuccessData(ctx context.Context, verificationI
 	}
 }
 
+// marshalParams сериализует параметры запроса для метаданных; nil — без параметров
+func (s *verificationService) marshalParams(usedParams interface{}) json.RawMessage {
+	if usedParams == nil {
+		return nil
+	}
+	data, err := json.Marshal(usedParams)
+	if err != nil {
+		s.logger.Warn("Failed to marshal request parameters", zap.Error(err))
+		return nil
+	}
+	return data
+}
+
 func (s *verificationService) updateVerificationStatus(ctx context.Context, verificationID, status string) error {
 	if err := s.repo.UpdateStatus(ctx, verificationID, status); err != nil {
 		s.logger.Error("Failed to update status",
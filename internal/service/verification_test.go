@@ -2,14 +2,19 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"scoring_worker/internal/credinform"
 	"scoring_worker/internal/credinform/types"
 	"scoring_worker/internal/repository"
 
-	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest"
 )
 
@@ -22,6 +27,13 @@ type mockCredinformClient struct {
 	getAddressesByUnifiedStateRegisterFunc func(ctx context.Context, companyID string, params credinform.AddressesByUnifiedStateRegisterParams) (*types.AddressesByUnifiedStateRegister, error)
 	getAffiliatedCompaniesFunc             func(ctx context.Context, companyID string, params credinform.AffiliatedCompaniesParams) (*types.AffiliatedCompanies, error)
 	getArbitrageStatisticsFunc             func(ctx context.Context, companyID string, params credinform.ArbitrageStatisticsParams) (*types.ArbitrageStatistics, error)
+	getFinancialStatementsFunc             func(ctx context.Context, companyID string, params credinform.FinancialStatementsParams) (*types.FinancialStatements, error)
+	getArbitrageCasesFunc                  func(ctx context.Context, companyID string, params credinform.ArbitrageCasesParams) (*types.ArbitrageCases, error)
+	getBankruptcyMessagesFunc              func(ctx context.Context, companyID string, params credinform.BankruptcyMessagesParams) (*types.BankruptcyMessages, error)
+	getEnforcementProceedingsFunc          func(ctx context.Context, companyID string, params credinform.EnforcementProceedingsParams) (*types.EnforcementProceedings, error)
+	getStateContractsFunc                  func(ctx context.Context, companyID string, params credinform.StateContractsParams) (*types.StateContracts, error)
+	getLicensesFunc                        func(ctx context.Context, companyID string, params credinform.LicensesParams) (*types.Licenses, error)
+	getPledgesAndLeasingFunc               func(ctx context.Context, companyID string, params credinform.PledgesAndLeasingParams) (*types.PledgesAndLeasing, error)
 }
 
 func (m *mockCredinformClient) SearchCompany(ctx context.Context, inn string) (*credinform.CompanyData, error) {
@@ -73,15 +85,64 @@ func (m *mockCredinformClient) GetArbitrageStatistics(ctx context.Context, compa
 	return &types.ArbitrageStatistics{}, nil
 }
 
+func (m *mockCredinformClient) GetFinancialStatements(ctx context.Context, companyID string, params credinform.FinancialStatementsParams) (*types.FinancialStatements, error) {
+	if m.getFinancialStatementsFunc != nil {
+		return m.getFinancialStatementsFunc(ctx, companyID, params)
+	}
+	return &types.FinancialStatements{}, nil
+}
+
+func (m *mockCredinformClient) GetArbitrageCases(ctx context.Context, companyID string, params credinform.ArbitrageCasesParams) (*types.ArbitrageCases, error) {
+	if m.getArbitrageCasesFunc != nil {
+		return m.getArbitrageCasesFunc(ctx, companyID, params)
+	}
+	return &types.ArbitrageCases{}, nil
+}
+
+func (m *mockCredinformClient) GetBankruptcyMessages(ctx context.Context, companyID string, params credinform.BankruptcyMessagesParams) (*types.BankruptcyMessages, error) {
+	if m.getBankruptcyMessagesFunc != nil {
+		return m.getBankruptcyMessagesFunc(ctx, companyID, params)
+	}
+	return &types.BankruptcyMessages{}, nil
+}
+
+func (m *mockCredinformClient) GetEnforcementProceedings(ctx context.Context, companyID string, params credinform.EnforcementProceedingsParams) (*types.EnforcementProceedings, error) {
+	if m.getEnforcementProceedingsFunc != nil {
+		return m.getEnforcementProceedingsFunc(ctx, companyID, params)
+	}
+	return &types.EnforcementProceedings{}, nil
+}
+
+func (m *mockCredinformClient) GetStateContracts(ctx context.Context, companyID string, params credinform.StateContractsParams) (*types.StateContracts, error) {
+	if m.getStateContractsFunc != nil {
+		return m.getStateContractsFunc(ctx, companyID, params)
+	}
+	return &types.StateContracts{}, nil
+}
+
+func (m *mockCredinformClient) GetLicenses(ctx context.Context, companyID string, params credinform.LicensesParams) (*types.Licenses, error) {
+	if m.getLicensesFunc != nil {
+		return m.getLicensesFunc(ctx, companyID, params)
+	}
+	return &types.Licenses{}, nil
+}
+
+func (m *mockCredinformClient) GetPledgesAndLeasing(ctx context.Context, companyID string, params credinform.PledgesAndLeasingParams) (*types.PledgesAndLeasing, error) {
+	if m.getPledgesAndLeasingFunc != nil {
+		return m.getPledgesAndLeasingFunc(ctx, companyID, params)
+	}
+	return &types.PledgesAndLeasing{}, nil
+}
+
 // Mock для VerificationRepository
 type mockVerificationRepository struct {
 	createFunc          func(ctx context.Context, id string, inn string, requestedTypes []string, authorEmail string) error
 	updateStatusFunc    func(ctx context.Context, id string, status string) error
 	updateCompanyIDFunc func(ctx context.Context, id string, companyID string) error
-	addDataFunc         func(ctx context.Context, verificationID string, dataType string, data string) error
+	addDataFunc         func(ctx context.Context, verificationID string, dataType string, data string, metadata repository.DataMetadata) error
 }
 
-func (m *mockVerificationRepository) Create(ctx context.Context, id string, inn string, requestedTypes []string, authorEmail string) error {
+func (m *mockVerificationRepository) Create(ctx context.Context, id string, inn string, requestedTypes []string, authorEmail string, parameters json.RawMessage) error {
 	if m.createFunc != nil {
 		return m.createFunc(ctx, id, inn, requestedTypes, authorEmail)
 	}
@@ -102,9 +163,9 @@ func (m *mockVerificationRepository) UpdateCompanyID(ctx context.Context, id str
 	return nil
 }
 
-func (m *mockVerificationRepository) AddData(ctx context.Context, verificationID string, dataType string, data string) error {
+func (m *mockVerificationRepository) AddData(ctx context.Context, verificationID string, dataType string, data string, metadata repository.DataMetadata) error {
 	if m.addDataFunc != nil {
-		return m.addDataFunc(ctx, verificationID, dataType, data)
+		return m.addDataFunc(ctx, verificationID, dataType, data, metadata)
 	}
 	return nil
 }
@@ -117,93 +178,28 @@ func (m *mockVerificationRepository) AcquireStaleVerification(ctx context.Contex
 	return nil, nil
 }
 
-// Интерфейс для Credinform клиента (для внедрения зависимостей в тестах)
-type CredinformClientInterface interface {
-	SearchCompany(ctx context.Context, inn string) (*credinform.CompanyData, error)
-	GetBasicInformation(ctx context.Context, companyID string, params credinform.BasicInformationParams) (*types.BasicInformation, error)
-	GetActivities(ctx context.Context, companyID string, params credinform.ActivitiesParams) (*types.Activities, error)
-	GetAddressesByCredinform(ctx context.Context, companyID string, params credinform.AddressesByCredinformParams) (*types.AddressesByCredinform, error)
-	GetAddressesByUnifiedStateRegister(ctx context.Context, companyID string, params credinform.AddressesByUnifiedStateRegisterParams) (*types.AddressesByUnifiedStateRegister, error)
-	GetAffiliatedCompanies(ctx context.Context, companyID string, params credinform.AffiliatedCompaniesParams) (*types.AffiliatedCompanies, error)
-	GetArbitrageStatistics(ctx context.Context, companyID string, params credinform.ArbitrageStatisticsParams) (*types.ArbitrageStatistics, error)
+func (m *mockVerificationRepository) AcquireDeferredVerification(ctx context.Context) (*repository.Verification, error) {
+	return nil, nil
 }
 
-// Тестовая версия VerificationService для внедрения зависимостей
-type testVerificationService struct {
-	repo       repository.VerificationRepository
-	client     CredinformClientInterface
-	logger     *zap.Logger
-	maxRetries int
+func (m *mockVerificationRepository) ReleaseVerification(ctx context.Context, id string) error {
+	return nil
 }
 
-func NewTestVerificationService(repo repository.VerificationRepository, client CredinformClientInterface, logger *zap.Logger) *testVerificationService {
-	return &testVerificationService{
-		repo:       repo,
-		client:     client,
-		logger:     logger,
-		maxRetries: 3,
-	}
+func (m *mockVerificationRepository) RetryVerification(ctx context.Context, id string) (bool, error) {
+	return false, nil
 }
 
-func (s *testVerificationService) ProcessVerification(ctx context.Context, verificationID, inn string, requestedTypes []string) error {
-	// Поиск компании
-	companyData, err := s.client.SearchCompany(ctx, inn)
-	if err != nil {
-		s.logger.Error("Failed to search company", zap.Error(err))
-		if updateErr := s.repo.UpdateStatus(ctx, verificationID, "error"); updateErr != nil {
-			s.logger.Error("Failed to update status to error", zap.Error(updateErr))
-		}
-		return err
-	}
-
-	// Обновление company_id
-	if err := s.repo.UpdateCompanyID(ctx, verificationID, companyData.CompanyID); err != nil {
-		s.logger.Error("Failed to update company ID", zap.Error(err))
-		return err
-	}
-
-	// Получение данных по каждому типу
-	for _, dataType := range requestedTypes {
-		var fetchErr error
-
-		switch dataType {
-		case "basic_information":
-			_, fetchErr = s.client.GetBasicInformation(ctx, companyData.CompanyID, credinform.BasicInformationParams{})
-		case "activities":
-			_, fetchErr = s.client.GetActivities(ctx, companyData.CompanyID, credinform.ActivitiesParams{})
-		case "addresses_by_credinform":
-			_, fetchErr = s.client.GetAddressesByCredinform(ctx, companyData.CompanyID, credinform.AddressesByCredinformParams{})
-		case "addresses_by_unified_state_register":
-			_, fetchErr = s.client.GetAddressesByUnifiedStateRegister(ctx, companyData.CompanyID, credinform.AddressesByUnifiedStateRegisterParams{})
-		case "affiliated_companies":
-			_, fetchErr = s.client.GetAffiliatedCompanies(ctx, companyData.CompanyID, credinform.AffiliatedCompaniesParams{})
-		case "arbitrage_statistics":
-			_, fetchErr = s.client.GetArbitrageStatistics(ctx, companyData.CompanyID, credinform.ArbitrageStatisticsParams{})
-		default:
-			s.logger.Warn("Unknown data type requested", zap.String("type", dataType))
-			continue
-		}
-
-		if fetchErr != nil {
-			s.logger.Error("Failed to fetch data", zap.String("type", dataType), zap.Error(fetchErr))
-			continue
-		}
-
-		// Сохранение данных (в реальной реализации здесь была бы сериализация в JSON)
-		if err := s.repo.AddData(ctx, verificationID, dataType, "mock_data"); err != nil {
-			s.logger.Error("Failed to save data", zap.String("type", dataType), zap.Error(err))
-		}
-	}
-
-	// Обновление статуса на completed
-	if err := s.repo.UpdateStatus(ctx, verificationID, "completed"); err != nil {
-		s.logger.Error("Failed to update status to completed", zap.Error(err))
-		return err
-	}
+func (m *mockVerificationRepository) DeferVerification(ctx context.Context, id string) error {
+	return nil
+}
 
+func (m *mockVerificationRepository) RenewLease(ctx context.Context, id string) error {
 	return nil
 }
 
+var _ credinform.CredinformAPI = (*mockCredinformClient)(nil)
+
 func TestProcessVerification(t *testing.T) {
 	tests := []struct {
 		name                 string
@@ -213,21 +209,73 @@ func TestProcessVerification(t *testing.T) {
 		searchCompanyError   error
 		updateCompanyIDError error
 		updateStatusError    error
-		expectedError        string
+		expectedError        error
+		expectedStatuses     []string
+		expectedTemporary    bool
+		expectedDeferred     bool
 	}{
 		{
-			name:           "successful_processing",
-			verificationID: "test-verification-id",
-			inn:            "1234567890",
-			requestedTypes: []string{"basic_information", "activities"},
+			name:             "successful_processing",
+			verificationID:   "test-verification-id",
+			inn:              "1234567890",
+			requestedTypes:   []string{"basic_information", "activities"},
+			expectedStatuses: []string{"PROCESSING", "COMPLETED"},
 		},
 		{
 			name:               "search_company_error",
 			verificationID:     "test-verification-id",
 			inn:                "1234567890",
 			requestedTypes:     []string{"basic_information"},
-			searchCompanyError: errors.New("company not found"),
-			expectedError:      "company not found",
+			searchCompanyError: fmt.Errorf("all retry attempts failed: %w", credinform.ErrCompanyNotFound),
+			expectedError:      errors.New("company not found"),
+			expectedStatuses:   []string{"COMPANY_NOT_FOUND"},
+		},
+		{
+			name:               "search_company_unauthorized",
+			verificationID:     "test-verification-id",
+			inn:                "1234567890",
+			requestedTypes:     []string{"basic_information"},
+			searchCompanyError: &credinform.APIError{StatusCode: 401, Method: "Search/SearchCompany"},
+			expectedError:      errors.New("status 401"),
+			expectedStatuses:   []string{"ERROR"},
+		},
+		{
+			// Временная ошибка не фиксирует статус, проверка будет повторена
+			name:               "search_company_rate_limited",
+			verificationID:     "test-verification-id",
+			inn:                "1234567890",
+			requestedTypes:     []string{"basic_information"},
+			searchCompanyError: &credinform.APIError{StatusCode: 429, Method: "Search/SearchCompany"},
+			expectedError:      errors.New("status 429"),
+			expectedTemporary:  true,
+		},
+		{
+			name:               "search_company_server_error",
+			verificationID:     "test-verification-id",
+			inn:                "1234567890",
+			requestedTypes:     []string{"basic_information"},
+			searchCompanyError: fmt.Errorf("all retry attempts failed: %w", &credinform.APIError{StatusCode: 503, Method: "Search/SearchCompany"}),
+			expectedError:      errors.New("status 503"),
+			expectedTemporary:  true,
+		},
+		{
+			// Разомкнутый circuit breaker откладывает проверку без итогового статуса
+			name:               "search_company_circuit_open",
+			verificationID:     "test-verification-id",
+			inn:                "1234567890",
+			requestedTypes:     []string{"basic_information"},
+			searchCompanyError: fmt.Errorf("request Search/SearchCompany rejected: %w", credinform.ErrCircuitOpen),
+			expectedError:      errors.New("circuit breaker is open"),
+			expectedDeferred:   true,
+		},
+		{
+			name:               "search_company_api_error",
+			verificationID:     "test-verification-id",
+			inn:                "1234567890",
+			requestedTypes:     []string{"basic_information"},
+			searchCompanyError: errors.New("credinform API error"),
+			expectedError:      errors.New("credinform API error"),
+			expectedStatuses:   []string{"ERROR"},
 		},
 		{
 			name:                 "update_company_id_error",
@@ -235,7 +283,16 @@ func TestProcessVerification(t *testing.T) {
 			inn:                  "1234567890",
 			requestedTypes:       []string{"basic_information"},
 			updateCompanyIDError: errors.New("database error"),
-			expectedError:        "database error",
+			expectedError:        errors.New("database error"),
+		},
+		{
+			name:              "update_status_error",
+			verificationID:    "test-verification-id",
+			inn:               "1234567890",
+			requestedTypes:    []string{"basic_information"},
+			updateStatusError: errors.New("database error"),
+			expectedError:     errors.New("database error"),
+			expectedStatuses:  []string{"PROCESSING"},
 		},
 	}
 
@@ -243,11 +300,13 @@ func TestProcessVerification(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			logger := zaptest.NewLogger(t)
 
+			var statuses []string
 			mockRepo := &mockVerificationRepository{
 				updateCompanyIDFunc: func(ctx context.Context, id string, companyID string) error {
 					return tt.updateCompanyIDError
 				},
 				updateStatusFunc: func(ctx context.Context, id string, status string) error {
+					statuses = append(statuses, status)
 					return tt.updateStatusError
 				},
 			}
@@ -261,19 +320,30 @@ func TestProcessVerification(t *testing.T) {
 				},
 			}
 
-			service := NewTestVerificationService(mockRepo, mockClient, logger)
+			service := NewVerificationService(mockClient, mockRepo, logger)
 
-			err := service.ProcessVerification(context.Background(), tt.verificationID, tt.inn, tt.requestedTypes)
+			_, err := service.ProcessVerification(context.Background(), tt.verificationID, tt.inn, tt.requestedTypes, DataParams{})
 
-			if tt.expectedError != "" {
+			if tt.expectedError != nil {
 				if err == nil {
 					t.Errorf("Expected error containing '%s', got nil", tt.expectedError)
-				} else if err.Error() != tt.expectedError {
-					t.Errorf("Expected error '%s', got '%s'", tt.expectedError, err.Error())
+				} else if !strings.Contains(err.Error(), tt.expectedError.Error()) {
+					t.Errorf("Expected error containing '%s', got '%s'", tt.expectedError, err.Error())
 				}
 			} else if err != nil {
 				t.Errorf("Expected no error, got %v", err)
 			}
+
+			if IsTemporary(err) != tt.expectedTemporary {
+				t.Errorf("Expected temporary=%v, got %v", tt.expectedTemporary, IsTemporary(err))
+			}
+			if IsDeferred(err) != tt.expectedDeferred {
+				t.Errorf("Expected deferred=%v, got %v", tt.expectedDeferred, IsDeferred(err))
+			}
+
+			if strings.Join(statuses, ",") != strings.Join(tt.expectedStatuses, ",") {
+				t.Errorf("Expected statuses %v, got %v", tt.expectedStatuses, statuses)
+			}
 		})
 	}
 }
@@ -281,14 +351,25 @@ func TestProcessVerification(t *testing.T) {
 func TestProcessVerificationDataTypes(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 
-	mockRepo := &mockVerificationRepository{}
+	var mu sync.Mutex
+	saved := make(map[string]string)
+	savedMetadata := make(map[string]repository.DataMetadata)
+	mockRepo := &mockVerificationRepository{
+		addDataFunc: func(ctx context.Context, verificationID string, dataType string, data string, metadata repository.DataMetadata) error {
+			mu.Lock()
+			defer mu.Unlock()
+			saved[dataType] = data
+			savedMetadata[dataType] = metadata
+			return nil
+		},
+	}
 	mockClient := &mockCredinformClient{
 		searchCompanyFunc: func(ctx context.Context, inn string) (*credinform.CompanyData, error) {
 			return &credinform.CompanyData{CompanyID: "test-company-id"}, nil
 		},
 	}
 
-	service := NewTestVerificationService(mockRepo, mockClient, logger)
+	service := NewVerificationService(mockClient, mockRepo, logger)
 
 	dataTypes := []string{
 		"basic_information",
@@ -297,19 +378,64 @@ func TestProcessVerificationDataTypes(t *testing.T) {
 		"addresses_by_unified_state_register",
 		"affiliated_companies",
 		"arbitrage_statistics",
+		"financial_statements",
+		"financial_analysis",
+		"arbitrage_cases",
+		"bankruptcy_messages",
+		"enforcement_proceedings",
+		"state_contracts",
+		"licenses",
+		"pledges_and_leasing",
 		"unknown_type", // должен быть проигнорирован
 	}
 
-	err := service.ProcessVerification(context.Background(), "test-id", "1234567890", dataTypes)
+	_, err := service.ProcessVerification(context.Background(), "test-id", "1234567890", dataTypes, DataParams{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
+
+	if len(saved) != len(dataTypes)-1 {
+		t.Errorf("Expected %d saved data types, got %d", len(dataTypes)-1, len(saved))
+	}
+	if _, ok := saved["unknown_type"]; ok {
+		t.Error("Unknown data type should not be saved")
+	}
+	for dataType, data := range saved {
+		var result map[string]interface{}
+		if err := json.Unmarshal([]byte(data), &result); err != nil {
+			t.Errorf("Saved data for %s is not valid JSON: %v", dataType, err)
+			continue
+		}
+		// Изменчивые поля не должны попадать в содержимое, иначе кэш не дедуплицирует ответы
+		if _, ok := result["processed_at"]; ok {
+			t.Errorf("Saved data for %s should not contain processed_at", dataType)
+		}
+
+		metadata := savedMetadata[dataType]
+		if metadata.Status != "completed" {
+			t.Errorf("Expected status 'completed' for %s, got %v", dataType, metadata.Status)
+		}
+		if metadata.CompanyID != "test-company-id" {
+			t.Errorf("Expected company_id 'test-company-id' for %s, got %v", dataType, metadata.CompanyID)
+		}
+		if metadata.ProcessedAt.IsZero() {
+			t.Errorf("Expected processed_at to be set for %s", dataType)
+		}
+	}
 }
 
 func TestProcessVerificationWithCredinformErrors(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 
-	mockRepo := &mockVerificationRepository{}
+	var savedData string
+	var savedMetadata repository.DataMetadata
+	mockRepo := &mockVerificationRepository{
+		addDataFunc: func(ctx context.Context, verificationID string, dataType string, data string, metadata repository.DataMetadata) error {
+			savedData = data
+			savedMetadata = metadata
+			return nil
+		},
+	}
 	mockClient := &mockCredinformClient{
 		searchCompanyFunc: func(ctx context.Context, inn string) (*credinform.CompanyData, error) {
 			return &credinform.CompanyData{CompanyID: "test-company-id"}, nil
@@ -319,11 +445,500 @@ func TestProcessVerificationWithCredinformErrors(t *testing.T) {
 		},
 	}
 
-	service := NewTestVerificationService(mockRepo, mockClient, logger)
+	service := NewVerificationService(mockClient, mockRepo, logger)
 
 	// Ошибки получения данных не должны прерывать весь процесс
-	err := service.ProcessVerification(context.Background(), "test-id", "1234567890", []string{"basic_information"})
+	_, err := service.ProcessVerification(context.Background(), "test-id", "1234567890", []string{"basic_information"}, DataParams{})
 	if err != nil {
 		t.Errorf("Expected no error when data fetching fails, got %v", err)
 	}
+
+	// Ошибка должна быть сохранена в данных проверки
+	var result map[string]interface{}
+	if err := json.Unmarshal([]byte(savedData), &result); err != nil {
+		t.Fatalf("Saved error data is not valid JSON: %v", err)
+	}
+	if result["error"] != "credinform API error" {
+		t.Errorf("Expected saved error 'credinform API error', got %v", result["error"])
+	}
+	if savedMetadata.Status != "error" || savedMetadata.Error != "credinform API error" {
+		t.Errorf("Expected error metadata, got %+v", savedMetadata)
+	}
+}
+
+func TestProcessVerificationDerivedData(t *testing.T) {
+	revenue := 1000.0
+	tests := []struct {
+		name           string
+		requestedTypes []string
+		statementsErr  error
+		expectedSaved  []string
+		expectedStatus string
+	}{
+		{
+			// Отчётность загружается для расчёта, но не сохраняется, если не запрошена
+			name:           "analysis_only",
+			requestedTypes: []string{"financial_analysis"},
+			expectedSaved:  []string{"financial_analysis"},
+			expectedStatus: "completed",
+		},
+		{
+			name:           "analysis_with_statements",
+			requestedTypes: []string{"financial_analysis", "financial_statements"},
+			expectedSaved:  []string{"financial_analysis", "financial_statements"},
+			expectedStatus: "completed",
+		},
+		{
+			name:           "statements_unavailable",
+			requestedTypes: []string{"financial_analysis"},
+			statementsErr:  errors.New("credinform API error"),
+			expectedSaved:  []string{"financial_analysis"},
+			expectedStatus: "error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			saved := make(map[string]repository.DataMetadata)
+			mockRepo := &mockVerificationRepository{
+				addDataFunc: func(ctx context.Context, verificationID string, dataType string, data string, metadata repository.DataMetadata) error {
+					mu.Lock()
+					defer mu.Unlock()
+					saved[dataType] = metadata
+					return nil
+				},
+			}
+			var calls atomic.Int32
+			mockClient := &mockCredinformClient{
+				getFinancialStatementsFunc: func(ctx context.Context, companyID string, params credinform.FinancialStatementsParams) (*types.FinancialStatements, error) {
+					calls.Add(1)
+					if tt.statementsErr != nil {
+						return nil, tt.statementsErr
+					}
+					return &types.FinancialStatements{Years: []*types.FinancialYear{
+						{Year: 2024, ProfitAndLoss: &types.ProfitAndLoss{Revenue: &revenue}},
+					}}, nil
+				},
+			}
+
+			service := NewVerificationService(mockClient, mockRepo, zaptest.NewLogger(t))
+			if _, err := service.ProcessVerification(context.Background(), "test-id", "1234567890", tt.requestedTypes, DataParams{}); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if calls.Load() != 1 {
+				t.Errorf("Expected financial statements to be fetched once, got %d", calls.Load())
+			}
+			if len(saved) != len(tt.expectedSaved) {
+				t.Errorf("Expected saved types %v, got %v", tt.expectedSaved, saved)
+			}
+			for _, dataType := range tt.expectedSaved {
+				if _, ok := saved[dataType]; !ok {
+					t.Errorf("Expected %s to be saved", dataType)
+				}
+			}
+			if status := saved["financial_analysis"].Status; status != tt.expectedStatus {
+				t.Errorf("Expected financial_analysis status %q, got %q", tt.expectedStatus, status)
+			}
+		})
+	}
+}
+
+func TestProcessVerificationArbitrageParams(t *testing.T) {
+	tests := []struct {
+		name          string
+		defaults      DataParams
+		overrides     DataParams
+		expectedFrom  string
+		expectedSides []string
+	}{
+		{
+			name:          "config_defaults",
+			defaults:      DataParams{ArbitrageLookback: "2024-01-01", ArbitrageSides: []string{"Claimant", "Defendant"}},
+			expectedFrom:  "2024-01-01T00:00:00",
+			expectedSides: []string{"Claimant", "Defendant"},
+		},
+		{
+			// Переопределения из сообщения заменяют только заданные поля
+			name:          "message_overrides",
+			defaults:      DataParams{ArbitrageLookback: "2024-01-01", ArbitrageSides: []string{"Claimant", "Defendant"}},
+			overrides:     DataParams{ArbitrageSides: []string{"Defendant"}},
+			expectedFrom:  "2024-01-01T00:00:00",
+			expectedSides: []string{"Defendant"},
+		},
+		{
+			name:          "lookback_override",
+			defaults:      DataParams{ArbitrageLookback: "2024-01-01", ArbitrageSides: []string{"Claimant"}},
+			overrides:     DataParams{ArbitrageLookback: "2022-07-15"},
+			expectedFrom:  "2022-07-15T00:00:00",
+			expectedSides: []string{"Claimant"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotParams credinform.ArbitrageStatisticsParams
+			mockClient := &mockCredinformClient{
+				getArbitrageStatisticsFunc: func(ctx context.Context, companyID string, params credinform.ArbitrageStatisticsParams) (*types.ArbitrageStatistics, error) {
+					gotParams = params
+					return &types.ArbitrageStatistics{}, nil
+				},
+			}
+			var savedMetadata repository.DataMetadata
+			mockRepo := &mockVerificationRepository{
+				addDataFunc: func(ctx context.Context, verificationID string, dataType string, data string, metadata repository.DataMetadata) error {
+					savedMetadata = metadata
+					return nil
+				},
+			}
+
+			service := NewVerificationService(mockClient, mockRepo, zaptest.NewLogger(t), WithDataParams(tt.defaults))
+			if _, err := service.ProcessVerification(context.Background(), "test-id", "1234567890", []string{"arbitrage_statistics"}, tt.overrides); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if gotParams.LastCaseChangeDateRange.From != tt.expectedFrom {
+				t.Errorf("Expected lastCaseChangeDateRange.from %s, got %s", tt.expectedFrom, gotParams.LastCaseChangeDateRange.From)
+			}
+			if strings.Join(gotParams.ArbitrageSideCommonType, ",") != strings.Join(tt.expectedSides, ",") {
+				t.Errorf("Expected arbitrage sides %v, got %v", tt.expectedSides, gotParams.ArbitrageSideCommonType)
+			}
+
+			// Использованные параметры сохраняются в метаданных для аудита
+			var audited credinform.ArbitrageStatisticsParams
+			if err := json.Unmarshal(savedMetadata.Parameters, &audited); err != nil {
+				t.Fatalf("Expected parameters in metadata, got %s: %v", savedMetadata.Parameters, err)
+			}
+			if audited.LastCaseChangeDateRange.From != tt.expectedFrom {
+				t.Errorf("Expected audited from %s, got %s", tt.expectedFrom, audited.LastCaseChangeDateRange.From)
+			}
+		})
+	}
+}
+
+func TestProcessVerificationBankruptcyResult(t *testing.T) {
+	stringPtr := func(v string) *string { return &v }
+	boolPtr := func(v bool) *bool { return &v }
+	introduced := &types.BankruptcyMessages{MessageList: []*types.BankruptcyMessage{{
+		Kind:            stringPtr(types.BankruptcyMessageProcedureIntroduced),
+		PublicationDate: stringPtr("2024-04-10"),
+		Stage:           stringPtr(types.BankruptcyStageObservation),
+	}}}
+
+	tests := []struct {
+		name           string
+		requestedTypes []string
+		messages       *types.BankruptcyMessages
+		messagesErr    error
+		expected       *bool
+	}{
+		{
+			name:           "active_procedure",
+			requestedTypes: []string{"bankruptcy_messages"},
+			messages:       introduced,
+			expected:       boolPtr(true),
+		},
+		{
+			name:           "no_procedure",
+			requestedTypes: []string{"bankruptcy_messages"},
+			messages:       &types.BankruptcyMessages{},
+			expected:       boolPtr(false),
+		},
+		{
+			// Признак не передаётся, если сообщения не запрашивались
+			name:           "not_requested",
+			requestedTypes: []string{"basic_information"},
+			messages:       introduced,
+		},
+		{
+			// Неизвестное состояние не выдаётся за отсутствие процедуры
+			name:           "fetch_error",
+			requestedTypes: []string{"bankruptcy_messages"},
+			messagesErr:    errors.New("api error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &mockCredinformClient{
+				getBankruptcyMessagesFunc: func(ctx context.Context, companyID string, params credinform.BankruptcyMessagesParams) (*types.BankruptcyMessages, error) {
+					return tt.messages, tt.messagesErr
+				},
+			}
+
+			service := NewVerificationService(mockClient, &mockVerificationRepository{}, zaptest.NewLogger(t))
+			result, err := service.ProcessVerification(context.Background(), "test-id", "1234567890", tt.requestedTypes, DataParams{})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			got := result.ActiveBankruptcyProcedure
+			if (got == nil) != (tt.expected == nil) || (got != nil && *got != *tt.expected) {
+				t.Errorf("Expected active bankruptcy procedure %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestProcessVerificationLicenses(t *testing.T) {
+	tests := []struct {
+		name                string
+		requestedTypes      []string
+		activitiesErr       error
+		overrides           DataParams
+		expectedUnlicensed  int
+		expectedUnavailable bool
+	}{
+		{
+			name:               "default_groups",
+			requestedTypes:     []string{"licenses"},
+			expectedUnlicensed: 1,
+		},
+		{
+			// Группы из сообщения заменяют группы конфигурации
+			name:           "override_groups",
+			requestedTypes: []string{"licenses"},
+			overrides:      DataParams{LicensedActivities: []string{"62"}},
+		},
+		{
+			// Запрошенные вместе виды деятельности загружаются один раз
+			name:               "with_activities",
+			requestedTypes:     []string{"activities", "licenses"},
+			expectedUnlicensed: 1,
+		},
+		{
+			// Без видов деятельности лицензии сохраняются, а сверка помечается недоступной
+			name:                "activities_error",
+			requestedTypes:      []string{"licenses"},
+			activitiesErr:       errors.New("api error"),
+			expectedUnavailable: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code := "86.10"
+			var activitiesCalls atomic.Int32
+			mockClient := &mockCredinformClient{
+				getActivitiesFunc: func(ctx context.Context, companyID string, params credinform.ActivitiesParams) (*types.Activities, error) {
+					activitiesCalls.Add(1)
+					if tt.activitiesErr != nil {
+						return nil, tt.activitiesErr
+					}
+					return &types.Activities{KindOfActivityList: []*types.KindOfActivity{{Industry: &types.Industry{Code: &code}}}}, nil
+				},
+			}
+			var mu sync.Mutex
+			saved := make(map[string]string)
+			savedMetadata := make(map[string]repository.DataMetadata)
+			mockRepo := &mockVerificationRepository{
+				addDataFunc: func(ctx context.Context, verificationID string, dataType string, data string, metadata repository.DataMetadata) error {
+					mu.Lock()
+					defer mu.Unlock()
+					saved[dataType], savedMetadata[dataType] = data, metadata
+					return nil
+				},
+			}
+
+			service := NewVerificationService(mockClient, mockRepo, zaptest.NewLogger(t), WithDataParams(DefaultDataParams()))
+			if _, err := service.ProcessVerification(context.Background(), "test-id", "1234567890", tt.requestedTypes, tt.overrides); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if calls := activitiesCalls.Load(); calls != 1 {
+				t.Errorf("Expected activities to be requested once, got %d", calls)
+			}
+			if len(saved) != len(tt.requestedTypes) {
+				t.Errorf("Expected only requested types to be saved, got %v", saved)
+			}
+			if savedMetadata["licenses"].Status != "completed" {
+				t.Fatalf("Expected licenses status completed, got %s", savedMetadata["licenses"].Status)
+			}
+			var stored struct {
+				Summary struct {
+					UnlicensedActivities  []json.RawMessage `json:"unlicensed_activities"`
+					ActivitiesUnavailable bool              `json:"activities_unavailable"`
+				} `json:"summary"`
+			}
+			if err := json.Unmarshal([]byte(saved["licenses"]), &stored); err != nil {
+				t.Fatalf("Failed to unmarshal saved data %s: %v", saved["licenses"], err)
+			}
+			if len(stored.Summary.UnlicensedActivities) != tt.expectedUnlicensed {
+				t.Errorf("Expected %d unlicensed activities, got %s", tt.expectedUnlicensed, saved["licenses"])
+			}
+			if stored.Summary.ActivitiesUnavailable != tt.expectedUnavailable {
+				t.Errorf("Expected activities_unavailable %t, got %s", tt.expectedUnavailable, saved["licenses"])
+			}
+		})
+	}
+}
+
+func TestProcessVerificationPledgesAndLeasing(t *testing.T) {
+	var gotParams credinform.PledgesAndLeasingParams
+	mockClient := &mockCredinformClient{
+		getPledgesAndLeasingFunc: func(ctx context.Context, companyID string, params credinform.PledgesAndLeasingParams) (*types.PledgesAndLeasing, error) {
+			gotParams = params
+			return &types.PledgesAndLeasing{PledgeList: []*types.PledgeNotification{{}}}, nil
+		},
+	}
+	var saved string
+	var savedMetadata repository.DataMetadata
+	mockRepo := &mockVerificationRepository{
+		addDataFunc: func(ctx context.Context, verificationID string, dataType string, data string, metadata repository.DataMetadata) error {
+			saved, savedMetadata = data, metadata
+			return nil
+		},
+	}
+
+	service := NewVerificationService(mockClient, mockRepo, zaptest.NewLogger(t))
+	if _, err := service.ProcessVerification(context.Background(), "test-id", "1234567890", []string{"pledges_and_leasing"}, DataParams{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Запрашиваются только обременения имущества самой компании
+	if strings.Join(gotParams.ParticipationRoles, ",") != "Pledgor,Lessee" {
+		t.Errorf("Expected roles Pledgor and Lessee, got %v", gotParams.ParticipationRoles)
+	}
+	if !strings.Contains(string(savedMetadata.Parameters), "Pledgor") {
+		t.Errorf("Expected roles in metadata parameters, got %s", savedMetadata.Parameters)
+	}
+
+	var stored struct {
+		PledgeList []json.RawMessage `json:"pledgeNotificationList"`
+		Summary    struct {
+			ActivePledges int `json:"active_pledges"`
+		} `json:"summary"`
+	}
+	if err := json.Unmarshal([]byte(saved), &stored); err != nil {
+		t.Fatalf("Failed to unmarshal saved data %s: %v", saved, err)
+	}
+	if len(stored.PledgeList) != 1 || stored.Summary.ActivePledges != 1 {
+		t.Errorf("Unexpected saved pledges and leasing: %s", saved)
+	}
+}
+
+func TestProcessVerificationEnforcementProceedings(t *testing.T) {
+	amount := 1500.0
+	startDate := time.Now().AddDate(0, -2, 0).Format("2006-01-02")
+	mockClient := &mockCredinformClient{
+		getEnforcementProceedingsFunc: func(ctx context.Context, companyID string, params credinform.EnforcementProceedingsParams) (*types.EnforcementProceedings, error) {
+			return &types.EnforcementProceedings{ProceedingList: []*types.EnforcementProceeding{
+				{StartDate: &startDate, RemainingDebt: &amount},
+			}}, nil
+		},
+	}
+	var saved string
+	mockRepo := &mockVerificationRepository{
+		addDataFunc: func(ctx context.Context, verificationID string, dataType string, data string, metadata repository.DataMetadata) error {
+			saved = data
+			return nil
+		},
+	}
+
+	service := NewVerificationService(mockClient, mockRepo, zaptest.NewLogger(t))
+	if _, err := service.ProcessVerification(context.Background(), "test-id", "1234567890", []string{"enforcement_proceedings"}, DataParams{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Производства и сводка сохраняются одним документом
+	var stored struct {
+		ProceedingList []json.RawMessage `json:"enforcementProceedingList"`
+		Summary        struct {
+			OpenCount          int     `json:"open_count"`
+			OutstandingAmount  float64 `json:"outstanding_amount"`
+			OpenedLast12Months int     `json:"opened_last_12_months"`
+		} `json:"summary"`
+	}
+	if err := json.Unmarshal([]byte(saved), &stored); err != nil {
+		t.Fatalf("Failed to unmarshal saved data %s: %v", saved, err)
+	}
+	if len(stored.ProceedingList) != 1 || stored.Summary.OpenCount != 1 ||
+		stored.Summary.OutstandingAmount != amount || stored.Summary.OpenedLast12Months != 1 {
+		t.Errorf("Unexpected saved enforcement proceedings: %s", saved)
+	}
+}
+
+func TestProcessVerificationInterrupted(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var statuses []string
+	var savedTypes []string
+	var mu sync.Mutex
+	mockRepo := &mockVerificationRepository{
+		updateStatusFunc: func(ctx context.Context, id string, status string) error {
+			statuses = append(statuses, status)
+			return nil
+		},
+		addDataFunc: func(ctx context.Context, verificationID string, dataType string, data string, metadata repository.DataMetadata) error {
+			mu.Lock()
+			defer mu.Unlock()
+			savedTypes = append(savedTypes, dataType)
+			return nil
+		},
+	}
+	mockClient := &mockCredinformClient{
+		getBasicInformationFunc: func(ctx context.Context, companyID string, params credinform.BasicInformationParams) (*types.BasicInformation, error) {
+			// Остановка воркера во время запроса к Credinform
+			cancel()
+			return nil, ctx.Err()
+		},
+	}
+
+	service := NewVerificationService(mockClient, mockRepo, logger)
+
+	_, err := service.ProcessVerification(ctx, "test-id", "1234567890", []string{"basic_information"}, DataParams{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+
+	if strings.Join(statuses, ",") != "PROCESSING" {
+		t.Errorf("Expected interrupted verification to stay in PROCESSING, got statuses %v", statuses)
+	}
+	if len(savedTypes) != 0 {
+		t.Errorf("Expected no data to be saved for interrupted fetch, got %v", savedTypes)
+	}
+}
+
+func TestProcessVerificationDeferredByOpenCircuit(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	var statuses []string
+	var mu sync.Mutex
+	var savedTypes []string
+	mockRepo := &mockVerificationRepository{
+		updateStatusFunc: func(ctx context.Context, id string, status string) error {
+			statuses = append(statuses, status)
+			return nil
+		},
+		addDataFunc: func(ctx context.Context, verificationID string, dataType string, data string, metadata repository.DataMetadata) error {
+			mu.Lock()
+			defer mu.Unlock()
+			savedTypes = append(savedTypes, dataType)
+			return nil
+		},
+	}
+	mockClient := &mockCredinformClient{
+		getActivitiesFunc: func(ctx context.Context, companyID string, params credinform.ActivitiesParams) (*types.Activities, error) {
+			// Breaker разомкнулся после поиска компании
+			return nil, fmt.Errorf("request CompanyInformation/Activities rejected: %w", credinform.ErrCircuitOpen)
+		},
+	}
+
+	service := NewVerificationService(mockClient, mockRepo, logger)
+
+	_, err := service.ProcessVerification(context.Background(), "test-id", "1234567890", []string{"basic_information", "activities"}, DataParams{})
+	if !IsDeferred(err) {
+		t.Fatalf("Expected deferred error, got %v", err)
+	}
+
+	if strings.Join(statuses, ",") != "PROCESSING" {
+		t.Errorf("Expected deferred verification not to be completed, got statuses %v", statuses)
+	}
+	// Ошибка разомкнутого breaker не сохраняется как данные проверки
+	if strings.Join(savedTypes, ",") != "basic_information" {
+		t.Errorf("Expected only basic_information to be saved, got %v", savedTypes)
+	}
 }
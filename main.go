@@ -2,23 +2,71 @@ package main
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
+	"flag"
 	"fmt"
+	"net/http"
 	"os"
-	"os/signal"
 	"scoring_worker/internal/config"
 	"scoring_worker/internal/credinform"
+	"scoring_worker/internal/credinform/cassette"
 	"scoring_worker/internal/logger"
 	"scoring_worker/internal/messaging"
+	"scoring_worker/internal/migrations"
 	"scoring_worker/internal/repository"
 	"scoring_worker/internal/service"
-	"syscall"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
 )
 
+// workerFlags — флаги запуска воркера
+type workerFlags struct {
+	// record — файл кассеты, в который записывается обмен с Credinform
+	record string
+	// replay — файл кассеты, из которого воспроизводятся ответы Credinform вместо обращения к API
+	replay string
+}
+
+func parseWorkerFlags(args []string) (workerFlags, error) {
+	var flags workerFlags
+	fs := flag.NewFlagSet("scoring_worker", flag.ContinueOnError)
+	fs.StringVar(&flags.record, "record", "", "record Credinform traffic to the cassette file")
+	fs.StringVar(&flags.replay, "replay", "", "replay Credinform responses from the cassette file")
+	fs.Usage = func() { fmt.Fprintln(fs.Output(), usage) }
+	if err := fs.Parse(args); err != nil {
+		return workerFlags{}, err
+	}
+	if fs.NArg() > 0 {
+		return workerFlags{}, fmt.Errorf("unexpected arguments %v\n%s", fs.Args(), usage)
+	}
+	if flags.record != "" && flags.replay != "" {
+		return workerFlags{}, fmt.Errorf("--record and --replay cannot be used together")
+	}
+	return flags, nil
+}
+
 func main() {
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		if err := runCommand(os.Args[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	flags, err := parseWorkerFlags(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
 	cfg, err := setupConfig()
 	if err != nil {
 		panic(fmt.Sprintf("failed to setup config: %v", err))
@@ -36,21 +84,89 @@ func main() {
 	}
 	defer db.Close()
 
+	if cfg.Database.AutoMigrate {
+		if err := migrateDatabase(db, log); err != nil {
+			log.Fatal("failed to migrate database", zap.Error(err))
+		}
+	}
+
 	natsClient, err := setupNATSClient(cfg, log)
 	if err != nil {
 		log.Fatal("failed to setup nats client", zap.Error(err))
 	}
 	defer natsClient.Close()
 
-	cacheRepo := repository.NewDataCacheRepository(db, log)
-	repo := repository.NewVerificationRepository(db, cacheRepo, log)
-	credinformClient := credinform.NewClient(&cfg.Credinform, log)
-	verificationService := service.NewVerificationService(credinformClient, repo, log)
+	workerID := setupWorkerID(cfg)
+	log = log.With(zap.String("worker_id", workerID))
 
-	worker := NewWorker(log, repo, verificationService, natsClient, cfg.WorkerConcurrency)
+	cacheRepo := repository.NewDataCacheRepository(db, log)
+	repo := repository.NewVerificationRepository(db, cacheRepo, repository.LeaseConfig{
+		OwnerID:       workerID,
+		TTL:           time.Duration(cfg.Worker.LeaseTTL) * time.Second,
+		MaxAttempts:   cfg.Worker.MaxAttempts,
+		RetryDelay:    time.Duration(cfg.Worker.RetryDelay) * time.Second,
+		RetryMaxDelay: time.Duration(cfg.Worker.RetryMaxDelay) * time.Second,
+	}, log)
+	var credinformOpts []credinform.Option
+	transport, err := setupCredinformTransport(cfg, flags, log)
+	if err != nil {
+		log.Fatal("failed to setup credinform transport", zap.Error(err))
+	}
+	if transport != nil {
+		credinformOpts = append(credinformOpts, credinform.WithTransport(transport))
+	}
+	if cfg.Credinform.RateLimit.Enabled && cfg.Credinform.RateLimit.Distributed {
+		store := repository.NewRateLimitRepository(db, log)
+		credinformOpts = append(credinformOpts, credinform.WithLimiter(credinform.NewDistributedLimiter(&cfg.Credinform.RateLimit, store, log)))
+	}
+	credinformClient := credinform.NewClient(&cfg.Credinform, log, credinformOpts...)
+	if credinformClient == nil {
+		log.Fatal("failed to setup credinform client")
+	}
+	dataParams, err := service.DataParamsFromConfig(&cfg.Data)
+	if err != nil {
+		log.Fatal("failed to load data parameters", zap.Error(err))
+	}
+	verificationService := service.NewVerificationService(credinformClient, repo, log, service.WithDataParams(dataParams))
+
+	opts := WorkerOptions{
+		Concurrency:         cfg.WorkerConcurrency,
+		ShutdownGracePeriod: time.Duration(cfg.Worker.ShutdownGracePeriod) * time.Second,
+		LeaseRenewInterval:  time.Duration(cfg.Worker.LeaseRenewInterval) * time.Second,
+		RecoveryInterval:    time.Duration(cfg.Worker.RecoveryInterval) * time.Second,
+		Circuit:             credinformClient,
+	}
+	if cfg.CacheGC.Enabled {
+		opts.CacheGCInterval = time.Duration(cfg.CacheGC.Interval) * time.Second
+		opts.CacheGCOlderThan = time.Duration(cfg.CacheGC.OlderThan) * time.Second
+	}
+	worker := NewWorker(log, repo, cacheRepo, verificationService, natsClient, opts)
 	worker.Run()
 }
 
+// setupCredinformTransport возвращает транспорт для записи или воспроизведения
+// обмена с Credinform либо nil, если воркер обращается к API напрямую
+func setupCredinformTransport(cfg *config.Config, flags workerFlags, log *zap.Logger) (http.RoundTripper, error) {
+	switch {
+	case flags.replay != "":
+		c, err := cassette.Load(flags.replay)
+		if err != nil {
+			return nil, err
+		}
+		// Учётные данные в режиме воспроизведения не используются, но обязательны для клиента
+		if cfg.Credinform.Username == "" || cfg.Credinform.Password == "" {
+			cfg.Credinform.Username = "replay"
+			cfg.Credinform.Password = base64.StdEncoding.EncodeToString([]byte("replay"))
+		}
+		log.Warn("Replaying Credinform responses from cassette", zap.String("cassette", flags.replay), zap.Int("interactions", len(c.Interactions)))
+		return cassette.NewReplayer(c), nil
+	case flags.record != "":
+		log.Warn("Recording Credinform traffic to cassette", zap.String("cassette", flags.record))
+		return cassette.NewRecorder(flags.record, nil), nil
+	}
+	return nil, nil
+}
+
 func setupConfig() (*config.Config, error) {
 	return config.Load()
 }
@@ -67,116 +183,31 @@ func setupDatabase(cfg *config.Config) (*pgxpool.Pool, error) {
 	return db, nil
 }
 
-func setupNATSClient(cfg *config.Config, log *zap.Logger) (messaging.NATSClient, error) {
-	return messaging.NewNATSClient(cfg.NATS.URL, log)
-}
-
-type Worker struct {
-	log                 *zap.Logger
-	repo                repository.VerificationRepository
-	verificationService service.VerificationService
-	natsClient          messaging.NATSClient
-	concurrencyCh       chan struct{}
-}
-
-func NewWorker(log *zap.Logger, repo repository.VerificationRepository, verificationService service.VerificationService, natsClient messaging.NATSClient, concurrency int) *Worker {
-	if concurrency <= 0 {
-		concurrency = 5
-	}
-	return &Worker{
-		log:                 log,
-		repo:                repo,
-		verificationService: verificationService,
-		natsClient:          natsClient,
-		concurrencyCh:       make(chan struct{}, concurrency),
+func migrateDatabase(db *pgxpool.Pool, log *zap.Logger) error {
+	migrator, err := migrations.NewMigrator(db, log)
+	if err != nil {
+		return err
 	}
-}
-
-func (w *Worker) Run() {
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	go w.resumeProcessing(ctx)
-
-	err := w.subscribeToVerifications(ctx)
+	applied, err := migrator.Up(context.Background())
 	if err != nil {
-		w.log.Fatal("Failed to subscribe to NATS", zap.Error(err))
+		return err
 	}
-
-	w.log.Info("Worker started, waiting for messages...")
-	w.waitForShutdownSignal()
+	log.Info("database schema is up to date", zap.Int("applied_migrations", applied))
+	return nil
 }
 
-func (w *Worker) resumeProcessing(ctx context.Context) {
-	w.log.Info("Checking for stale verifications to resume...")
-	for {
-		verification, err := w.repo.AcquireStaleVerification(ctx)
-		if err != nil {
-			w.log.Error("Failed to acquire stale verification", zap.Error(err))
-			time.Sleep(5 * time.Second)
-			continue
-		}
-
-		if verification == nil {
-			w.log.Info("No more stale verifications to resume.")
-			break
-		}
-
-		go func(v repository.Verification) {
-			w.concurrencyCh <- struct{}{}
-			defer func() { <-w.concurrencyCh }()
-
-			w.log.Info("Resuming verification processing", zap.String("id", v.ID), zap.String("inn", v.Inn))
-			if err := w.verificationService.ProcessVerification(context.Background(), v.ID, v.Inn, v.RequestedDataTypes); err != nil {
-				w.log.Error("Failed to process resumed verification", zap.Error(err), zap.String("id", v.ID))
-				w.natsClient.PublishVerificationCompleted(ctx, v.ID, "ERROR", err.Error())
-				return
-			}
-			w.log.Info("Resumed verification processing completed", zap.String("id", v.ID))
-			err = w.natsClient.PublishVerificationCompleted(ctx, v.ID, "COMPLETED", "")
-			if err != nil {
-				w.log.Error("Failed to publish completion notification for resumed verification", zap.Error(err), zap.String("id", v.ID))
-			}
-		}(*verification)
+// setupWorkerID возвращает идентификатор экземпляра, под которым воркер арендует проверки
+func setupWorkerID(cfg *config.Config) string {
+	if cfg.Worker.ID != "" {
+		return cfg.Worker.ID
 	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "scoring_worker"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
 }
 
-func (w *Worker) subscribeToVerifications(ctx context.Context) error {
-	return w.natsClient.SubscribeVerificationCreate(ctx, func(msg messaging.VerificationCreateMessage) {
-		w.log.Info("Received verification.create", zap.String("id", msg.VerificationID), zap.String("inn", msg.INN))
-
-		err := w.repo.Create(ctx, msg.VerificationID, msg.INN, msg.RequestedTypes, msg.AuthorEmail)
-		if err != nil {
-			w.log.Error("Failed to create verification in DB", zap.Error(err), zap.String("id", msg.VerificationID))
-			w.natsClient.PublishVerificationCompleted(ctx, msg.VerificationID, "ERROR", err.Error())
-			return
-		}
-
-		go func(id string, inn string, requestedTypes []string) {
-			w.concurrencyCh <- struct{}{}
-			defer func() { <-w.concurrencyCh }()
-
-			w.log.Info("Starting verification processing", zap.String("id", id), zap.String("inn", inn))
-
-			if err := w.verificationService.ProcessVerification(context.Background(), id, inn, requestedTypes); err != nil {
-				w.log.Error("Failed to process verification", zap.Error(err), zap.String("id", id))
-				w.natsClient.PublishVerificationCompleted(ctx, id, "ERROR", err.Error())
-				return
-			}
-
-			w.log.Info("Verification processing completed", zap.String("id", id))
-
-			err = w.natsClient.PublishVerificationCompleted(ctx, id, "COMPLETED", "")
-			if err != nil {
-				w.log.Error("Failed to publish completion notification", zap.Error(err), zap.String("id", id))
-			}
-		}(msg.VerificationID, msg.INN, msg.RequestedTypes)
-	})
-}
-
-func (w *Worker) waitForShutdownSignal() {
-	sig := make(chan os.Signal, 1)
-	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
-	<-sig
-	w.log.Info("Shutting down worker")
+func setupNATSClient(cfg *config.Config, log *zap.Logger) (messaging.NATSClient, error) {
+	return messaging.NewNATSClient(&cfg.NATS, log)
 }
@@ -0,0 +1,454 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"scoring_worker/internal/credinform"
+	"scoring_worker/internal/messaging"
+	"scoring_worker/internal/repository"
+	"scoring_worker/internal/service"
+
+	"go.uber.org/zap"
+)
+
+// releaseTimeout ограничивает время возврата незавершённой проверки в очередь
+const releaseTimeout = 10 * time.Second
+
+// WorkerOptions задаёт параметры обработки и остановки воркера
+type WorkerOptions struct {
+	Concurrency         int
+	ShutdownGracePeriod time.Duration
+	LeaseRenewInterval  time.Duration
+	RecoveryInterval    time.Duration
+	// CacheGCInterval включает периодическую очистку кэша, если больше нуля
+	CacheGCInterval  time.Duration
+	CacheGCOlderThan time.Duration
+	// Circuit сообщает состояние circuit breaker Credinform: отложенные проверки
+	// подбираются, только когда он пропускает запросы. nil — без ограничений.
+	Circuit circuitStater
+}
+
+// circuitStater сообщает состояние circuit breaker клиента Credinform
+type circuitStater interface {
+	CircuitState() credinform.CircuitState
+}
+
+type Worker struct {
+	log                 *zap.Logger
+	repo                repository.VerificationRepository
+	cacheRepo           repository.DataCacheRepository
+	verificationService service.VerificationService
+	natsClient          messaging.NATSClient
+	concurrencyCh       chan struct{}
+	gracePeriod         time.Duration
+	leaseRenewInterval  time.Duration
+	recoveryInterval    time.Duration
+	cacheGCInterval     time.Duration
+	cacheGCOlderThan    time.Duration
+	circuit             circuitStater
+
+	// active учитывает запущенные проверки, включая ожидающие свободного слота
+	active atomic.Int64
+
+	// processCtx отменяется, если обработка не уложилась в период плавной остановки
+	processCtx    context.Context
+	cancelProcess context.CancelFunc
+	inFlight      sync.WaitGroup
+	// stopping выставляется перед ожиданием inFlight; под mu, чтобы новые проверки
+	// не добавлялись в inFlight одновременно с Wait
+	mu       sync.Mutex
+	stopping bool
+}
+
+func NewWorker(log *zap.Logger, repo repository.VerificationRepository, cacheRepo repository.DataCacheRepository, verificationService service.VerificationService, natsClient messaging.NATSClient, opts WorkerOptions) *Worker {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 5
+	}
+	if opts.ShutdownGracePeriod <= 0 {
+		opts.ShutdownGracePeriod = 30 * time.Second
+	}
+	if opts.LeaseRenewInterval <= 0 {
+		opts.LeaseRenewInterval = 20 * time.Second
+	}
+	if opts.RecoveryInterval <= 0 {
+		opts.RecoveryInterval = 30 * time.Second
+	}
+	processCtx, cancelProcess := context.WithCancel(context.Background())
+	return &Worker{
+		log:                 log,
+		repo:                repo,
+		cacheRepo:           cacheRepo,
+		verificationService: verificationService,
+		natsClient:          natsClient,
+		concurrencyCh:       make(chan struct{}, opts.Concurrency),
+		gracePeriod:         opts.ShutdownGracePeriod,
+		leaseRenewInterval:  opts.LeaseRenewInterval,
+		recoveryInterval:    opts.RecoveryInterval,
+		cacheGCInterval:     opts.CacheGCInterval,
+		cacheGCOlderThan:    opts.CacheGCOlderThan,
+		circuit:             opts.Circuit,
+		processCtx:          processCtx,
+		cancelProcess:       cancelProcess,
+	}
+}
+
+func (w *Worker) Run() {
+	// ctx ограничивает приём новых задач и отменяется в начале остановки
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	defer w.cancelProcess()
+
+	w.inFlight.Add(1)
+	go func() {
+		defer w.inFlight.Done()
+		w.runRecovery(ctx)
+	}()
+
+	if w.cacheGCInterval > 0 {
+		w.inFlight.Add(1)
+		go func() {
+			defer w.inFlight.Done()
+			w.runCacheGC(ctx)
+		}()
+	}
+
+	err := w.subscribeToVerifications(ctx)
+	if err != nil {
+		w.log.Fatal("Failed to subscribe to NATS", zap.Error(err))
+	}
+
+	w.log.Info("Worker started, waiting for messages...")
+	w.waitForShutdownSignal()
+	w.shutdown(cancel)
+}
+
+// shutdown прекращает приём сообщений, ждёт завершения текущих проверок в течение
+// периода плавной остановки, после чего отменяет оставшиеся
+func (w *Worker) shutdown(stopAccepting context.CancelFunc) {
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), w.gracePeriod)
+	defer cancelDrain()
+
+	if err := w.natsClient.Drain(drainCtx); err != nil {
+		w.log.Error("Failed to drain NATS subscription", zap.Error(err))
+	}
+	stopAccepting()
+
+	w.mu.Lock()
+	w.stopping = true
+	w.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		w.inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		w.log.Info("All in-flight verifications finished")
+		return
+	case <-drainCtx.Done():
+		w.log.Warn("Shutdown grace period expired, cancelling in-flight verifications",
+			zap.Duration("grace_period", w.gracePeriod))
+	}
+
+	w.cancelProcess()
+	select {
+	case <-done:
+		w.log.Info("In-flight verifications cancelled")
+	case <-time.After(releaseTimeout):
+		w.log.Error("Timed out waiting for cancelled verifications to stop")
+	}
+}
+
+// runRecovery периодически подбирает проверки с истёкшей арендой: брошенные
+// упавшими воркерами или возвращённые в очередь при остановке, а также
+// проверки, отложенные до восстановления Credinform
+func (w *Worker) runRecovery(ctx context.Context) {
+	ticker := time.NewTicker(w.recoveryInterval)
+	defer ticker.Stop()
+
+	for {
+		w.recoverStaleVerifications(ctx)
+		w.recoverDeferredVerifications(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Worker) recoverStaleVerifications(ctx context.Context) {
+	w.log.Debug("Checking for stale verifications to resume...")
+	for ctx.Err() == nil && w.active.Load() < int64(cap(w.concurrencyCh)) {
+		verification, err := w.repo.AcquireStaleVerification(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				w.log.Error("Failed to acquire stale verification", zap.Error(err))
+			}
+			return
+		}
+
+		if verification == nil {
+			w.log.Debug("No more stale verifications to resume.")
+			return
+		}
+
+		w.log.Info("Resuming verification processing", zap.String("id", verification.ID), zap.String("inn", verification.Inn))
+		w.startProcessing(verification.ID, verification.Inn, verification.RequestedDataTypes, w.storedParams(verification))
+	}
+}
+
+// recoverDeferredVerifications возобновляет отложенные проверки. Пока circuit breaker
+// разомкнут, они не подбираются; в полуоткрытом состоянии подбирается одна проверка,
+// запросы которой станут пробными.
+func (w *Worker) recoverDeferredVerifications(ctx context.Context) {
+	limit := cap(w.concurrencyCh)
+	if w.circuit != nil {
+		switch state := w.circuit.CircuitState(); state {
+		case credinform.CircuitOpen:
+			w.log.Debug("Credinform circuit breaker is open, deferred verifications stay parked")
+			return
+		case credinform.CircuitHalfOpen:
+			limit = 1
+		}
+	}
+
+	for i := 0; i < limit && ctx.Err() == nil && w.active.Load() < int64(cap(w.concurrencyCh)); i++ {
+		verification, err := w.repo.AcquireDeferredVerification(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				w.log.Error("Failed to acquire deferred verification", zap.Error(err))
+			}
+			return
+		}
+
+		if verification == nil {
+			w.log.Debug("No more deferred verifications to resume.")
+			return
+		}
+
+		w.log.Info("Resuming deferred verification", zap.String("id", verification.ID), zap.String("inn", verification.Inn))
+		w.startProcessing(verification.ID, verification.Inn, verification.RequestedDataTypes, w.storedParams(verification))
+	}
+}
+
+// storedParams возвращает переопределения параметров, сохранённые с проверкой.
+// Они проверены при приёме сообщения, поэтому ошибка разбора лишь логируется.
+func (w *Worker) storedParams(verification *repository.Verification) service.DataParams {
+	params, err := service.ParseDataParams(verification.Parameters)
+	if err != nil {
+		w.log.Error("Failed to parse stored data parameters, using defaults", zap.Error(err), zap.String("id", verification.ID))
+	}
+	return params
+}
+
+// runCacheGC периодически удаляет записи кэша, на которые больше не ссылаются проверки
+func (w *Worker) runCacheGC(ctx context.Context) {
+	ticker := time.NewTicker(w.cacheGCInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if _, err := w.cacheRepo.GC(ctx, w.cacheGCOlderThan); err != nil && ctx.Err() == nil {
+			w.log.Error("Failed to collect cache garbage", zap.Error(err))
+		}
+	}
+}
+
+func (w *Worker) subscribeToVerifications(ctx context.Context) error {
+	return w.natsClient.SubscribeVerificationCreate(ctx, func(msg messaging.VerificationCreateMessage) error {
+		w.log.Info("Received verification.create", zap.String("id", msg.VerificationID), zap.String("inn", msg.INN))
+
+		if msg.VerificationID == "" || msg.INN == "" {
+			return messaging.Permanent(fmt.Errorf("invalid verification.create: verification_id and inn are required"))
+		}
+
+		params, err := service.ParseDataParams(msg.Parameters)
+		if err != nil {
+			return messaging.Permanent(fmt.Errorf("invalid verification.create parameters: %w", err))
+		}
+		paramsJSON, err := json.Marshal(params)
+		if err != nil {
+			return messaging.Permanent(fmt.Errorf("failed to marshal verification parameters: %w", err))
+		}
+
+		err = w.repo.Create(ctx, msg.VerificationID, msg.INN, msg.RequestedTypes, msg.AuthorEmail, paramsJSON)
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			// Повторная доставка: проверка уже сохранена и обрабатывается или будет подобрана при восстановлении
+			w.log.Info("Verification already exists, skipping", zap.String("id", msg.VerificationID))
+			return nil
+		}
+		if err != nil {
+			w.log.Error("Failed to create verification in DB", zap.Error(err), zap.String("id", msg.VerificationID))
+			return err
+		}
+
+		w.startProcessing(msg.VerificationID, msg.INN, msg.RequestedTypes, params)
+		return nil
+	})
+}
+
+// startProcessing запускает обработку проверки с учётом лимита параллельности.
+// Пока обработка идёт, аренда проверки продлевается. Проверки, прерванные
+// остановкой воркера, возвращаются в очередь восстановления; при потере аренды
+// обработка прекращается, так как проверку уже подобрал другой воркер.
+func (w *Worker) startProcessing(id string, inn string, requestedTypes []string, params service.DataParams) {
+	w.mu.Lock()
+	if w.stopping {
+		w.mu.Unlock()
+		// Проверку подберёт восстановление после перезапуска
+		w.log.Info("Worker is stopping, releasing verification", zap.String("id", id))
+		w.releaseVerification(id)
+		return
+	}
+	w.inFlight.Add(1)
+	w.mu.Unlock()
+	w.active.Add(1)
+	go func() {
+		defer w.inFlight.Done()
+		defer w.active.Add(-1)
+
+		ctx, cancel := context.WithCancel(w.processCtx)
+		defer cancel()
+
+		var leaseLost atomic.Bool
+		go w.keepLease(ctx, id, func() {
+			leaseLost.Store(true)
+			cancel()
+		})
+
+		select {
+		case w.concurrencyCh <- struct{}{}:
+		case <-ctx.Done():
+			if !leaseLost.Load() {
+				w.releaseVerification(id)
+			}
+			return
+		}
+		defer func() { <-w.concurrencyCh }()
+
+		w.log.Info("Starting verification processing", zap.String("id", id), zap.String("inn", inn))
+
+		result, err := w.verificationService.ProcessVerification(ctx, id, inn, requestedTypes, params)
+		if err != nil {
+			if leaseLost.Load() {
+				w.log.Warn("Verification lease lost, abandoning processing", zap.String("id", id))
+				return
+			}
+			if w.processCtx.Err() != nil {
+				w.log.Warn("Verification processing interrupted by shutdown", zap.String("id", id))
+				w.releaseVerification(id)
+				return
+			}
+			if service.IsDeferred(err) {
+				// Проверка будет подобрана восстановлением, когда Credinform снова станет доступен
+				w.log.Warn("Verification deferred until Credinform recovers", zap.Error(err), zap.String("id", id))
+				w.deferVerification(id)
+				return
+			}
+			if service.IsTemporary(err) {
+				// Проверка вернётся в очередь восстановления и будет повторена после задержки
+				w.log.Warn("Verification failed temporarily, scheduling retry", zap.Error(err), zap.String("id", id))
+				w.retryVerification(id, err)
+				return
+			}
+			w.log.Error("Failed to process verification", zap.Error(err), zap.String("id", id))
+			w.publishCompleted(messaging.VerificationCompletedMessage{VerificationID: id, Status: "ERROR", Error: err.Error()})
+			return
+		}
+
+		w.log.Info("Verification processing completed", zap.String("id", id))
+		w.publishCompleted(messaging.VerificationCompletedMessage{
+			VerificationID:            id,
+			Status:                    "COMPLETED",
+			ActiveBankruptcyProcedure: result.ActiveBankruptcyProcedure,
+		})
+	}()
+}
+
+// keepLease продлевает аренду проверки до отмены ctx и вызывает onLost,
+// если аренда перешла к другому воркеру
+func (w *Worker) keepLease(ctx context.Context, id string, onLost func()) {
+	ticker := time.NewTicker(w.leaseRenewInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := w.repo.RenewLease(ctx, id)
+		if errors.Is(err, repository.ErrLeaseLost) {
+			w.log.Warn("Verification lease lost", zap.String("id", id))
+			onLost()
+			return
+		}
+		if err != nil && ctx.Err() == nil {
+			w.log.Error("Failed to renew verification lease", zap.Error(err), zap.String("id", id))
+		}
+	}
+}
+
+func (w *Worker) releaseVerification(id string) {
+	ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancel()
+	if err := w.repo.ReleaseVerification(ctx, id); err != nil {
+		w.log.Error("Failed to release verification", zap.Error(err), zap.String("id", id))
+	}
+}
+
+// retryVerification откладывает повтор проверки; если попытки исчерпаны,
+// проверка уже переведена в ERROR и публикуется её завершение
+func (w *Worker) retryVerification(id string, cause error) {
+	ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancel()
+	exhausted, err := w.repo.RetryVerification(ctx, id)
+	if err != nil {
+		w.log.Error("Failed to schedule verification retry", zap.Error(err), zap.String("id", id))
+		return
+	}
+	if exhausted {
+		w.log.Error("Verification retry attempts exhausted", zap.Error(cause), zap.String("id", id))
+		w.publishCompleted(messaging.VerificationCompletedMessage{VerificationID: id, Status: "ERROR", Error: cause.Error()})
+	}
+}
+
+func (w *Worker) deferVerification(id string) {
+	ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancel()
+	if err := w.repo.DeferVerification(ctx, id); err != nil {
+		w.log.Error("Failed to defer verification", zap.Error(err), zap.String("id", id))
+	}
+}
+
+func (w *Worker) publishCompleted(msg messaging.VerificationCompletedMessage) {
+	if err := w.natsClient.PublishVerificationCompleted(context.Background(), msg); err != nil {
+		w.log.Error("Failed to publish completion notification", zap.Error(err), zap.String("id", msg.VerificationID))
+	}
+}
+
+func (w *Worker) waitForShutdownSignal() {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	<-sig
+	w.log.Info("Shutting down worker")
+}
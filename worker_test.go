@@ -0,0 +1,435 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"scoring_worker/internal/credinform"
+	"scoring_worker/internal/messaging"
+	"scoring_worker/internal/repository"
+	"scoring_worker/internal/service"
+
+	"go.uber.org/zap/zaptest"
+)
+
+// Mock для NATSClient
+type mockNATSClient struct {
+	drainFunc func(ctx context.Context) error
+
+	mu        sync.Mutex
+	published []messaging.VerificationCompletedMessage
+}
+
+func (m *mockNATSClient) SubscribeVerificationCreate(ctx context.Context, handler messaging.VerificationCreateHandler) error {
+	return nil
+}
+
+func (m *mockNATSClient) PublishVerificationCompleted(ctx context.Context, msg messaging.VerificationCompletedMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.published = append(m.published, msg)
+	return nil
+}
+
+func (m *mockNATSClient) Drain(ctx context.Context) error {
+	if m.drainFunc != nil {
+		return m.drainFunc(ctx)
+	}
+	return nil
+}
+
+func (m *mockNATSClient) Close() {}
+
+func (m *mockNATSClient) completed() map[string]string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	statuses := make(map[string]string, len(m.published))
+	for _, msg := range m.published {
+		statuses[msg.VerificationID] = msg.Status
+	}
+	return statuses
+}
+
+// Mock для VerificationRepository
+type mockVerificationRepository struct {
+	acquireStaleFunc    func(ctx context.Context) (*repository.Verification, error)
+	acquireDeferredFunc func(ctx context.Context) (*repository.Verification, error)
+	renewLeaseFunc      func(ctx context.Context, id string) error
+
+	mu       sync.Mutex
+	released []string
+}
+
+func (m *mockVerificationRepository) Create(ctx context.Context, id string, inn string, requestedTypes []string, authorEmail string, parameters json.RawMessage) error {
+	return nil
+}
+
+func (m *mockVerificationRepository) UpdateStatus(ctx context.Context, id string, status string) error {
+	return nil
+}
+
+func (m *mockVerificationRepository) UpdateCompanyID(ctx context.Context, id string, companyID string) error {
+	return nil
+}
+
+func (m *mockVerificationRepository) AddData(ctx context.Context, verificationID string, dataType string, data string, metadata repository.DataMetadata) error {
+	return nil
+}
+
+func (m *mockVerificationRepository) GetByID(ctx context.Context, id string) (*repository.Verification, error) {
+	return nil, nil
+}
+
+func (m *mockVerificationRepository) AcquireStaleVerification(ctx context.Context) (*repository.Verification, error) {
+	if m.acquireStaleFunc != nil {
+		return m.acquireStaleFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (m *mockVerificationRepository) AcquireDeferredVerification(ctx context.Context) (*repository.Verification, error) {
+	if m.acquireDeferredFunc != nil {
+		return m.acquireDeferredFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (m *mockVerificationRepository) ReleaseVerification(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.released = append(m.released, id)
+	return nil
+}
+
+func (m *mockVerificationRepository) RetryVerification(ctx context.Context, id string) (bool, error) {
+	return false, nil
+}
+
+func (m *mockVerificationRepository) DeferVerification(ctx context.Context, id string) error {
+	return nil
+}
+
+func (m *mockVerificationRepository) RenewLease(ctx context.Context, id string) error {
+	if m.renewLeaseFunc != nil {
+		return m.renewLeaseFunc(ctx, id)
+	}
+	return nil
+}
+
+func (m *mockVerificationRepository) releasedIDs() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.released...)
+}
+
+// Mock для VerificationService
+type mockVerificationService struct {
+	processFunc func(ctx context.Context, verificationID string) (*service.Result, error)
+
+	mu    sync.Mutex
+	calls int
+}
+
+func (m *mockVerificationService) ProcessVerification(ctx context.Context, verificationID, inn string, requestedTypes []string, overrides service.DataParams) (*service.Result, error) {
+	m.mu.Lock()
+	m.calls++
+	m.mu.Unlock()
+	if m.processFunc != nil {
+		return m.processFunc(ctx, verificationID)
+	}
+	return &service.Result{}, nil
+}
+
+func (m *mockVerificationService) processCalls() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls
+}
+
+var (
+	_ messaging.NATSClient              = (*mockNATSClient)(nil)
+	_ repository.VerificationRepository = (*mockVerificationRepository)(nil)
+	_ service.VerificationService       = (*mockVerificationService)(nil)
+)
+
+// sleepProcessing имитирует обработку заданной длительности, прерываемую отменой ctx.
+// started закрывается, когда обработка началась.
+func sleepProcessing(duration time.Duration, started chan struct{}) func(ctx context.Context, verificationID string) (*service.Result, error) {
+	var once sync.Once
+	return func(ctx context.Context, verificationID string) (*service.Result, error) {
+		once.Do(func() { close(started) })
+		select {
+		case <-time.After(duration):
+			return &service.Result{}, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func newTestWorker(t *testing.T, repo *mockVerificationRepository, svc *mockVerificationService, natsClient *mockNATSClient, opts WorkerOptions) *Worker {
+	t.Helper()
+	if opts.LeaseRenewInterval == 0 {
+		opts.LeaseRenewInterval = time.Hour
+	}
+	w := NewWorker(zaptest.NewLogger(t), repo, nil, svc, natsClient, opts)
+	t.Cleanup(func() {
+		w.cancelProcess()
+		w.inFlight.Wait()
+	})
+	return w
+}
+
+// waitInFlight дожидается завершения всех запущенных проверок
+func waitInFlight(t *testing.T, w *Worker) {
+	t.Helper()
+	done := make(chan struct{})
+	go func() {
+		w.inFlight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("in-flight verifications did not finish")
+	}
+}
+
+func TestWorkerShutdown(t *testing.T) {
+	tests := []struct {
+		name              string
+		processing        time.Duration
+		gracePeriod       time.Duration
+		expectedCompleted map[string]string
+		expectedReleased  []string
+	}{
+		{
+			// Проверка, уложившаяся в период плавной остановки, завершается как обычно
+			name:              "finishes_within_grace_period",
+			processing:        50 * time.Millisecond,
+			gracePeriod:       5 * time.Second,
+			expectedCompleted: map[string]string{"v-1": "COMPLETED"},
+		},
+		{
+			// Не уложившаяся проверка отменяется и возвращается в очередь восстановления
+			name:             "runs_past_grace_period",
+			processing:       time.Hour,
+			gracePeriod:      50 * time.Millisecond,
+			expectedReleased: []string{"v-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			started := make(chan struct{})
+			repo := &mockVerificationRepository{}
+			svc := &mockVerificationService{processFunc: sleepProcessing(tt.processing, started)}
+			natsClient := &mockNATSClient{}
+			w := newTestWorker(t, repo, svc, natsClient, WorkerOptions{ShutdownGracePeriod: tt.gracePeriod})
+
+			w.startProcessing("v-1", "1234567890", nil, service.DataParams{})
+			<-started
+			w.shutdown(func() {})
+
+			if w.active.Load() != 0 {
+				t.Errorf("expected no active verifications after shutdown, got %d", w.active.Load())
+			}
+			completed := natsClient.completed()
+			if len(completed) != len(tt.expectedCompleted) {
+				t.Errorf("expected completions %v, got %v", tt.expectedCompleted, completed)
+			}
+			for id, status := range tt.expectedCompleted {
+				if completed[id] != status {
+					t.Errorf("expected %s to complete with %s, got %q", id, status, completed[id])
+				}
+			}
+			released := repo.releasedIDs()
+			if len(released) != len(tt.expectedReleased) {
+				t.Fatalf("expected released %v, got %v", tt.expectedReleased, released)
+			}
+			for i, id := range tt.expectedReleased {
+				if released[i] != id {
+					t.Errorf("expected released %v, got %v", tt.expectedReleased, released)
+				}
+			}
+		})
+	}
+}
+
+func TestStartProcessingAfterStopping(t *testing.T) {
+	repo := &mockVerificationRepository{}
+	svc := &mockVerificationService{}
+	natsClient := &mockNATSClient{}
+	w := newTestWorker(t, repo, svc, natsClient, WorkerOptions{ShutdownGracePeriod: time.Second})
+
+	w.shutdown(func() {})
+	w.startProcessing("v-1", "1234567890", nil, service.DataParams{})
+	waitInFlight(t, w)
+
+	if calls := svc.processCalls(); calls != 0 {
+		t.Errorf("expected no processing after stopping, got %d calls", calls)
+	}
+	if w.active.Load() != 0 {
+		t.Errorf("expected no active verifications, got %d", w.active.Load())
+	}
+	if released := repo.releasedIDs(); len(released) != 1 || released[0] != "v-1" {
+		t.Errorf("expected v-1 to be released, got %v", released)
+	}
+}
+
+func TestShutdownDrainsBeforeWaiting(t *testing.T) {
+	drained := make(chan struct{})
+	repo := &mockVerificationRepository{}
+	svc := &mockVerificationService{
+		processFunc: func(ctx context.Context, verificationID string) (*service.Result, error) {
+			// Проверка завершается только после Drain: если ожидание начнётся раньше,
+			// период плавной остановки истечёт и проверка будет отменена
+			select {
+			case <-drained:
+				return &service.Result{}, nil
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		},
+	}
+	natsClient := &mockNATSClient{}
+	w := newTestWorker(t, repo, svc, natsClient, WorkerOptions{ShutdownGracePeriod: time.Second})
+	natsClient.drainFunc = func(ctx context.Context) error {
+		// Сообщение, обработанное во время Drain, должно быть принято в работу
+		w.startProcessing("v-2", "1234567890", nil, service.DataParams{})
+		close(drained)
+		return nil
+	}
+
+	w.startProcessing("v-1", "1234567890", nil, service.DataParams{})
+	w.shutdown(func() {})
+
+	completed := natsClient.completed()
+	for _, id := range []string{"v-1", "v-2"} {
+		if completed[id] != "COMPLETED" {
+			t.Errorf("expected %s to complete, got %q", id, completed[id])
+		}
+	}
+	if released := repo.releasedIDs(); len(released) != 0 {
+		t.Errorf("expected no released verifications, got %v", released)
+	}
+}
+
+func TestLeaseLostCancelsProcessing(t *testing.T) {
+	started := make(chan struct{})
+	repo := &mockVerificationRepository{
+		renewLeaseFunc: func(ctx context.Context, id string) error {
+			return fmt.Errorf("%w: %s", repository.ErrLeaseLost, id)
+		},
+	}
+	svc := &mockVerificationService{processFunc: sleepProcessing(time.Hour, started)}
+	natsClient := &mockNATSClient{}
+	w := newTestWorker(t, repo, svc, natsClient, WorkerOptions{LeaseRenewInterval: 10 * time.Millisecond})
+
+	w.startProcessing("v-1", "1234567890", nil, service.DataParams{})
+	<-started
+	waitInFlight(t, w)
+
+	// Проверку уже подобрал другой воркер: её нельзя ни завершать, ни возвращать в очередь
+	if completed := natsClient.completed(); len(completed) != 0 {
+		t.Errorf("expected no completions, got %v", completed)
+	}
+	if released := repo.releasedIDs(); len(released) != 0 {
+		t.Errorf("expected no released verifications, got %v", released)
+	}
+	if w.processCtx.Err() != nil {
+		t.Error("expected lost lease to cancel only its own verification")
+	}
+}
+
+// Mock для circuitStater
+type mockCircuit struct {
+	state credinform.CircuitState
+}
+
+func (m mockCircuit) CircuitState() credinform.CircuitState {
+	return m.state
+}
+
+func TestRecoverDeferredVerifications(t *testing.T) {
+	tests := []struct {
+		name             string
+		circuit          circuitStater
+		deferred         int
+		expectedAcquired int
+	}{
+		{
+			name:             "no_circuit_breaker",
+			deferred:         10,
+			expectedAcquired: 3,
+		},
+		{
+			// Закрытый breaker: подбирается обычная партия до лимита параллельности
+			name:             "closed",
+			circuit:          mockCircuit{state: credinform.CircuitClosed},
+			deferred:         10,
+			expectedAcquired: 3,
+		},
+		{
+			name:             "closed_fewer_than_concurrency",
+			circuit:          mockCircuit{state: credinform.CircuitClosed},
+			deferred:         2,
+			expectedAcquired: 2,
+		},
+		{
+			// Полуоткрытый breaker: одна проверка, запросы которой станут пробными
+			name:             "half_open",
+			circuit:          mockCircuit{state: credinform.CircuitHalfOpen},
+			deferred:         10,
+			expectedAcquired: 1,
+		},
+		{
+			// Разомкнутый breaker: проверки остаются отложенными
+			name:             "open",
+			circuit:          mockCircuit{state: credinform.CircuitOpen},
+			deferred:         10,
+			expectedAcquired: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mu       sync.Mutex
+				acquired int
+			)
+			repo := &mockVerificationRepository{
+				acquireDeferredFunc: func(ctx context.Context) (*repository.Verification, error) {
+					mu.Lock()
+					defer mu.Unlock()
+					if acquired == tt.deferred {
+						return nil, nil
+					}
+					acquired++
+					return &repository.Verification{
+						ID:         fmt.Sprintf("v-%d", acquired),
+						Inn:        "1234567890",
+						Parameters: json.RawMessage("{}"),
+					}, nil
+				},
+			}
+			svc := &mockVerificationService{processFunc: sleepProcessing(time.Hour, make(chan struct{}))}
+			w := newTestWorker(t, repo, svc, &mockNATSClient{}, WorkerOptions{Concurrency: 3, Circuit: tt.circuit})
+
+			w.recoverDeferredVerifications(context.Background())
+
+			mu.Lock()
+			defer mu.Unlock()
+			if acquired != tt.expectedAcquired {
+				t.Errorf("expected %d acquired verifications, got %d", tt.expectedAcquired, acquired)
+			}
+			if active := w.active.Load(); active != int64(tt.expectedAcquired) {
+				t.Errorf("expected %d active verifications, got %d", tt.expectedAcquired, active)
+			}
+		})
+	}
+}